@@ -1,53 +1,276 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
+	_ "time/tzdata" // Validasi timezone tidak bergantung pada tzdata di image
 
 	"github.com/Hilmarch27/gin-api/internal/delivery/http/handler"
 	"github.com/Hilmarch27/gin-api/internal/delivery/http/router"
+	"github.com/Hilmarch27/gin-api/internal/delivery/http/validation"
 	"github.com/Hilmarch27/gin-api/internal/domain"
 	"github.com/Hilmarch27/gin-api/internal/repository"
 	"github.com/Hilmarch27/gin-api/internal/usecase"
 	"github.com/Hilmarch27/gin-api/pkg/config"
+	"github.com/Hilmarch27/gin-api/pkg/logger"
+	"github.com/Hilmarch27/gin-api/pkg/mailer"
+	"github.com/Hilmarch27/gin-api/pkg/metrics"
+	"github.com/Hilmarch27/gin-api/pkg/oidc"
+	"github.com/Hilmarch27/gin-api/pkg/storage"
+	"github.com/Hilmarch27/gin-api/pkg/tracing"
 	"github.com/gin-gonic/gin"
+	"github.com/go-webauthn/webauthn/webauthn"
+	gormtracing "gorm.io/plugin/opentelemetry/tracing"
 )
 
 func main() {
 	// Load config
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		log.Fatal(err)
+		slog.Error("failed to load config", "error", err)
+		os.Exit(1)
+	}
+
+	// Initialize logger (JSON, attribute sensitif disensor)
+	appLogger := logger.New(os.Stdout, logger.ParseLevel(cfg.LogLevel))
+	slog.SetDefault(appLogger)
+
+	// Initialize tracing (OTLP, stdout/file, atau none)
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		appLogger.Error("failed to setup tracing", "error", err)
+		os.Exit(1)
+	}
+
+	// Span untuk setiap query GORM; nilai parameter query tidak dicatat
+	if err := cfg.DB.Use(gormtracing.NewPlugin(gormtracing.WithoutMetrics(), gormtracing.WithoutQueryVariables())); err != nil {
+		appLogger.Error("failed to register gorm tracing", "error", err)
+		os.Exit(1)
 	}
 
 	// Auto migrate database
-	err = cfg.DB.AutoMigrate(&domain.User{})
+	err = cfg.DB.AutoMigrate(&domain.User{}, &domain.AuditLog{}, &domain.AuditCheckpoint{}, &domain.IdempotencyRecord{}, &domain.Organization{}, &domain.Membership{}, &domain.Invitation{}, &domain.PersonalAccessToken{}, &domain.ServiceAccount{}, &domain.APIKey{}, &domain.OAuthClient{}, &domain.OAuthAuthorizationCode{}, &domain.OAuthRefreshToken{}, &domain.OAuthConsent{}, &domain.FederatedIdentity{}, &domain.Passkey{}, &domain.PasskeyChallenge{}, &domain.MagicLink{})
 	if err != nil {
-		log.Fatal(err)
+		appLogger.Error("failed to migrate database", "error", err)
+		os.Exit(1)
+	}
+	if err := repository.InstallAuditTriggers(cfg.DB); err != nil {
+		appLogger.Error("failed to install audit triggers", "error", err)
+		os.Exit(1)
+	}
+
+	// Export statistik connection pool database ke Prometheus
+	sqlDB, err := cfg.DB.DB()
+	if err != nil {
+		appLogger.Error("failed to get database handle", "error", err)
+		os.Exit(1)
+	}
+	if err := metrics.RegisterDB("primary", sqlDB); err != nil {
+		appLogger.Error("failed to register database metrics", "error", err)
+		os.Exit(1)
+	}
+	for i, replica := range cfg.Replicas {
+		if err := metrics.RegisterDB(fmt.Sprintf("replica-%d", i+1), replica); err != nil {
+			appLogger.Error("failed to register database metrics", "error", err)
+			os.Exit(1)
+		}
 	}
 
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(cfg.DB)
+	orgRepo := repository.NewOrganizationRepository(cfg.DB)
+	invitationRepo := repository.NewInvitationRepository(cfg.DB)
+	accessTokenRepo := repository.NewAccessTokenRepository(cfg.DB)
+	serviceAccountRepo := repository.NewServiceAccountRepository(cfg.DB)
+	apiKeyRepo := repository.NewAPIKeyRepository(cfg.DB)
+	oauthClientRepo := repository.NewOAuthClientRepository(cfg.DB)
+	oauthGrantRepo := repository.NewOAuthGrantRepository(cfg.DB)
+	identityRepo := repository.NewIdentityRepository(cfg.DB)
+	passkeyRepo := repository.NewPasskeyRepository(cfg.DB)
+	magicLinkRepo := repository.NewMagicLinkRepository(cfg.DB)
+	auditRepo := repository.NewAuditRepository(cfg.DB)
+	idempotencyRepo := repository.NewIdempotencyRepository(cfg.DB)
+	uow := repository.NewUnitOfWork(cfg.DB)
+
+	// Initialize mailer, tanpa SMTP_HOST email hanya dicetak ke log
+	var mail mailer.Mailer = mailer.NewLogMailer(appLogger)
+	if cfg.SMTP.Host != "" {
+		mail = mailer.NewSMTPMailer(cfg.SMTP)
+	}
+
+	// Initialize storage untuk file upload (local atau S3-compatible)
+	fileStorage, err := storage.New(context.Background(), cfg.Storage)
+	if err != nil {
+		appLogger.Error("failed to initialize storage", "error", err)
+		os.Exit(1)
+	}
+
+	// Kunci penandatangan token OIDC; tanpa file dibuat acak (development)
+	var signingKey *oidc.SigningKey
+	if cfg.OIDC.SigningKeyFile != "" {
+		signingKey, err = oidc.LoadSigningKey(cfg.OIDC.SigningKeyFile)
+	} else {
+		appLogger.Warn("OIDC_SIGNING_KEY_FILE is not set, using an ephemeral signing key")
+		signingKey, err = oidc.GenerateSigningKey()
+	}
+	if err != nil {
+		appLogger.Error("failed to load OIDC signing key", "error", err)
+		os.Exit(1)
+	}
+
+	// Client identity provider SSO; discovery diambil saat login pertama
+	ssoProviders := make([]*oidc.Provider, 0, len(cfg.SSO.Providers))
+	for _, p := range cfg.SSO.Providers {
+		ssoProviders = append(ssoProviders, oidc.NewProvider(p, nil))
+	}
+
+	// Relying party WebAuthn untuk passkey
+	webAuthn, err := webauthn.New(&cfg.WebAuthn)
+	if err != nil {
+		appLogger.Error("failed to initialize WebAuthn", "error", err)
+		os.Exit(1)
+	}
 
 	// Initialize usecases
-	authUsecase := usecase.NewAuthUsecase(userRepo, cfg.JWTSecret, time.Hour*1)
+	authUsecase := usecase.NewAuthUsecase(userRepo, orgRepo, uow, mail, cfg.JWTSecret, time.Hour*1, cfg.LoginMaxFailures, cfg.LoginLockout)
+	avatarUsecase := usecase.NewAvatarUsecase(uow, fileStorage)
+	auditUsecase := usecase.NewAuditUsecase(auditRepo)
+	orgUsecase := usecase.NewOrganizationUsecase(orgRepo, uow)
+	invitationUsecase := usecase.NewInvitationUsecase(invitationRepo, userRepo, uow, mail, cfg.JWTSecret, cfg.InvitationTTL, cfg.InvitationAcceptURL)
+	accessTokenUsecase := usecase.NewAccessTokenUsecase(accessTokenRepo, userRepo, uow)
+	serviceAccountUsecase := usecase.NewServiceAccountUsecase(serviceAccountRepo, apiKeyRepo, uow, cfg.APIKeyRotationOverlap)
+	oauthUsecase := usecase.NewOAuthUsecase(oauthClientRepo, oauthGrantRepo, userRepo, uow, signingKey, cfg.OIDC)
+	ssoUsecase := usecase.NewSSOUsecase(identityRepo, userRepo, uow, ssoProviders, cfg.SSO, cfg.JWTSecret)
+	passkeyUsecase := usecase.NewPasskeyUsecase(passkeyRepo, userRepo, uow, webAuthn)
+	magicLinkUsecase := usecase.NewMagicLinkUsecase(magicLinkRepo, userRepo, uow, mail, cfg.JWTSecret, cfg.MagicLinkTTL, cfg.MagicLinkURL, cfg.MagicLinkMaxSends, cfg.MagicLinkWindow)
+	idempotencyUsecase := usecase.NewIdempotencyUsecase(idempotencyRepo, cfg.IdempotencyTTL)
 
 	// Initialize handlers
 	authHandler := handler.NewAuthHandler(authUsecase)
+	avatarHandler := handler.NewAvatarHandler(avatarUsecase, cfg.AvatarMaxBytes)
+	auditHandler := handler.NewAuditHandler(auditUsecase)
+	orgHandler := handler.NewOrganizationHandler(orgUsecase, authUsecase)
+	invitationHandler := handler.NewInvitationHandler(invitationUsecase, authUsecase)
+	accessTokenHandler := handler.NewAccessTokenHandler(accessTokenUsecase)
+	serviceAccountHandler := handler.NewServiceAccountHandler(serviceAccountUsecase)
+	oauthHandler := handler.NewOAuthHandler(oauthUsecase)
+	ssoHandler := handler.NewSSOHandler(ssoUsecase, authUsecase)
+	passkeyHandler := handler.NewPasskeyHandler(passkeyUsecase, authUsecase)
+	magicLinkHandler := handler.NewMagicLinkHandler(magicLinkUsecase, authUsecase)
 
-	// Initialize Gin engine
-	engine := gin.Default()
+	// Validator melaporkan nama field JSON pada error validasi
+	validation.Setup()
+
+	// Initialize Gin engine, logging request ditangani RequestLogger
+	engine, err := router.NewEngine(cfg.TrustedProxies)
+	if err != nil {
+		appLogger.Error("invalid trusted proxies", "error", err)
+		os.Exit(1)
+	}
 
 	// Initialize routers
-	publicRouter  := router.NewPublicRouter(authHandler, cfg.JWTSecret)
-	apiRouter := router.NewApiRouter(authHandler, cfg.JWTSecret)
+	publicRouter := router.NewPublicRouter(authHandler, invitationHandler, ssoHandler, passkeyHandler, magicLinkHandler, cfg.JWTSecret)
+	apiRouter := router.NewApiRouter(authHandler, avatarHandler, auditHandler, orgHandler, invitationHandler, accessTokenHandler, serviceAccountHandler, oauthHandler, ssoHandler, passkeyHandler, cfg.JWTSecret)
+	oauthRouter := router.NewOAuthRouter(oauthHandler)
+
+	// Versi API; handler v2 bisa ditambahkan sebagai Version baru
+	v1 := []router.RouteGroup{publicRouter, apiRouter, oauthRouter}
+	versions := []router.Version{{Name: "v1", Prefix: "/v1", Routes: v1}}
+	if cfg.LegacyRoutes {
+		versions = append(versions, router.Version{
+			Name:       "legacy",
+			Deprecated: cfg.LegacyDeprecated,
+			Sunset:     cfg.LegacySunset,
+			Successor:  "/v1",
+			Routes:     v1,
+		})
+	}
 
 	// Setup main router
-	mainRouter := router.NewRouter(engine, publicRouter, apiRouter, []byte(cfg.JWTSecret))
-	mainRouter.SetupRoutes()
+	mainRouter := router.NewRouter(engine, versions, idempotencyUsecase, orgUsecase, accessTokenUsecase, serviceAccountUsecase, cfg.TenantBaseDomain, []byte(cfg.JWTSecret), cfg.DBTimeout, appLogger, cfg.Tracing.ServiceName)
+	if err := mainRouter.SetupRoutes(); err != nil {
+		appLogger.Error("failed to setup routes", "error", err)
+		os.Exit(1)
+	}
+
+	// Discovery OpenID Connect harus ada di bawah issuer, di luar versi API
+	engine.GET("/.well-known/openid-configuration", oauthHandler.Discovery)
+	engine.GET("/.well-known/jwks.json", oauthHandler.JWKS)
+
+	// File storage local dilayani langsung oleh server
+	if local, ok := fileStorage.(*storage.LocalStorage); ok {
+		engine.Static(cfg.Storage.LocalURL, local.Dir())
+	}
+
+	// Endpoint metrics: di port admin terpisah jika METRICS_ADDR diisi
+	if cfg.MetricsAddr != "" {
+		go serveMetrics(cfg.MetricsAddr, appLogger)
+	} else {
+		engine.GET("/metrics", gin.WrapH(metrics.Handler()))
+	}
 
 	// Start server
-	if err := engine.Run(":3027"); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	server := &http.Server{
+		Addr:              ":3027",
+		Handler:           engine,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Hapus response Idempotency-Key yang sudah expired secara berkala
+	go idempotencyUsecase.RunCleanup(ctx, cfg.IdempotencyCleanupInterval, appLogger)
+
+	// Hapus magic link lama yang sudah lewat masa berlaku dan window throttle
+	go magicLinkUsecase.RunCleanup(ctx, cfg.MagicLinkCleanupInterval, appLogger)
+
+	// Hapus challenge passkey yang tidak pernah diselesaikan browser
+	go passkeyUsecase.RunCleanup(ctx, cfg.PasskeyCleanupInterval, appLogger)
+
+	go func() {
+		appLogger.Info("starting server", "addr", server.Addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			appLogger.Error("failed to start server", "error", err)
+			os.Exit(1)
+		}
+	}()
+
+	// Tunggu sinyal berhenti, lalu selesaikan request yang berjalan dan
+	// kirim span yang tersisa
+	<-ctx.Done()
+	appLogger.Info("shutting down server")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		appLogger.Error("failed to shutdown server", "error", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		appLogger.Error("failed to shutdown tracing", "error", err)
+	}
+}
+
+func serveMetrics(addr string, log *slog.Logger) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	log.Info("starting metrics server", "addr", addr)
+	if err := server.ListenAndServe(); err != nil {
+		log.Error("metrics server stopped", "error", err)
 	}
-}
\ No newline at end of file
+}
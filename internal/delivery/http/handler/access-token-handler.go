@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AccessTokenHandler struct {
+	tokenUsecase usecase.AccessTokenUsecase
+}
+
+func NewAccessTokenHandler(tu usecase.AccessTokenUsecase) *AccessTokenHandler {
+	return &AccessTokenHandler{
+		tokenUsecase: tu,
+	}
+}
+
+// List mengembalikan personal access token milik user yang sedang login
+func (h *AccessTokenHandler) List(c *gin.Context) {
+	me, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	tokens, err := h.tokenUsecase.List(c.Request.Context(), me.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   tokens,
+	})
+}
+
+// Create membuat personal access token; token utuh hanya ada di response ini
+func (h *AccessTokenHandler) Create(c *gin.Context) {
+	me, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req domain.CreateAccessTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.ErrInvalidInput.Wrap(err))
+		return
+	}
+
+	token, err := h.tokenUsecase.Create(c.Request.Context(), me, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Token rahasia tidak boleh disimpan cache
+	c.Header("Cache-Control", "no-store")
+	c.Header("Location", c.Request.URL.Path+"/"+token.ID.String())
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": message(c, "access_token_created"),
+		"data":    token,
+	})
+}
+
+func (h *AccessTokenHandler) Revoke(c *gin.Context) {
+	me, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	id, err := uuid.Parse(c.Param("token_id"))
+	if err != nil {
+		c.Error(domain.ErrInvalidAccessTokenID.Wrap(err))
+		return
+	}
+
+	if err := h.tokenUsecase.Revoke(c.Request.Context(), me.ID, id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": message(c, "access_token_revoked"),
+	})
+}
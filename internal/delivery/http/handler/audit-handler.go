@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AuditHandler struct {
+	auditUsecase usecase.AuditUsecase
+}
+
+func NewAuditHandler(au usecase.AuditUsecase) *AuditHandler {
+	return &AuditHandler{
+		auditUsecase: au,
+	}
+}
+
+// AuditQuery adalah filter query string untuk GET /api/admin/audit
+type AuditQuery struct {
+	ActorID   string `form:"actor_id" binding:"omitempty,uuid"`
+	TargetID  string `form:"target_id" binding:"omitempty,uuid"`
+	Action    string `form:"action"`
+	From      string `form:"from" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To        string `form:"to" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	BeforeSeq int64  `form:"before_seq" binding:"omitempty,min=1"`
+	Limit     int    `form:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+func (h *AuditHandler) List(c *gin.Context) {
+	var query AuditQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(domain.ErrInvalidInput.Wrap(err))
+		return
+	}
+
+	// Format sudah divalidasi binding, error parse bisa diabaikan
+	filter := domain.AuditFilter{
+		Action:    query.Action,
+		BeforeSeq: query.BeforeSeq,
+		Limit:     query.Limit,
+	}
+	if query.ActorID != "" {
+		id := uuid.MustParse(query.ActorID)
+		filter.ActorID = &id
+	}
+	if query.TargetID != "" {
+		id := uuid.MustParse(query.TargetID)
+		filter.TargetID = &id
+	}
+	if query.From != "" {
+		from, _ := time.Parse(time.RFC3339, query.From)
+		filter.From = &from
+	}
+	if query.To != "" {
+		to, _ := time.Parse(time.RFC3339, query.To)
+		filter.To = &to
+	}
+
+	entries, err := h.auditUsecase.List(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Cursor halaman berikutnya adalah seq entri terakhir
+	response := gin.H{
+		"status": "success",
+		"data":   entries,
+	}
+	if len(entries) > 0 {
+		response["next_before_seq"] = entries[len(entries)-1].Seq
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuditHandler) Verify(c *gin.Context) {
+	result, err := h.auditUsecase.Verify(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   result,
+	})
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// AvatarFormField adalah nama field multipart untuk file avatar
+const AvatarFormField = "avatar"
+
+// AvatarMediaTypes adalah tipe gambar yang diterima, dicek dari isi file
+// (bukan header Content-Type dari client)
+var AvatarMediaTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+
+// multipartOverhead adalah ruang untuk boundary dan header part multipart
+const multipartOverhead = 64 << 10
+
+type AvatarHandler struct {
+	avatarUsecase usecase.AvatarUsecase
+	maxBytes      int64
+}
+
+func NewAvatarHandler(au usecase.AvatarUsecase, maxBytes int64) *AvatarHandler {
+	return &AvatarHandler{
+		avatarUsecase: au,
+		maxBytes:      maxBytes,
+	}
+}
+
+func (h *AvatarHandler) Upload(c *gin.Context) {
+	me, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Batasi body sebelum multipart di-parse agar file besar tidak ditulis ke disk
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxBytes+multipartOverhead)
+
+	data, err := h.readFile(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if !isAvatarMediaType(http.DetectContentType(data)) {
+		c.Error(domain.ErrUnsupportedImage)
+		return
+	}
+
+	userResponse, err := h.avatarUsecase.Upload(c.Request.Context(), me.ID, data)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   userResponse,
+	})
+}
+
+func (h *AvatarHandler) Delete(c *gin.Context) {
+	me, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.avatarUsecase.Remove(c.Request.Context(), me.ID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": message(c, "avatar_removed"),
+	})
+}
+
+// readFile membaca file avatar dari form multipart dengan batas maxBytes
+func (h *AvatarHandler) readFile(c *gin.Context) ([]byte, error) {
+	header, err := c.FormFile(AvatarFormField)
+	var maxErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxErr):
+		return nil, domain.ErrFileTooLarge.Wrap(err)
+	case errors.Is(err, http.ErrMissingFile), errors.Is(err, http.ErrNotMultipart):
+		return nil, domain.ErrFileRequired.Wrap(err)
+	case err != nil:
+		return nil, domain.ErrInvalidInput.Wrap(err)
+	}
+	if header.Size > h.maxBytes {
+		return nil, domain.ErrFileTooLarge
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, h.maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > h.maxBytes {
+		return nil, domain.ErrFileTooLarge
+	}
+	if len(data) == 0 {
+		return nil, domain.ErrFileRequired
+	}
+	return data, nil
+}
+
+func isAvatarMediaType(mediaType string) bool {
+	for _, t := range AvatarMediaTypes {
+		if mediaType == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// ETag membentuk strong ETag dari versi resource, misalnya "3"
+func ETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ifMatch mem-parse header If-Match menjadi syarat versi. Tanpa header
+// hasilnya nil (tanpa syarat). Weak ETag (W/"...") tidak pernah cocok
+// karena If-Match memakai strong comparison (RFC 9110).
+func ifMatch(c *gin.Context) *domain.VersionMatch {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		return nil
+	}
+
+	match := &domain.VersionMatch{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			match.Any = true
+			continue
+		}
+		if version, ok := parseETag(tag); ok {
+			match.Versions = append(match.Versions, version)
+		}
+	}
+	return match
+}
+
+// requireIfMatch seperti ifMatch, tetapi request tanpa If-Match ditolak
+// dengan ErrPreconditionRequired (428, RFC 6585). Dipakai untuk perubahan
+// oleh admin, yang bisa menimpa perubahan user atau admin lain tanpa
+// disadari. Pemilik akun mengubah datanya sendiri sehingga If-Match di
+// /users/me tetap opsional.
+func requireIfMatch(c *gin.Context) (*domain.VersionMatch, bool) {
+	match := ifMatch(c)
+	if match == nil {
+		c.Error(domain.ErrPreconditionRequired)
+		return nil, false
+	}
+	return match, true
+}
+
+// notModified mengecek If-None-Match terhadap versi resource (weak
+// comparison, RFC 9110)
+func notModified(c *gin.Context, version int64) bool {
+	for _, tag := range strings.Split(c.GetHeader("If-None-Match"), ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" {
+			return true
+		}
+		if v, ok := parseETag(tag); ok && v == version {
+			return true
+		}
+	}
+	return false
+}
+
+func parseETag(tag string) (int64, bool) {
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+	version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+	return version, err == nil
+}
+
+// respondUser mengirim user beserta header ETag. Untuk GET, request dengan
+// If-None-Match yang cocok dijawab 304 tanpa body.
+func respondUser(c *gin.Context, user *domain.UserResponse, body gin.H) {
+	c.Header("ETag", ETag(user.Version))
+	if c.Request.Method == http.MethodGet && notModified(c, user.Version) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	body["status"] = "success"
+	body["data"] = user
+	c.JSON(http.StatusOK, body)
+}
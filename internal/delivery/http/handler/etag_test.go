@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Hilmarch27/gin-api/internal/delivery/http/middleware"
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// conflictingUsers mencatat syarat versi yang diterima, lalu gagal seperti
+// update yang kalah cepat dari request lain
+type conflictingUsers struct {
+	usecase.AuthUsecase
+	match *domain.VersionMatch
+	calls int
+}
+
+func (u *conflictingUsers) UpdateUser(_ context.Context, _ *domain.UpdateRequest, match *domain.VersionMatch) (*domain.UserResponse, error) {
+	u.calls++
+	u.match = match
+	return nil, domain.ErrPreconditionFailed.Wrap(domain.ErrUserModified)
+}
+
+func (u *conflictingUsers) PatchUser(_ context.Context, _ uuid.UUID, match *domain.VersionMatch, _ domain.FieldSet, _ domain.UserPatchFunc) (*domain.UserResponse, error) {
+	u.calls++
+	u.match = match
+	return nil, domain.ErrPreconditionFailed.Wrap(domain.ErrUserModified)
+}
+
+func (u *conflictingUsers) DeleteUser(_ context.Context, _ uuid.UUID, match *domain.VersionMatch) error {
+	u.calls++
+	u.match = match
+	return domain.ErrPreconditionFailed.Wrap(domain.ErrUserModified)
+}
+
+func TestAdminUserWritesRequireIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	requests := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+	}{
+		{"update", http.MethodPatch, gin.MIMEJSON, `{"name":"Bob"}`},
+		{"merge patch", http.MethodPatch, MergePatchContentType, `{"name":"Bob"}`},
+		{"delete", http.MethodDelete, "", ""},
+	}
+	tests := []struct {
+		name    string
+		ifMatch string
+		want    int
+		calls   int
+	}{
+		{"missing", "", http.StatusPreconditionRequired, 0},
+		{"version changed while saving", `"3"`, http.StatusPreconditionFailed, 1},
+	}
+
+	for _, rq := range requests {
+		for _, tt := range tests {
+			t.Run(rq.name+"/"+tt.name, func(t *testing.T) {
+				users := &conflictingUsers{}
+				h := NewAuthHandler(users)
+				engine := gin.New()
+				engine.Use(middleware.ErrorHandler())
+				engine.PATCH("/admin/users/:id", h.Update)
+				engine.DELETE("/admin/users/:id", h.Delete)
+
+				req := httptest.NewRequest(rq.method, "/admin/users/"+uuid.NewString(), strings.NewReader(rq.body))
+				if rq.contentType != "" {
+					req.Header.Set("Content-Type", rq.contentType)
+				}
+				if tt.ifMatch != "" {
+					req.Header.Set("If-Match", tt.ifMatch)
+				}
+				w := httptest.NewRecorder()
+				engine.ServeHTTP(w, req)
+
+				if w.Code != tt.want {
+					t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+				}
+				if users.calls != tt.calls {
+					t.Fatalf("usecase called %d times, want %d", users.calls, tt.calls)
+				}
+				if tt.calls > 0 && !users.match.Matches(3) {
+					t.Errorf("usecase got match %+v, want version 3", users.match)
+				}
+			})
+		}
+	}
+}
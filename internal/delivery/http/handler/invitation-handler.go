@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type InvitationHandler struct {
+	invitationUsecase usecase.InvitationUsecase
+	authUsecase       usecase.AuthUsecase
+}
+
+func NewInvitationHandler(iu usecase.InvitationUsecase, au usecase.AuthUsecase) *InvitationHandler {
+	return &InvitationHandler{
+		invitationUsecase: iu,
+		authUsecase:       au,
+	}
+}
+
+// InvitationQuery adalah filter query string untuk daftar invitation
+type InvitationQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending accepted revoked expired"`
+}
+
+// Create mengundang email ke organization aktif dan mengirim email invitation
+func (h *InvitationHandler) Create(c *gin.Context) {
+	me, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	tenant, err := currentTenant(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req domain.CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.ErrInvalidInput.Wrap(err))
+		return
+	}
+
+	inv, err := h.invitationUsecase.Create(c.Request.Context(), tenant, me.ID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Location", c.Request.URL.Path+"/"+inv.ID.String())
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": message(c, "invitation_sent"),
+		"data":    inv,
+	})
+}
+
+func (h *InvitationHandler) List(c *gin.Context) {
+	tenant, err := currentTenant(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var query InvitationQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(domain.ErrInvalidInput.Wrap(err))
+		return
+	}
+
+	invitations, err := h.invitationUsecase.List(c.Request.Context(), domain.InvitationFilter{
+		OrganizationID: tenant.ID,
+		Status:         query.Status,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   invitations,
+	})
+}
+
+// Resend mengirim ulang invitation dengan token dan masa berlaku baru
+func (h *InvitationHandler) Resend(c *gin.Context) {
+	tenant, err := currentTenant(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	id, err := uuid.Parse(c.Param("invitation_id"))
+	if err != nil {
+		c.Error(domain.ErrInvalidInvitationID.Wrap(err))
+		return
+	}
+
+	inv, err := h.invitationUsecase.Resend(c.Request.Context(), tenant, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": message(c, "invitation_resent"),
+		"data":    inv,
+	})
+}
+
+func (h *InvitationHandler) Revoke(c *gin.Context) {
+	tenant, err := currentTenant(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	id, err := uuid.Parse(c.Param("invitation_id"))
+	if err != nil {
+		c.Error(domain.ErrInvalidInvitationID.Wrap(err))
+		return
+	}
+
+	if err := h.invitationUsecase.Revoke(c.Request.Context(), tenant, id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": message(c, "invitation_revoked"),
+	})
+}
+
+// Preview menampilkan organization dan email invitation dari token, agar
+// client tahu apakah perlu meminta password atau data sign-up
+func (h *InvitationHandler) Preview(c *gin.Context) {
+	var req domain.InvitationTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.ErrInvalidInput.Wrap(err))
+		return
+	}
+
+	preview, err := h.invitationUsecase.Preview(c.Request.Context(), req.Token)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   preview,
+	})
+}
+
+// Accept menerima invitation sebagai user yang sedang login, akun lama
+// (dengan password) atau akun baru, lalu login dengan organization
+// tersebut sebagai tenant aktif
+func (h *InvitationHandler) Accept(c *gin.Context) {
+	var req domain.AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.ErrInvalidInput.Wrap(err))
+		return
+	}
+
+	var userID *uuid.UUID
+	if me, err := currentUser(c); err == nil {
+		userID = &me.ID
+	}
+
+	ctx := c.Request.Context()
+	user, membership, err := h.invitationUsecase.Accept(ctx, &req, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	accessToken, refreshToken, err := h.authUsecase.IssueTokens(ctx, user, membership)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	setSessionCookies(c, accessToken, refreshToken)
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": message(c, "invitation_accepted"),
+		"data":    domain.NewOrganizationResponse(membership.Organization, membership.Role),
+	})
+}
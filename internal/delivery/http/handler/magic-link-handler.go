@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type MagicLinkHandler struct {
+	magicLinkUsecase usecase.MagicLinkUsecase
+	authUsecase      usecase.AuthUsecase
+}
+
+func NewMagicLinkHandler(mu usecase.MagicLinkUsecase, au usecase.AuthUsecase) *MagicLinkHandler {
+	return &MagicLinkHandler{
+		magicLinkUsecase: mu,
+		authUsecase:      au,
+	}
+}
+
+// Send mengirim magic link ke email di background. Response selalu sama,
+// baik akun dengan email tersebut ada maupun tidak.
+func (h *MagicLinkHandler) Send(c *gin.Context) {
+	var req domain.MagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.ErrInvalidInput.Wrap(err))
+		return
+	}
+
+	if err := h.magicLinkUsecase.Send(c.Request.Context(), &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"status":  "success",
+		"message": message(c, "magic_link_sent"),
+	})
+}
+
+// Verify menukar token magic link dengan cookie sesi seperti
+// AuthHandler.Login. Hanya tersedia lewat POST agar link yang dibuka
+// scanner email (GET) tidak menghabiskan token.
+func (h *MagicLinkHandler) Verify(c *gin.Context) {
+	var req domain.VerifyMagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.ErrInvalidInput.Wrap(err))
+		return
+	}
+
+	ctx := c.Request.Context()
+	user, err := h.magicLinkUsecase.Verify(ctx, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	accessToken, refreshToken, err := h.authUsecase.CompleteLogin(ctx, user, domain.LoginMethodMagicLink)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	setSessionCookies(c, accessToken, refreshToken)
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": message(c, "login_successful"),
+	})
+}
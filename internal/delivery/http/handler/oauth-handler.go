@@ -0,0 +1,331 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Hilmarch27/gin-api/internal/delivery/http/middleware"
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+)
+
+type OAuthHandler struct {
+	oauthUsecase usecase.OAuthUsecase
+}
+
+func NewOAuthHandler(ou usecase.OAuthUsecase) *OAuthHandler {
+	return &OAuthHandler{
+		oauthUsecase: ou,
+	}
+}
+
+// oauthClientID membaca ID client OAuth dari path
+func oauthClientID(c *gin.Context) (uuid.UUID, error) {
+	id, err := uuid.Parse(c.Param("client_id"))
+	if err != nil {
+		return uuid.Nil, domain.ErrInvalidOAuthClientID.Wrap(err)
+	}
+	return id, nil
+}
+
+// clientCredentials mengambil credential client dari HTTP Basic (nilai
+// di-encode form-urlencoded, RFC 6749 bagian 2.3.1) atau dari body
+func clientCredentials(c *gin.Context, id, secret string) (domain.ClientCredentials, error) {
+	user, pass, ok := c.Request.BasicAuth()
+	if !ok {
+		return domain.ClientCredentials{ID: id, Secret: secret}, nil
+	}
+	basicID, err := url.QueryUnescape(user)
+	if err != nil {
+		return domain.ClientCredentials{}, domain.ErrInvalidClient.Wrap(err)
+	}
+	basicSecret, err := url.QueryUnescape(pass)
+	if err != nil {
+		return domain.ClientCredentials{}, domain.ErrInvalidClient.Wrap(err)
+	}
+	return domain.ClientCredentials{ID: basicID, Secret: basicSecret}, nil
+}
+
+// Discovery melayani /.well-known/openid-configuration
+func (h *OAuthHandler) Discovery(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, h.oauthUsecase.Discovery())
+}
+
+// JWKS melayani /.well-known/jwks.json, public key untuk verifikasi token
+func (h *OAuthHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, h.oauthUsecase.JWKS())
+}
+
+// Authorize memulai authorization code flow. Browser selalu di-redirect:
+// ke halaman login, consent screen, atau redirect_uri client.
+func (h *OAuthHandler) Authorize(c *gin.Context) {
+	var req domain.AuthorizeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(domain.ErrInvalidInput.Wrap(err))
+		return
+	}
+
+	// Hanya sesi login yang bisa memberi izin, bukan token script
+	var userID *uuid.UUID
+	if principal, ok := middleware.CurrentPrincipal(c); ok && principal.Session() {
+		userID = &principal.ID
+	}
+
+	redirect, err := h.oauthUsecase.Authorize(c.Request.Context(), &req, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, redirect)
+}
+
+// ConsentDetails mengembalikan client dan scope untuk consent screen
+func (h *OAuthHandler) ConsentDetails(c *gin.Context) {
+	me, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	request := c.Query("request")
+	if request == "" {
+		c.Error(domain.ErrInvalidAuthorizationRequest)
+		return
+	}
+
+	details, err := h.oauthUsecase.ConsentDetails(c.Request.Context(), me.ID, request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   details,
+	})
+}
+
+// Consent menyimpan keputusan user; frontend lalu mengarahkan browser ke
+// redirect_to
+func (h *OAuthHandler) Consent(c *gin.Context) {
+	me, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req domain.ConsentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.ErrInvalidInput.Wrap(err))
+		return
+	}
+
+	redirect, err := h.oauthUsecase.Consent(c.Request.Context(), me.ID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   domain.AuthorizeResponse{RedirectTo: redirect},
+	})
+}
+
+// Token adalah endpoint token OAuth. Response memakai format OAuth, bukan
+// envelope status/data.
+func (h *OAuthHandler) Token(c *gin.Context) {
+	var req domain.TokenRequest
+	if err := c.ShouldBindWith(&req, binding.FormPost); err != nil {
+		c.Error(domain.ErrInvalidInput.Wrap(err))
+		return
+	}
+	creds, err := clientCredentials(c, req.ClientID, req.ClientSecret)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp, err := h.oauthUsecase.Token(c.Request.Context(), &req, creds)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+	c.JSON(http.StatusOK, resp)
+}
+
+// Revoke mencabut refresh token (RFC 7009); token yang tidak dikenal tetap
+// dijawab 200
+func (h *OAuthHandler) Revoke(c *gin.Context) {
+	var req domain.RevokeTokenRequest
+	if err := c.ShouldBindWith(&req, binding.FormPost); err != nil {
+		c.Error(domain.ErrInvalidInput.Wrap(err))
+		return
+	}
+	creds, err := clientCredentials(c, req.ClientID, req.ClientSecret)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.oauthUsecase.Revoke(c.Request.Context(), &req, creds); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// UserInfo mengembalikan claim user pemilik access token OAuth
+func (h *OAuthHandler) UserInfo(c *gin.Context) {
+	scheme, token, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		c.Error(domain.ErrInvalidToken)
+		return
+	}
+
+	info, err := h.oauthUsecase.UserInfo(c.Request.Context(), strings.TrimSpace(token))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, info)
+}
+
+// ListGrants mengembalikan aplikasi yang sudah diberi akses oleh user
+func (h *OAuthHandler) ListGrants(c *gin.Context) {
+	me, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	grants, err := h.oauthUsecase.ListGrants(c.Request.Context(), me.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   grants,
+	})
+}
+
+// RevokeGrant mencabut akses aplikasi beserta refresh token-nya
+func (h *OAuthHandler) RevokeGrant(c *gin.Context) {
+	me, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	clientID, err := oauthClientID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.oauthUsecase.RevokeGrant(c.Request.Context(), me.ID, clientID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": message(c, "oauth_grant_revoked"),
+	})
+}
+
+func (h *OAuthHandler) ListClients(c *gin.Context) {
+	clients, err := h.oauthUsecase.ListClients(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   clients,
+	})
+}
+
+// CreateClient mendaftarkan client; client secret hanya ada di response ini
+func (h *OAuthHandler) CreateClient(c *gin.Context) {
+	me, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req domain.CreateOAuthClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.ErrInvalidInput.Wrap(err))
+		return
+	}
+
+	client, err := h.oauthUsecase.CreateClient(c.Request.Context(), me.ID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Client secret tidak boleh disimpan cache
+	c.Header("Cache-Control", "no-store")
+	c.Header("Location", c.Request.URL.Path+"/"+client.ClientID.String())
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": message(c, "oauth_client_created"),
+		"data":    client,
+	})
+}
+
+func (h *OAuthHandler) GetClient(c *gin.Context) {
+	id, err := oauthClientID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	client, err := h.oauthUsecase.GetClient(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   client,
+	})
+}
+
+// DeleteClient menghapus client dan mencabut semua refresh token-nya
+func (h *OAuthHandler) DeleteClient(c *gin.Context) {
+	id, err := oauthClientID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.oauthUsecase.DeleteClient(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": message(c, "oauth_client_deleted"),
+	})
+}
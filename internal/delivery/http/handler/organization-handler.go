@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Hilmarch27/gin-api/internal/delivery/http/middleware"
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type OrganizationHandler struct {
+	orgUsecase  usecase.OrganizationUsecase
+	authUsecase usecase.AuthUsecase
+}
+
+func NewOrganizationHandler(ou usecase.OrganizationUsecase, au usecase.AuthUsecase) *OrganizationHandler {
+	return &OrganizationHandler{
+		orgUsecase:  ou,
+		authUsecase: au,
+	}
+}
+
+// currentTenant mengambil organization aktif yang ditentukan middleware Tenant
+func currentTenant(c *gin.Context) (domain.Tenant, error) {
+	tenant, ok := c.Get(middleware.TenantKey)
+	if !ok {
+		return domain.Tenant{}, domain.ErrOrganizationNotFound
+	}
+	return tenant.(domain.Tenant), nil
+}
+
+// List mengembalikan organization yang diikuti user yang sedang login
+func (h *OrganizationHandler) List(c *gin.Context) {
+	me, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	orgs, err := h.orgUsecase.ListForUser(c.Request.Context(), me.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   orgs,
+	})
+}
+
+// Create membuat organization dengan user yang sedang login sebagai owner
+func (h *OrganizationHandler) Create(c *gin.Context) {
+	me, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req domain.CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.ErrInvalidInput.Wrap(err))
+		return
+	}
+
+	org, err := h.orgUsecase.Create(c.Request.Context(), me.ID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Location", c.Request.URL.Path+"/"+org.Slug)
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": message(c, "organization_created"),
+		"data":    org,
+	})
+}
+
+func (h *OrganizationHandler) Get(c *gin.Context) {
+	tenant, err := currentTenant(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	org, err := h.orgUsecase.Get(c.Request.Context(), tenant)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   org,
+	})
+}
+
+// Switch menjadikan organization di path sebagai tenant aktif di token baru
+func (h *OrganizationHandler) Switch(c *gin.Context) {
+	me, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	tenant, err := currentTenant(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	accessToken, refreshToken, err := h.authUsecase.SwitchOrganization(c.Request.Context(), me.ID, tenant.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	setSessionCookies(c, accessToken, refreshToken)
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": message(c, "organization_switched"),
+	})
+}
+
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	tenant, err := currentTenant(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	members, err := h.orgUsecase.ListMembers(c.Request.Context(), tenant.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   members,
+	})
+}
+
+func (h *OrganizationHandler) AddMember(c *gin.Context) {
+	tenant, err := currentTenant(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req domain.AddMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.ErrInvalidInput.Wrap(err))
+		return
+	}
+
+	member, err := h.orgUsecase.AddMember(c.Request.Context(), tenant, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": message(c, "member_added"),
+		"data":    member,
+	})
+}
+
+func (h *OrganizationHandler) UpdateMember(c *gin.Context) {
+	tenant, err := currentTenant(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.Error(domain.ErrInvalidUserID.Wrap(err))
+		return
+	}
+
+	var req domain.UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.ErrInvalidInput.Wrap(err))
+		return
+	}
+
+	member, err := h.orgUsecase.UpdateMember(c.Request.Context(), tenant, userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": message(c, "member_updated"),
+		"data":    member,
+	})
+}
+
+// RemoveMember mengeluarkan anggota; anggota biasa hanya bisa mengeluarkan
+// dirinya sendiri (keluar dari organization)
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	me, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	tenant, err := currentTenant(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.Error(domain.ErrInvalidUserID.Wrap(err))
+		return
+	}
+
+	if err := h.orgUsecase.RemoveMember(c.Request.Context(), tenant, me.ID, userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": message(c, "member_removed"),
+	})
+}
@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// passkeySessionCookie menyimpan ID sesi ceremony WebAuthn (challenge
+// tersimpan di server) di antara request options dan verifikasi
+const passkeySessionCookie = "passkey_session"
+
+type PasskeyHandler struct {
+	passkeyUsecase usecase.PasskeyUsecase
+	authUsecase    usecase.AuthUsecase
+}
+
+func NewPasskeyHandler(pu usecase.PasskeyUsecase, au usecase.AuthUsecase) *PasskeyHandler {
+	return &PasskeyHandler{
+		passkeyUsecase: pu,
+		authUsecase:    au,
+	}
+}
+
+// setPasskeySession menyimpan sesi ceremony; value kosong menghapusnya
+func setPasskeySession(c *gin.Context, session string) {
+	maxAge := 300 // 5 menit
+	if session == "" {
+		maxAge = -1
+	}
+	c.SetCookie(passkeySessionCookie, session, maxAge, "/", "", false, true)
+	c.Header("Cache-Control", "no-store")
+}
+
+// takePasskeySession membaca sesi ceremony lalu menghapus cookie-nya;
+// challenge-nya sendiri dihapus server saat diverifikasi
+func takePasskeySession(c *gin.Context) string {
+	session, _ := c.Cookie(passkeySessionCookie)
+	setPasskeySession(c, "")
+	return session
+}
+
+// LoginOptions mengembalikan options untuk navigator.credentials.get()
+func (h *PasskeyHandler) LoginOptions(c *gin.Context) {
+	options, session, err := h.passkeyUsecase.LoginOptions(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	setPasskeySession(c, session)
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   options,
+	})
+}
+
+// Login memverifikasi passkey lalu menerbitkan cookie sesi seperti
+// AuthHandler.Login
+func (h *PasskeyHandler) Login(c *gin.Context) {
+	var req domain.PasskeyLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.ErrInvalidInput.Wrap(err))
+		return
+	}
+
+	ctx := c.Request.Context()
+	user, err := h.passkeyUsecase.Login(ctx, &req, takePasskeySession(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	accessToken, refreshToken, err := h.authUsecase.CompleteLogin(ctx, user, domain.LoginMethodPasskey)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	setSessionCookies(c, accessToken, refreshToken)
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": message(c, "login_successful"),
+	})
+}
+
+// List mengembalikan passkey milik user yang sedang login
+func (h *PasskeyHandler) List(c *gin.Context) {
+	me, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	passkeys, err := h.passkeyUsecase.List(c.Request.Context(), me.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   passkeys,
+	})
+}
+
+// RegistrationOptions mengembalikan options untuk
+// navigator.credentials.create()
+func (h *PasskeyHandler) RegistrationOptions(c *gin.Context) {
+	me, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	options, session, err := h.passkeyUsecase.RegistrationOptions(c.Request.Context(), me.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	setPasskeySession(c, session)
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   options,
+	})
+}
+
+// Register menyimpan passkey baru dari hasil navigator.credentials.create()
+func (h *PasskeyHandler) Register(c *gin.Context) {
+	me, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req domain.RegisterPasskeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.ErrInvalidInput.Wrap(err))
+		return
+	}
+
+	passkey, err := h.passkeyUsecase.Register(c.Request.Context(), me.ID, &req, takePasskeySession(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Location", c.Request.URL.Path+"/"+passkey.ID.String())
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": message(c, "passkey_registered"),
+		"data":    passkey,
+	})
+}
+
+func (h *PasskeyHandler) Rename(c *gin.Context) {
+	me, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	id, err := uuid.Parse(c.Param("passkey_id"))
+	if err != nil {
+		c.Error(domain.ErrInvalidPasskeyID.Wrap(err))
+		return
+	}
+
+	var req domain.RenamePasskeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.ErrInvalidInput.Wrap(err))
+		return
+	}
+
+	passkey, err := h.passkeyUsecase.Rename(c.Request.Context(), me.ID, id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": message(c, "passkey_renamed"),
+		"data":    passkey,
+	})
+}
+
+func (h *PasskeyHandler) Delete(c *gin.Context) {
+	me, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	id, err := uuid.Parse(c.Param("passkey_id"))
+	if err != nil {
+		c.Error(domain.ErrInvalidPasskeyID.Wrap(err))
+		return
+	}
+
+	if err := h.passkeyUsecase.Delete(c.Request.Context(), me.ID, id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": message(c, "passkey_deleted"),
+	})
+}
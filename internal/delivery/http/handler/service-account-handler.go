@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ServiceAccountHandler struct {
+	serviceUsecase usecase.ServiceAccountUsecase
+}
+
+func NewServiceAccountHandler(su usecase.ServiceAccountUsecase) *ServiceAccountHandler {
+	return &ServiceAccountHandler{
+		serviceUsecase: su,
+	}
+}
+
+// serviceAccountID membaca ID service account dari path
+func serviceAccountID(c *gin.Context) (uuid.UUID, error) {
+	id, err := uuid.Parse(c.Param("service_account_id"))
+	if err != nil {
+		return uuid.Nil, domain.ErrInvalidServiceAccountID.Wrap(err)
+	}
+	return id, nil
+}
+
+// apiKeyIDs membaca ID service account dan ID API key dari path
+func apiKeyIDs(c *gin.Context) (uuid.UUID, uuid.UUID, error) {
+	accountID, err := serviceAccountID(c)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	id, err := uuid.Parse(c.Param("key_id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, domain.ErrInvalidAPIKeyID.Wrap(err)
+	}
+	return accountID, id, nil
+}
+
+func (h *ServiceAccountHandler) List(c *gin.Context) {
+	accounts, err := h.serviceUsecase.List(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   accounts,
+	})
+}
+
+// Create membuat service account dengan admin yang sedang login sebagai
+// pembuatnya
+func (h *ServiceAccountHandler) Create(c *gin.Context) {
+	me, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req domain.CreateServiceAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.ErrInvalidInput.Wrap(err))
+		return
+	}
+
+	account, err := h.serviceUsecase.Create(c.Request.Context(), me.ID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Location", c.Request.URL.Path+"/"+account.ID.String())
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": message(c, "service_account_created"),
+		"data":    account,
+	})
+}
+
+func (h *ServiceAccountHandler) Get(c *gin.Context) {
+	id, err := serviceAccountID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	account, err := h.serviceUsecase.Get(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   account,
+	})
+}
+
+// Delete menghapus service account beserta semua API key-nya
+func (h *ServiceAccountHandler) Delete(c *gin.Context) {
+	id, err := serviceAccountID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.serviceUsecase.Delete(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": message(c, "service_account_deleted"),
+	})
+}
+
+func (h *ServiceAccountHandler) ListKeys(c *gin.Context) {
+	id, err := serviceAccountID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	keys, err := h.serviceUsecase.ListKeys(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   keys,
+	})
+}
+
+// CreateKey membuat API key; key utuh hanya ada di response ini
+func (h *ServiceAccountHandler) CreateKey(c *gin.Context) {
+	id, err := serviceAccountID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req domain.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.ErrInvalidInput.Wrap(err))
+		return
+	}
+
+	key, err := h.serviceUsecase.CreateKey(c.Request.Context(), id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Key rahasia tidak boleh disimpan cache
+	c.Header("Cache-Control", "no-store")
+	c.Header("Location", c.Request.URL.Path+"/"+key.ID.String())
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": message(c, "api_key_created"),
+		"data":    key,
+	})
+}
+
+// RotateKey membuat key pengganti; key lama berlaku sampai masa overlap habis
+func (h *ServiceAccountHandler) RotateKey(c *gin.Context) {
+	accountID, id, err := apiKeyIDs(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Body boleh kosong untuk memakai overlap default
+	var req domain.RotateAPIKeyRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(domain.ErrInvalidInput.Wrap(err))
+			return
+		}
+	}
+
+	key, err := h.serviceUsecase.RotateKey(c.Request.Context(), accountID, id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": message(c, "api_key_rotated"),
+		"data":    key,
+	})
+}
+
+func (h *ServiceAccountHandler) RevokeKey(c *gin.Context) {
+	accountID, id, err := apiKeyIDs(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.serviceUsecase.RevokeKey(c.Request.Context(), accountID, id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": message(c, "api_key_revoked"),
+	})
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Hilmarch27/gin-api/internal/delivery/http/middleware"
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ssoStateCookie menyimpan state login SSO selama user berada di provider
+const ssoStateCookie = "sso_state"
+
+type SSOHandler struct {
+	ssoUsecase  usecase.SSOUsecase
+	authUsecase usecase.AuthUsecase
+}
+
+func NewSSOHandler(su usecase.SSOUsecase, au usecase.AuthUsecase) *SSOHandler {
+	return &SSOHandler{
+		ssoUsecase:  su,
+		authUsecase: au,
+	}
+}
+
+// Providers mengembalikan identity provider yang bisa dipakai login
+func (h *SSOHandler) Providers(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   h.ssoUsecase.Providers(),
+	})
+}
+
+// Start mengarahkan browser ke halaman login provider
+func (h *SSOHandler) Start(c *gin.Context) {
+	var req domain.SSOStartRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(domain.ErrInvalidInput.Wrap(err))
+		return
+	}
+
+	// Menghubungkan provider hanya bisa dari sesi login
+	var userID *uuid.UUID
+	if principal, ok := middleware.CurrentPrincipal(c); ok && principal.Session() {
+		userID = &principal.ID
+	}
+
+	redirect, state, err := h.ssoUsecase.Start(c.Request.Context(), c.Param("provider"), &req, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// SameSite Lax agar cookie ikut terkirim saat provider me-redirect
+	// kembali ke callback
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(ssoStateCookie, state, 600, "/", "", false, true) // 10 menit
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, redirect)
+}
+
+// Callback menerima redirect dari provider. Browser selalu diarahkan ke
+// frontend: ke return_to dengan cookie sesi, atau ke halaman error.
+func (h *SSOHandler) Callback(c *gin.Context) {
+	var req domain.SSOCallbackRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(domain.ErrInvalidInput.Wrap(err))
+		return
+	}
+
+	// State hanya berlaku sekali
+	state, _ := c.Cookie(ssoStateCookie)
+	c.SetCookie(ssoStateCookie, "", -1, "/", "", false, true)
+	c.Header("Cache-Control", "no-store")
+
+	ctx := c.Request.Context()
+	provider := c.Param("provider")
+	result, err := h.ssoUsecase.Callback(ctx, provider, &req, state)
+	if err == nil && !result.Linked {
+		var accessToken, refreshToken string
+		accessToken, refreshToken, err = h.authUsecase.CompleteLogin(ctx, result.User, domain.LoginMethodSSO+provider)
+		if err == nil {
+			setSessionCookies(c, accessToken, refreshToken)
+		}
+	}
+	if err != nil {
+		// Error internal tetap dicatat dan dirender ErrorHandler
+		if domain.KindOf(err) == domain.KindInternal {
+			c.Error(err)
+			return
+		}
+		c.Redirect(http.StatusFound, h.ssoUsecase.ErrorRedirect(err))
+		return
+	}
+
+	c.Redirect(http.StatusFound, result.ReturnTo)
+}
+
+// ListIdentities mengembalikan provider yang terhubung ke user
+func (h *SSOHandler) ListIdentities(c *gin.Context) {
+	me, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	identities, err := h.ssoUsecase.ListIdentities(c.Request.Context(), me.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   identities,
+	})
+}
+
+// Unlink memutus provider dari user
+func (h *SSOHandler) Unlink(c *gin.Context) {
+	me, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	id, err := uuid.Parse(c.Param("identity_id"))
+	if err != nil {
+		c.Error(domain.ErrInvalidIdentityID.Wrap(err))
+		return
+	}
+
+	if err := h.ssoUsecase.Unlink(c.Request.Context(), me.ID, id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": message(c, "identity_unlinked"),
+	})
+}
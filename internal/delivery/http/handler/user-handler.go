@@ -3,168 +3,319 @@ package handler
 import (
 	"net/http"
 
+	"github.com/Hilmarch27/gin-api/internal/delivery/http/middleware"
 	"github.com/Hilmarch27/gin-api/internal/domain"
 	"github.com/Hilmarch27/gin-api/internal/usecase"
+	"github.com/Hilmarch27/gin-api/pkg/i18n"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 type AuthHandler struct {
-    authUsecase usecase.AuthUsecase
+	authUsecase usecase.AuthUsecase
 }
 
 func NewAuthHandler(au usecase.AuthUsecase) *AuthHandler {
-    return &AuthHandler{
-        authUsecase: au,
-    }
+	return &AuthHandler{
+		authUsecase: au,
+	}
+}
+
+// message menerjemahkan pesan sukses sesuai bahasa request
+func message(c *gin.Context, key string) string {
+	return i18n.T(i18n.FromContext(c.Request.Context()), key)
 }
 
 func (h *AuthHandler) Register(c *gin.Context) {
-    // Parsing input dari request body
-    var req domain.RegisterRequest
-    if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
-        return
-    }
+	// Parsing input dari request body
+	var req domain.RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.ErrInvalidInput.Wrap(err))
+		return
+	}
 
-    // Panggil usecase untuk memproses registrasi
-    if err := h.authUsecase.Register(&req); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-        return
-    }
+	// Panggil usecase untuk memproses registrasi
+	if err := h.authUsecase.Register(c.Request.Context(), &req); err != nil {
+		c.Error(err)
+		return
+	}
 
-    // Response sukses
-    c.JSON(http.StatusCreated, gin.H{
-        "status":  "success",
-        "message": "user registered successfully",
-    })
+	// Response sukses
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": message(c, "user_registered"),
+	})
 }
 
-
 func (h *AuthHandler) Login(c *gin.Context) {
-    var req domain.LoginRequest
-    if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
-        return
-    }
+	var req domain.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.ErrInvalidInput.Wrap(err))
+		return
+	}
 
-    accessToken, refreshToken, err := h.authUsecase.Login(&req)
-    if err != nil {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-        return
-    }
+	accessToken, refreshToken, err := h.authUsecase.Login(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
 
-    // Set cookies
-    c.SetCookie("access_token", accessToken, 3600, "/", "", false, true)  // 1 hour
-    c.SetCookie("refresh_token", refreshToken, 604800, "/", "", false, true) // 1 week
+	// Set cookies
+	setSessionCookies(c, accessToken, refreshToken)
 
-    c.JSON(http.StatusOK, gin.H{
-        "status":  "success",
-        "message": "login successful",
-    })
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": message(c, "login_successful"),
+	})
 }
 
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
-    // Ambil refresh token dari cookie
-    refreshToken, err := c.Cookie("refresh_token")
-    if err != nil {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "refresh token not found"})
-        return
-    }
-
-    // Panggil usecase untuk refresh token
-    accessToken, newRefreshToken, err := h.authUsecase.RefreshToken(refreshToken)
-    if err != nil {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-        return
-    }
-
-    // Set cookies baru untuk access token dan refresh token
-    c.SetCookie("access_token", accessToken, 3600, "/", "", false, true)
-    c.SetCookie("refresh_token", newRefreshToken, 604800, "/", "", false, true)
-
-    c.JSON(http.StatusOK, gin.H{
-        "status":  "success",
-        "message": "tokens refreshed successfully",
-    })
-}
-
-func (h *AuthHandler) GetUserByID(c *gin.Context) {
-    user, exists := c.Get("user")
-    if !exists || user == nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "UserId not found"})
-        return
-    }
-
-    // Type assertion ke *domain.User
-    userObj, ok := user.(*domain.User)
-    if !ok {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user object"})
-        return
-    }
-
-    userId := userObj.ID
-
-    userResponse, err := h.authUsecase.GetUserByID(userId)
-    if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-        return
-    }
-
-    c.JSON(http.StatusOK, gin.H{
-        "status": "success",
-        "data":   userResponse,
-    })
+	// Ambil refresh token dari cookie
+	refreshToken, err := c.Cookie("refresh_token")
+	if err != nil {
+		c.Error(domain.ErrInvalidRefreshToken.Wrap(err))
+		return
+	}
+
+	// Panggil usecase untuk refresh token
+	accessToken, newRefreshToken, err := h.authUsecase.RefreshToken(c.Request.Context(), refreshToken)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Set cookies baru untuk access token dan refresh token
+	setSessionCookies(c, accessToken, newRefreshToken)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": message(c, "tokens_refreshed"),
+	})
+}
+
+// setSessionCookies menyimpan access token dan refresh token sebagai
+// cookie HttpOnly
+func setSessionCookies(c *gin.Context, accessToken, refreshToken string) {
+	c.SetCookie("access_token", accessToken, 3600, "/", "", false, true)     // 1 hour
+	c.SetCookie("refresh_token", refreshToken, 604800, "/", "", false, true) // 1 week
+}
+
+// currentUser mengambil user yang sudah diautentikasi dari context. Service
+// account ditolak karena aksi ini hanya berlaku untuk user.
+func currentUser(c *gin.Context) (*domain.User, error) {
+	principal, ok := middleware.CurrentPrincipal(c)
+	if !ok {
+		return nil, domain.ErrUnauthorized
+	}
+	if !principal.IsUser() {
+		return nil, domain.ErrUserRequired
+	}
+
+	return &domain.User{
+		ID:     principal.ID,
+		Role:   principal.Role,
+		Locale: principal.Locale,
+	}, nil
+}
+
+// GetMe mengembalikan profil user yang sedang login
+func (h *AuthHandler) GetMe(c *gin.Context) {
+	me, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	userResponse, err := h.authUsecase.GetUserByID(c.Request.Context(), me.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	respondUser(c, userResponse, gin.H{})
+}
+
+// UpdateMe mengubah profil user yang sedang login (tanpa role). Body bisa
+// berupa JSON biasa, JSON Merge Patch atau JSON Patch.
+func (h *AuthHandler) UpdateMe(c *gin.Context) {
+	me, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if isPatchDocument(c) {
+		h.patchUser(c, me.ID, ifMatch(c), domain.OwnerWritableUserFields)
+		return
+	}
+	if !isPlainJSON(c) {
+		c.Error(domain.ErrUnsupportedPatch)
+		return
+	}
+
+	var req domain.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.ErrInvalidInput.Wrap(err))
+		return
+	}
+
+	update := &domain.UpdateRequest{ID: me.ID, UpdateProfileRequest: req}
+	userResponse, err := h.authUsecase.UpdateUser(c.Request.Context(), update, ifMatch(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	respondUser(c, userResponse, gin.H{"message": message(c, "user_updated")})
+}
+
+// DeleteMe menghapus akun user yang sedang login, password wajib dikonfirmasi
+func (h *AuthHandler) DeleteMe(c *gin.Context) {
+	me, err := currentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req domain.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.ErrInvalidInput.Wrap(err))
+		return
+	}
+
+	if err := h.authUsecase.DeleteAccount(c.Request.Context(), me.ID, req.Password, ifMatch(c)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Akun sudah tidak ada, hapus cookie sesi
+	c.SetCookie("access_token", "", -1, "/", "", false, true)
+	c.SetCookie("refresh_token", "", -1, "/", "", false, true)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": message(c, "account_deleted"),
+	})
+}
+
+// UserQuery adalah filter query string untuk GET /api/admin/users
+type UserQuery struct {
+	Role   string `form:"role" binding:"omitempty,oneof=admin user guest"`
+	Search string `form:"q"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// ListUsers mengembalikan daftar user untuk admin
+func (h *AuthHandler) ListUsers(c *gin.Context) {
+	var query UserQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(domain.ErrInvalidInput.Wrap(err))
+		return
+	}
+
+	users, total, err := h.authUsecase.ListUsers(c.Request.Context(), domain.UserFilter{
+		Role:   query.Role,
+		Search: query.Search,
+		Limit:  query.Limit,
+		Offset: query.Offset,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data":   users,
+		"total":  total,
+	})
+}
+
+// GetUser mengembalikan user berdasarkan ID untuk admin
+func (h *AuthHandler) GetUser(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(domain.ErrInvalidUserID.Wrap(err))
+		return
+	}
+
+	userResponse, err := h.authUsecase.GetUserByID(c.Request.Context(), userId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	respondUser(c, userResponse, gin.H{})
 }
 
 func (h *AuthHandler) Update(c *gin.Context) {
-    // Ambil ID dari parameter URL
-    userId, err := uuid.Parse(c.Param("id"))
-    if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
-        return
-    }
-
-    // Bind data JSON ke UpdateRequest tanpa ID
-    var req domain.UpdateRequest
-    if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "detail": err.Error()})
-        return
-    }
-
-    // Tambahkan ID dari URL ke objek request
-    req.ID = userId
-
-    // Panggil usecase untuk update user
-    if err := h.authUsecase.UpdateUser(&req); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-        return
-    }
-
-    // Kirimkan response sukses
-    c.JSON(http.StatusOK, gin.H{
-        "status":  "success",
-        "message": "User updated successfully",
-    })
+	// Ambil ID dari parameter URL
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(domain.ErrInvalidUserID.Wrap(err))
+		return
+	}
+
+	// Admin wajib mengirim If-Match agar tidak menimpa perubahan lain
+	match, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	// Merge Patch / JSON Patch, admin boleh mengubah semua field termasuk role
+	if isPatchDocument(c) {
+		h.patchUser(c, userId, match, domain.AdminWritableUserFields)
+		return
+	}
+	if !isPlainJSON(c) {
+		c.Error(domain.ErrUnsupportedPatch)
+		return
+	}
+
+	// Bind data JSON ke UpdateRequest tanpa ID
+	var req domain.UpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.ErrInvalidInput.Wrap(err))
+		return
+	}
+
+	// Tambahkan ID dari URL ke objek request
+	req.ID = userId
+
+	// Panggil usecase untuk update user, If-Match mencegah menimpa perubahan orang lain
+	userResponse, err := h.authUsecase.UpdateUser(c.Request.Context(), &req, match)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Kirimkan response sukses beserta ETag versi baru
+	respondUser(c, userResponse, gin.H{"message": message(c, "user_updated")})
 }
 
 func (h *AuthHandler) Delete(c *gin.Context) {
-    // Ambil ID dari parameter URL
-    userId, err := uuid.Parse(c.Param("id"))
-    if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
-        return
-    }
-
-    // Panggil usecase untuk delete user
-    if err := h.authUsecase.DeleteUser(userId); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-        return
-    }
-
-    // Kirimkan response sukses
-    c.JSON(http.StatusOK, gin.H{
-        "status":  "success",
-        "message": "User deleted successfully",
-    })
-}
\ No newline at end of file
+	// Ambil ID dari parameter URL
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(domain.ErrInvalidUserID.Wrap(err))
+		return
+	}
+
+	match, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	// Panggil usecase untuk delete user
+	if err := h.authUsecase.DeleteUser(c.Request.Context(), userId, match); err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Kirimkan response sukses
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": message(c, "user_deleted"),
+	})
+}
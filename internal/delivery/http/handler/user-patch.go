@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Hilmarch27/gin-api/internal/delivery/http/validation"
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Content type PATCH selain application/json
+const (
+	MergePatchContentType = "application/merge-patch+json" // RFC 7396
+	JSONPatchContentType  = "application/json-patch+json"  // RFC 6902
+)
+
+// maxPatchBytes membatasi ukuran body patch
+const maxPatchBytes = 64 << 10
+
+// isPatchDocument mengecek apakah request memakai salah satu format patch
+func isPatchDocument(c *gin.Context) bool {
+	switch c.ContentType() {
+	case MergePatchContentType, JSONPatchContentType:
+		return true
+	}
+	return false
+}
+
+// isPlainJSON mengecek content type body update biasa (field pointer)
+func isPlainJSON(c *gin.Context) bool {
+	switch c.ContentType() {
+	case "", gin.MIMEJSON:
+		return true
+	}
+	return false
+}
+
+// patchUser menerapkan body merge patch / JSON patch ke user id dengan
+// syarat versi match. Field yang boleh diubah ditentukan writable (pemilik
+// akun atau admin).
+func (h *AuthHandler) patchUser(c *gin.Context, id uuid.UUID, match *domain.VersionMatch, writable domain.FieldSet) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxPatchBytes))
+	if err != nil {
+		c.Error(domain.ErrInvalidPatch.Wrap(err))
+		return
+	}
+
+	patch, err := newUserPatch(c.ContentType(), body)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	userResponse, err := h.authUsecase.PatchUser(c.Request.Context(), id, match, writable, patch)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	respondUser(c, userResponse, gin.H{"message": message(c, "user_updated")})
+}
+
+// newUserPatch mem-parse body patch lalu mengembalikan fungsi yang
+// menerapkannya ke dokumen user. Field yang berubah divalidasi dengan tag
+// binding UserDocument; field yang tidak disentuh tidak divalidasi ulang.
+func newUserPatch(contentType string, body []byte) (domain.UserPatchFunc, error) {
+	var apply func(doc []byte) ([]byte, error)
+
+	switch contentType {
+	case MergePatchContentType:
+		if !json.Valid(body) || !bytes.HasPrefix(bytes.TrimSpace(body), []byte("{")) {
+			return nil, domain.ErrInvalidPatch.Wrap(errors.New("merge patch must be a JSON object"))
+		}
+		apply = func(doc []byte) ([]byte, error) {
+			return jsonpatch.MergePatch(doc, body)
+		}
+	case JSONPatchContentType:
+		ops, err := jsonpatch.DecodePatch(body)
+		if err != nil {
+			return nil, domain.ErrInvalidPatch.Wrap(err)
+		}
+		apply = ops.Apply
+	default:
+		return nil, domain.ErrUnsupportedPatch
+	}
+
+	return func(doc *domain.UserDocument) error {
+		original, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+
+		patched, err := apply(original)
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			return domain.ErrPatchTestFailed.Wrap(err)
+		}
+		if err != nil {
+			return domain.ErrInvalidPatch.Wrap(err)
+		}
+
+		// Patch tidak boleh menambah field di luar dokumen user
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(patched, &fields); err != nil {
+			return domain.ErrInvalidPatch.Wrap(err)
+		}
+		known := domain.NewFieldSet(domain.UserDocumentFields()...)
+		var unknown domain.FieldViolations
+		for field := range fields {
+			if !known[field] {
+				unknown = append(unknown, domain.FieldViolation{Field: field, Rule: "unknown"})
+			}
+		}
+		if len(unknown) > 0 {
+			return domain.ErrInvalidInput.Wrap(unknown)
+		}
+
+		// Decode ke dokumen kosong: field yang dihapus atau di-set null
+		// menjadi string kosong
+		var next domain.UserDocument
+		if err := json.Unmarshal(patched, &next); err != nil {
+			return domain.ErrInvalidInput.Wrap(err)
+		}
+
+		if err := validation.Fields(next, doc.ChangedFields(next)...); err != nil {
+			return domain.ErrInvalidInput.Wrap(err)
+		}
+
+		*doc = next
+		return nil
+	}, nil
+}
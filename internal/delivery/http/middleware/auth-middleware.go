@@ -2,22 +2,44 @@ package middleware
 
 import (
 	"errors"
-	"fmt"
-	"net/http"
+	"strings"
 
 	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/usecase"
+	"github.com/Hilmarch27/gin-api/pkg/i18n"
+	"github.com/Hilmarch27/gin-api/pkg/logger"
+	"github.com/Hilmarch27/gin-api/pkg/metrics"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 )
 
-func AuthenticationMiddleware(jwtSecret []byte) gin.HandlerFunc {
+// PrincipalKey menyimpan *domain.Principal yang terautentikasi di context Gin
+const PrincipalKey = "principal"
+
+// AuthenticationMiddleware mengautentikasi request dari cookie access_token
+// (JWT) atau header Authorization: Bearer berisi JWT, personal access token
+// maupun API key service account. tokens dan services boleh nil untuk
+// menonaktifkan personal access token dan API key.
+func AuthenticationMiddleware(jwtSecret []byte, tokens usecase.AccessTokenUsecase, services usecase.ServiceAccountUsecase) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Ambil access_token dari cookie
+		bearer := bearerToken(c)
+		if domain.IsAccessToken(bearer) {
+			authenticateAccessToken(c, tokens, bearer)
+			return
+		}
+		if domain.IsAPIKey(bearer) {
+			authenticateAPIKey(c, services, bearer)
+			return
+		}
+
+		// Ambil access_token dari cookie, atau JWT dari header Authorization
 		cookie, err := c.Cookie("access_token")
-		fmt.Println("Access Token:", cookie)
 		if err != nil {
-			// Jika tidak ada cookie access_token, lanjutkan
+			cookie = bearer
+		}
+		if cookie == "" {
+			// Jika tidak ada access token, lanjutkan
 			c.Next()
 			return
 		}
@@ -33,6 +55,7 @@ func AuthenticationMiddleware(jwtSecret []byte) gin.HandlerFunc {
 
 		if err != nil || !token.Valid {
 			// Jika token tidak valid atau expired, lanjutkan tanpa mengatur user
+			metrics.AuthTokenValidationFailures.WithLabelValues(tokenFailureReason(err)).Inc()
 			c.Next()
 			return
 		}
@@ -42,7 +65,8 @@ func AuthenticationMiddleware(jwtSecret []byte) gin.HandlerFunc {
 			// Ambil userId dari claims
 			userIDStr, ok := claims["userId"].(string)
 			if !ok {
-				c.JSON(http.StatusUnauthorized, gin.H{"message": "Unauthorized"})
+				metrics.AuthTokenValidationFailures.WithLabelValues("invalid_claims").Inc()
+				c.Error(domain.ErrUnauthorized)
 				c.Abort()
 				return
 			}
@@ -50,7 +74,8 @@ func AuthenticationMiddleware(jwtSecret []byte) gin.HandlerFunc {
 			// Parse userId menjadi uuid.UUID
 			userID, err := uuid.Parse(userIDStr)
 			if err != nil {
-				c.JSON(http.StatusUnauthorized, gin.H{"message": "Invalid userId format"})
+				metrics.AuthTokenValidationFailures.WithLabelValues("invalid_claims").Inc()
+				c.Error(domain.ErrUnauthorized.Wrap(err))
 				c.Abort()
 				return
 			}
@@ -58,30 +83,179 @@ func AuthenticationMiddleware(jwtSecret []byte) gin.HandlerFunc {
 			// Ambil role dari claims
 			role, okRole := claims["role"].(string)
 			if !okRole {
-				c.JSON(http.StatusUnauthorized, gin.H{"message": "Unauthorized"})
+				metrics.AuthTokenValidationFailures.WithLabelValues("invalid_claims").Inc()
+				c.Error(domain.ErrUnauthorized)
 				c.Abort()
 				return
 			}
 
-			// Set user info ke context Gin
-			user := &domain.User{
-				ID:   userID,
-				Role: role,
+			// Locale bersifat opsional (token lama belum memilikinya)
+			locale, _ := claims["locale"].(string)
+
+			// Set principal ke context Gin
+			setPrincipal(c, &domain.Principal{
+				Kind:   domain.PrincipalUser,
+				ID:     userID,
+				Role:   role,
+				Locale: locale,
+			})
+
+			// Tenant aktif bersifat opsional (user tanpa organization atau
+			// token lama tidak memilikinya)
+			if orgStr, ok := claims["org"].(string); ok {
+				orgID, err := uuid.Parse(orgStr)
+				if err != nil {
+					metrics.AuthTokenValidationFailures.WithLabelValues("invalid_claims").Inc()
+					c.Error(domain.ErrUnauthorized.Wrap(err))
+					c.Abort()
+					return
+				}
+				orgRole, _ := claims["org_role"].(string)
+				c.Set(TokenTenantKey, domain.Tenant{ID: orgID, Role: orgRole})
 			}
-			c.Set("user", user)
 		}
 
 		c.Next()
 	}
 }
 
+// bearerToken mengambil credential dari header Authorization: Bearer
+func bearerToken(c *gin.Context) string {
+	scheme, credential, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(credential)
+}
+
+// authenticateAccessToken memeriksa personal access token. Berbeda dengan
+// JWT, token yang tidak valid langsung ditolak 401 karena client script
+// tidak punya alur refresh.
+func authenticateAccessToken(c *gin.Context, tokens usecase.AccessTokenUsecase, credential string) {
+	if tokens == nil {
+		metrics.AuthTokenValidationFailures.WithLabelValues("access_token").Inc()
+		c.Error(domain.ErrUnauthorized)
+		c.Abort()
+		return
+	}
+
+	user, token, err := tokens.Authenticate(c.Request.Context(), credential, c.ClientIP())
+	if err != nil {
+		if domain.KindOf(err) == domain.KindUnauthorized {
+			metrics.AuthTokenValidationFailures.WithLabelValues("access_token").Inc()
+		}
+		c.Error(err)
+		c.Abort()
+		return
+	}
+
+	setPrincipal(c, &domain.Principal{
+		Kind:         domain.PrincipalUser,
+		ID:           user.ID,
+		Role:         user.Role,
+		Locale:       user.Locale,
+		Scopes:       token.Scopes,
+		CredentialID: &token.ID,
+	})
+
+	ctx := c.Request.Context()
+	ctx = logger.WithContext(ctx, logger.FromContext(ctx).With("access_token_id", token.ID.String()))
+	c.Request = c.Request.WithContext(ctx)
+
+	c.Next()
+}
+
+// authenticateAPIKey memeriksa API key service account. Seperti personal
+// access token, key yang tidak valid atau dipakai dari IP di luar allowlist
+// langsung ditolak.
+func authenticateAPIKey(c *gin.Context, services usecase.ServiceAccountUsecase, credential string) {
+	if services == nil {
+		metrics.AuthTokenValidationFailures.WithLabelValues("api_key").Inc()
+		c.Error(domain.ErrUnauthorized)
+		c.Abort()
+		return
+	}
+
+	account, key, err := services.Authenticate(c.Request.Context(), credential, c.ClientIP())
+	if err != nil {
+		if kind := domain.KindOf(err); kind == domain.KindUnauthorized || kind == domain.KindForbidden {
+			metrics.AuthTokenValidationFailures.WithLabelValues("api_key").Inc()
+		}
+		c.Error(err)
+		c.Abort()
+		return
+	}
+
+	setPrincipal(c, &domain.Principal{
+		Kind:         domain.PrincipalService,
+		ID:           account.ID,
+		Role:         account.Role,
+		Scopes:       account.Scopes,
+		CredentialID: &key.ID,
+	})
+
+	ctx := c.Request.Context()
+	ctx = logger.WithContext(ctx, logger.FromContext(ctx).With("api_key_id", key.ID.String()))
+	c.Request = c.Request.WithContext(ctx)
+
+	c.Next()
+}
+
+// setPrincipal menyimpan principal terautentikasi ke context Gin dan logger
+func setPrincipal(c *gin.Context, principal *domain.Principal) {
+	c.Set(PrincipalKey, principal)
+
+	// Tambahkan user_id atau service_account_id ke logger per-request
+	idKey := "user_id"
+	if principal.IsService() {
+		idKey = "service_account_id"
+	}
+	ctx := c.Request.Context()
+	ctx = logger.WithContext(ctx, logger.FromContext(ctx).With(idKey, principal.ID.String()))
+	c.Request = c.Request.WithContext(ctx)
+
+	// Bahasa pilihan user menimpa hasil negosiasi Accept-Language
+	if i18n.IsSupported(principal.Locale) {
+		setLocale(c, principal.Locale)
+	}
+}
+
+// CurrentPrincipal mengambil principal yang sudah diautentikasi dari context
+func CurrentPrincipal(c *gin.Context) (*domain.Principal, bool) {
+	principal, ok := c.Get(PrincipalKey)
+	if !ok {
+		return nil, false
+	}
+	p, ok := principal.(*domain.Principal)
+	return p, ok && p != nil
+}
+
+// tokenFailureReason mengelompokkan error validasi JWT untuk label metrik
+func tokenFailureReason(err error) string {
+	var ve *jwt.ValidationError
+	if !errors.As(err, &ve) {
+		return "invalid"
+	}
+	switch {
+	case ve.Errors&jwt.ValidationErrorMalformed != 0:
+		return "malformed"
+	case ve.Errors&jwt.ValidationErrorExpired != 0:
+		return "expired"
+	case ve.Errors&jwt.ValidationErrorNotValidYet != 0:
+		return "not_valid_yet"
+	case ve.Errors&(jwt.ValidationErrorSignatureInvalid|jwt.ValidationErrorUnverifiable) != 0:
+		return "signature"
+	default:
+		return "invalid"
+	}
+}
+
 func RequireCredentials() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Periksa apakah user sudah ada di konteks
-		user, exists := c.Get("user")
-		if !exists || user == nil {
-			// Jika tidak ada user, kirim response Unauthorized
-			c.JSON(http.StatusUnauthorized, gin.H{"message": "Unauthorized"})
+		// Periksa apakah principal sudah ada di konteks
+		if _, ok := CurrentPrincipal(c); !ok {
+			// Jika tidak ada principal, kirim response Unauthorized
+			c.Error(domain.ErrUnauthorized)
 			c.Abort()
 			return
 		}
@@ -93,19 +267,19 @@ func RequireCredentials() gin.HandlerFunc {
 
 func RequireAdmin() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Periksa apakah ada user di konteks
-		user, exists := c.Get("user")
-		if !exists || user == nil {
-			// Jika user tidak ada, kirim response Unauthorized
-			c.JSON(http.StatusUnauthorized, gin.H{"message": "Unauthorized"})
+		// Periksa apakah ada principal di konteks
+		principal, ok := CurrentPrincipal(c)
+		if !ok {
+			// Jika principal tidak ada, kirim response Unauthorized
+			c.Error(domain.ErrUnauthorized)
 			c.Abort()
 			return
 		}
 
-		// Cek apakah user memiliki role 'admin'
-		if u, ok := user.(*domain.User); ok && u.Role != "admin" {
-			// Jika bukan admin, kirim response Unauthorized
-			c.JSON(http.StatusUnauthorized, gin.H{"message": "Unauthorized - Admin access required"})
+		// Cek apakah user atau service account memiliki role 'admin'
+		if !principal.IsAdmin() {
+			// Jika bukan admin, kirim response Forbidden
+			c.Error(domain.ErrAdminRequired)
 			c.Abort()
 			return
 		}
@@ -113,4 +287,30 @@ func RequireAdmin() gin.HandlerFunc {
 		// Lanjutkan request jika user memiliki akses admin
 		c.Next()
 	}
+}
+
+// RequireScope meneruskan request sesi login, atau personal access token
+// dan API key yang memiliki scope tersebut
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if p, ok := CurrentPrincipal(c); ok && !p.HasScope(scope) {
+			c.Error(domain.ErrInsufficientScope)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireSession menolak personal access token dan API key, untuk aksi yang
+// tidak boleh dilakukan script (misalnya membuat token baru)
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if p, ok := CurrentPrincipal(c); ok && !p.Session() {
+			c.Error(domain.ErrSessionRequired)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
 }
\ No newline at end of file
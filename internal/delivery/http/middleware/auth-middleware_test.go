@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+var testJWTSecret = []byte("test-secret")
+
+// staticTokens menerima satu personal access token dengan scope tertentu
+type staticTokens struct {
+	usecase.AccessTokenUsecase
+
+	token  string
+	scopes []string
+}
+
+func (s *staticTokens) Authenticate(_ context.Context, token, _ string) (*domain.User, *domain.PersonalAccessToken, error) {
+	if token != s.token {
+		return nil, nil, domain.ErrUnauthorized
+	}
+	user := &domain.User{ID: uuid.New(), Role: "user"}
+	return user, &domain.PersonalAccessToken{ID: uuid.New(), UserID: user.ID, Scopes: s.scopes}, nil
+}
+
+// staticServices menerima satu API key dengan scope tertentu
+type staticServices struct {
+	usecase.ServiceAccountUsecase
+
+	key    string
+	scopes []string
+}
+
+func (s *staticServices) Authenticate(_ context.Context, key, _ string) (*domain.ServiceAccount, *domain.APIKey, error) {
+	if key != s.key {
+		return nil, nil, domain.ErrUnauthorized
+	}
+	account := &domain.ServiceAccount{ID: uuid.New(), Role: "user", Scopes: s.scopes}
+	return account, &domain.APIKey{ID: uuid.New(), ServiceAccountID: account.ID}, nil
+}
+
+func sessionJWT(t *testing.T) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"userId": uuid.NewString(),
+		"role":   "user",
+		"exp":    time.Now().Add(time.Minute).Unix(),
+	}).SignedString(testJWTSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestRequireScopeAndSession(t *testing.T) {
+	const (
+		readToken  = domain.AccessTokenPrefix + "read"
+		writeToken = domain.AccessTokenPrefix + "write"
+		readKey    = domain.APIKeyPrefix + "read_secret"
+	)
+	session := sessionJWT(t)
+
+	// Token dan key dengan credential berbeda memakai fake terpisah
+	newEngine := func(tokenScopes, keyScopes []string, token, key string) *gin.Engine {
+		engine := gin.New()
+		engine.Use(ErrorHandler(), AuthenticationMiddleware(testJWTSecret,
+			&staticTokens{token: token, scopes: tokenScopes},
+			&staticServices{key: key, scopes: keyScopes}))
+		ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+		engine.GET("/scoped", RequireScope(domain.ScopeProfileWrite), ok)
+		engine.GET("/session", RequireSession(), ok)
+		engine.GET("/both", RequireCredentials(), RequireSession(), RequireScope(domain.ScopeProfileWrite), ok)
+		return engine
+	}
+
+	tests := []struct {
+		name        string
+		engine      *gin.Engine
+		credential  string
+		wantScoped  int
+		wantSession int
+		wantBoth    int
+	}{
+		{"session", newEngine(nil, nil, readToken, readKey), "Bearer " + session,
+			http.StatusNoContent, http.StatusNoContent, http.StatusNoContent},
+		{"access token with scope", newEngine([]string{domain.ScopeProfileWrite}, nil, writeToken, readKey), "Bearer " + writeToken,
+			http.StatusNoContent, http.StatusForbidden, http.StatusForbidden},
+		{"access token without scope", newEngine([]string{domain.ScopeProfileRead}, nil, readToken, readKey), "Bearer " + readToken,
+			http.StatusForbidden, http.StatusForbidden, http.StatusForbidden},
+		{"api key with scope", newEngine(nil, []string{domain.ScopeProfileWrite}, readToken, readKey), "Bearer " + readKey,
+			http.StatusNoContent, http.StatusForbidden, http.StatusForbidden},
+		{"api key without scope", newEngine(nil, []string{domain.ScopeProfileRead}, readToken, readKey), "Bearer " + readKey,
+			http.StatusForbidden, http.StatusForbidden, http.StatusForbidden},
+		// Tanpa principal keputusan diserahkan ke RequireCredentials
+		{"anonymous", newEngine(nil, nil, readToken, readKey), "",
+			http.StatusNoContent, http.StatusNoContent, http.StatusUnauthorized},
+		{"unknown access token", newEngine(nil, nil, readToken, readKey), "Bearer " + domain.AccessTokenPrefix + "other",
+			http.StatusUnauthorized, http.StatusUnauthorized, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for path, want := range map[string]int{"/scoped": tt.wantScoped, "/session": tt.wantSession, "/both": tt.wantBoth} {
+				req := httptest.NewRequest(http.MethodGet, path, nil)
+				if tt.credential != "" {
+					req.Header.Set("Authorization", tt.credential)
+				}
+				w := httptest.NewRecorder()
+				tt.engine.ServeHTTP(w, req)
+				if w.Code != want {
+					t.Errorf("%s: status = %d, want %d: %s", path, w.Code, want, w.Body)
+				}
+			}
+		})
+	}
+}
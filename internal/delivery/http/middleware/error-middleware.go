@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/pkg/i18n"
+	"github.com/Hilmarch27/gin-api/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ProblemContentType = "application/problem+json"
+	problemTypeBase    = "/problems/"
+)
+
+// Problem adalah body error sesuai RFC 7807 (application/problem+json)
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+var (
+	errRouteNotFound = domain.NewError(domain.KindNotFound, "route_not_found", "the requested resource does not exist")
+	errTimeout       = &domain.Error{Code: "timeout", Message: "request timed out"}
+	errInternal      = &domain.Error{Code: "internal_error", Message: "internal server error"}
+)
+
+// ErrorHandler merender error yang ditambahkan handler lewat c.Error()
+// menjadi response problem+json dengan status code yang konsisten. Detail
+// internal (pesan database dll) hanya di-log, tidak dikirim ke client.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		renderProblem(c, c.Errors.Last().Err)
+	}
+}
+
+// Recovery mengubah panic menjadi response 500 problem+json
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered any) {
+		renderProblem(c, fmt.Errorf("panic: %v", recovered))
+		c.Abort()
+	})
+}
+
+// NoRoute dipasang sebagai handler route yang tidak terdaftar
+func NoRoute(c *gin.Context) {
+	c.Error(errRouteNotFound)
+}
+
+func renderProblem(c *gin.Context, err error) {
+	problem := newProblem(i18n.FromContext(c.Request.Context()), err)
+	problem.Instance = c.Request.URL.Path
+	problem.RequestID = c.GetString(RequestIDKey)
+
+	if problem.Status >= http.StatusInternalServerError {
+		logger.FromContext(c.Request.Context()).Error("request failed", "error", err.Error())
+	}
+
+	c.Header("Content-Type", ProblemContentType)
+	c.JSON(problem.Status, problem)
+}
+
+func newProblem(locale string, err error) *Problem {
+	status := http.StatusInternalServerError
+	de := errInternal
+
+	var target *domain.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		status, de = http.StatusGatewayTimeout, errTimeout
+	case errors.As(err, &target) && target.Kind != domain.KindInternal:
+		status, de = statusForKind(target.Kind), target
+	}
+
+	// Detail diterjemahkan berdasarkan kode error, fallback ke pesan asli
+	detail, ok := i18n.Lookup(locale, de.Code)
+	if !ok {
+		detail = de.Message
+	}
+
+	problem := &Problem{
+		Type:   problemTypeBase + de.Code,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   de.Code,
+	}
+	if de.Kind == domain.KindValidation || de.Kind == domain.KindForbidden {
+		problem.Errors = fieldErrors(locale, err)
+	}
+	return problem
+}
+
+func statusForKind(kind domain.ErrorKind) int {
+	switch kind {
+	case domain.KindNotFound:
+		return http.StatusNotFound
+	case domain.KindConflict:
+		return http.StatusConflict
+	case domain.KindValidation:
+		return http.StatusBadRequest
+	case domain.KindUnauthorized:
+		return http.StatusUnauthorized
+	case domain.KindForbidden:
+		return http.StatusForbidden
+	case domain.KindRateLimited:
+		return http.StatusTooManyRequests
+	case domain.KindTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case domain.KindUnsupportedMedia:
+		return http.StatusUnsupportedMediaType
+	case domain.KindPreconditionFailed:
+		return http.StatusPreconditionFailed
+	case domain.KindPreconditionRequired:
+		return http.StatusPreconditionRequired
+	case domain.KindUnprocessable:
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
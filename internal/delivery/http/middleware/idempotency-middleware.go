@@ -0,0 +1,206 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/usecase"
+	"github.com/Hilmarch27/gin-api/pkg/logger"
+	"github.com/Hilmarch27/gin-api/pkg/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	maxIdempotentBodyBytes    = 1 << 20
+	idempotencyRetryAfterSecs = 1
+	idempotencyAnonymousScope = "anonymous"
+)
+
+// idempotentMethods adalah method yang bisa diulang dengan aman lewat
+// Idempotency-Key. PUT dan GET sudah idempotent secara definisi.
+var idempotentMethods = map[string]bool{
+	http.MethodPost:   true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// replayedHeaders adalah header response yang disimpan dan di-replay
+var replayedHeaders = []string{"Content-Type", "Content-Language", "ETag", "Location", "Deprecation", "Sunset", "Link"}
+
+// Idempotency menyimpan response request POST/PATCH/DELETE yang membawa
+// header Idempotency-Key lalu me-replay response tersebut ketika client
+// mengulang request yang sama. Key yang dipakai ulang dengan body berbeda
+// ditolak 422, dan duplikat yang masih diproses ditolak 409. Harus dipasang
+// setelah AuthenticationMiddleware agar key terpisah per user.
+func Idempotency(iu usecase.IdempotencyUsecase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !idempotentMethods[c.Request.Method] || c.FullPath() == "" {
+			c.Next()
+			return
+		}
+		if !validIdempotencyKey(key) {
+			c.Error(domain.ErrIdempotencyKey)
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxIdempotentBodyBytes+1))
+		if err != nil {
+			c.Error(domain.ErrInvalidInput.Wrap(err))
+			c.Abort()
+			return
+		}
+		if len(body) > maxIdempotentBodyBytes {
+			c.Error(domain.ErrRequestTooLarge)
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		record := &domain.IdempotencyRecord{
+			Scope:       idempotencyScope(c),
+			Key:         key,
+			Method:      c.Request.Method,
+			Path:        c.Request.URL.Path,
+			Fingerprint: fingerprint(c.Request.Method, c.Request.URL.RequestURI(), body),
+		}
+
+		stored, err := iu.Begin(c.Request.Context(), record)
+		switch {
+		case errors.Is(err, domain.ErrIdempotencyReused):
+			metrics.IdempotencyRequests.WithLabelValues("reused").Inc()
+			c.Error(err)
+			c.Abort()
+			return
+		case errors.Is(err, domain.ErrIdempotencyInFlight):
+			metrics.IdempotencyRequests.WithLabelValues("in_flight").Inc()
+			c.Header("Retry-After", strconv.Itoa(idempotencyRetryAfterSecs))
+			c.Error(err)
+			c.Abort()
+			return
+		case err != nil:
+			c.Error(err)
+			c.Abort()
+			return
+		case stored != nil:
+			metrics.IdempotencyRequests.WithLabelValues("replayed").Inc()
+			replay(c, stored)
+			return
+		}
+		metrics.IdempotencyRequests.WithLabelValues("new").Inc()
+
+		// Simpan/lepas record tetap dijalankan meskipun request timeout
+		// atau client sudah memutus koneksi
+		storeCtx := context.WithoutCancel(c.Request.Context())
+		log := logger.FromContext(storeCtx)
+
+		writer := &capturingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		finished := false
+		defer func() {
+			// Panic: lepas key agar client bisa mencoba lagi
+			if !finished {
+				if err := iu.Release(storeCtx, record.Scope, record.Key); err != nil {
+					log.Error("failed to release idempotency key", "error", err)
+				}
+			}
+		}()
+
+		c.Next()
+		finished = true
+
+		// Hanya response sukses yang disimpan. Error server dan error yang
+		// belum dirender boleh dicoba ulang, dan response yang memasang
+		// cookie tidak di-replay agar kredensial tidak tersimpan.
+		status := writer.Status()
+		if len(c.Errors) > 0 || status >= http.StatusInternalServerError || writer.Header().Get("Set-Cookie") != "" {
+			if err := iu.Release(storeCtx, record.Scope, record.Key); err != nil {
+				log.Error("failed to release idempotency key", "error", err)
+			}
+			return
+		}
+
+		record.StatusCode = status
+		record.Body = writer.body.Bytes()
+		record.Headers = domain.IdempotencyHeaders{}
+		for _, name := range replayedHeaders {
+			if v := writer.Header().Get(name); v != "" {
+				record.Headers[name] = v
+			}
+		}
+		if err := iu.Complete(storeCtx, record); err != nil {
+			log.Error("failed to store idempotent response", "error", err)
+		}
+	}
+}
+
+func replay(c *gin.Context, record *domain.IdempotencyRecord) {
+	for name, value := range record.Headers {
+		c.Header(name, value)
+	}
+	c.Header(IdempotentReplayedHeader, "true")
+	c.Status(record.StatusCode)
+	if len(record.Body) > 0 {
+		c.Writer.Write(record.Body)
+	}
+	c.Abort()
+}
+
+// idempotencyScope memisahkan key per principal. Request anonim dipisah
+// per IP client (di-hash agar tidak tersimpan apa adanya), sehingga satu
+// client tidak bisa me-replay response milik client lain dengan menebak key.
+func idempotencyScope(c *gin.Context) string {
+	if p, ok := CurrentPrincipal(c); ok {
+		return p.ID.String()
+	}
+	sum := sha256.Sum256([]byte(c.ClientIP()))
+	return idempotencyAnonymousScope + ":" + hex.EncodeToString(sum[:16])
+}
+
+// validIdempotencyKey menerima 1-255 karakter ASCII yang dapat dicetak
+func validIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func fingerprint(method, uri string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + "\n" + uri + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// capturingWriter menyalin body response agar bisa disimpan
+type capturingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *capturingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *capturingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,208 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/repository"
+	"github.com/Hilmarch27/gin-api/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// memIdempotency meniru idempotencyRepository di memori (tanpa expiry)
+type memIdempotency struct {
+	repository.IdempotencyRepository
+
+	mu      sync.Mutex
+	records map[string]domain.IdempotencyRecord
+}
+
+func (r *memIdempotency) Begin(_ context.Context, record *domain.IdempotencyRecord, _ time.Time) (*domain.IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.records[record.Scope+"|"+record.Key]
+	switch {
+	case !ok:
+		r.records[record.Scope+"|"+record.Key] = *record
+		return nil, nil
+	case existing.Fingerprint != record.Fingerprint:
+		return nil, domain.ErrIdempotencyReused
+	case existing.State == domain.IdempotencyInProgress:
+		return nil, domain.ErrIdempotencyInFlight
+	default:
+		return &existing, nil
+	}
+}
+
+func (r *memIdempotency) Complete(_ context.Context, record *domain.IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	completed := *record
+	completed.State = domain.IdempotencyCompleted
+	r.records[record.Scope+"|"+record.Key] = completed
+	return nil
+}
+
+func (r *memIdempotency) Release(_ context.Context, scope, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.records[scope+"|"+key].State == domain.IdempotencyInProgress {
+		delete(r.records, scope+"|"+key)
+	}
+	return nil
+}
+
+type idempotentRequest struct {
+	path       string
+	key        string
+	body       string
+	remoteAddr string
+}
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	first := idempotentRequest{path: "/items", key: "key-1", body: `{"name":"a"}`, remoteAddr: "192.0.2.1:1000"}
+	with := func(change func(r *idempotentRequest)) idempotentRequest {
+		r := first
+		change(&r)
+		return r
+	}
+
+	tests := []struct {
+		name     string
+		retry    idempotentRequest
+		want     int
+		replayed bool
+		calls    int
+	}{
+		{"same request replays", first, http.StatusCreated, true, 1},
+		{"different body", with(func(r *idempotentRequest) { r.body = `{"name":"b"}` }), http.StatusUnprocessableEntity, false, 1},
+		{"different path", with(func(r *idempotentRequest) { r.path = "/items/other" }), http.StatusUnprocessableEntity, false, 1},
+		{"different key", with(func(r *idempotentRequest) { r.key = "key-2" }), http.StatusCreated, false, 2},
+		{"different client", with(func(r *idempotentRequest) { r.remoteAddr = "198.51.100.1:1000" }), http.StatusCreated, false, 2},
+		{"no key", with(func(r *idempotentRequest) { r.key = "" }), http.StatusCreated, false, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			engine := gin.New()
+			engine.Use(ErrorHandler(), Idempotency(usecase.NewIdempotencyUsecase(&memIdempotency{records: map[string]domain.IdempotencyRecord{}}, time.Hour)))
+			create := func(c *gin.Context) {
+				calls++
+				c.Header("Location", c.Request.URL.Path+"/1")
+				c.JSON(http.StatusCreated, gin.H{"call": calls})
+			}
+			engine.POST("/items", create)
+			engine.POST("/items/other", create)
+
+			serve := func(r idempotentRequest) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodPost, r.path, strings.NewReader(r.body))
+				req.RemoteAddr = r.remoteAddr
+				req.Header.Set("Content-Type", gin.MIMEJSON)
+				if r.key != "" {
+					req.Header.Set(IdempotencyKeyHeader, r.key)
+				}
+				w := httptest.NewRecorder()
+				engine.ServeHTTP(w, req)
+				return w
+			}
+
+			original := serve(first)
+			if original.Code != http.StatusCreated {
+				t.Fatalf("first request: status = %d", original.Code)
+			}
+			w := serve(tt.retry)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if calls != tt.calls {
+				t.Errorf("handler ran %d times, want %d", calls, tt.calls)
+			}
+			if replayed := w.Header().Get(IdempotentReplayedHeader) == "true"; replayed != tt.replayed {
+				t.Errorf("replayed = %v, want %v", replayed, tt.replayed)
+			}
+			if tt.replayed {
+				if w.Body.String() != original.Body.String() || w.Header().Get("Location") != original.Header().Get("Location") {
+					t.Errorf("replay = %q (Location %q), want %q (Location %q)",
+						w.Body, w.Header().Get("Location"), original.Body, original.Header().Get("Location"))
+				}
+			}
+		})
+	}
+}
+
+func TestIdempotencyReleasesKeyAfterServerError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &memIdempotency{records: map[string]domain.IdempotencyRecord{}}
+
+	calls := 0
+	engine := gin.New()
+	engine.Use(ErrorHandler(), Idempotency(usecase.NewIdempotencyUsecase(repo, time.Hour)))
+	engine.POST("/items", func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			c.Error(errors.New("database down"))
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	for i, want := range []int{http.StatusInternalServerError, http.StatusNoContent, http.StatusNoContent} {
+		req := httptest.NewRequest(http.MethodPost, "/items", nil)
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Fatalf("request %d: status = %d, want %d", i+1, w.Code, want)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("handler ran %d times, want 2", calls)
+	}
+}
+
+func TestIdempotencyRejectsInFlightDuplicate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &memIdempotency{records: map[string]domain.IdempotencyRecord{}}
+
+	engine := gin.New()
+	engine.Use(ErrorHandler(), Idempotency(usecase.NewIdempotencyUsecase(repo, time.Hour)))
+	release := make(chan struct{})
+	started := make(chan struct{})
+	engine.POST("/items", func(c *gin.Context) {
+		close(started)
+		<-release
+		c.Status(http.StatusNoContent)
+	})
+
+	serve := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/items", nil)
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- serve() }()
+	<-started
+
+	w := serve()
+	if w.Code != http.StatusConflict || w.Header().Get("Retry-After") == "" {
+		t.Errorf("duplicate: status = %d, Retry-After = %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	close(release)
+	if w := <-done; w.Code != http.StatusNoContent {
+		t.Errorf("original: status = %d", w.Code)
+	}
+}
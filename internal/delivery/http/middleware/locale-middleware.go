@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"github.com/Hilmarch27/gin-api/pkg/i18n"
+	"github.com/gin-gonic/gin"
+)
+
+// Locale menentukan bahasa response dari header Accept-Language.
+// Dipasang sebelum AuthenticationMiddleware, yang akan menimpanya dengan
+// bahasa pilihan user jika request membawa token.
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		setLocale(c, i18n.Negotiate(c.GetHeader("Accept-Language")))
+		c.Next()
+	}
+}
+
+func setLocale(c *gin.Context, locale string) {
+	c.Header("Content-Language", locale)
+	c.Request = c.Request.WithContext(i18n.WithLocale(c.Request.Context(), locale))
+}
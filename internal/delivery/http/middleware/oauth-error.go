@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/pkg/i18n"
+	"github.com/gin-gonic/gin"
+)
+
+// OAuthError adalah body error endpoint OAuth (RFC 6749 bagian 5.2)
+type OAuthError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// OAuthErrors merender error endpoint token, revoke dan userinfo dalam
+// format OAuth ({"error": ...}) alih-alih problem+json, karena library
+// client OAuth hanya mengenali format tersebut. Error internal tetap
+// dirender ErrorHandler.
+func OAuthErrors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		err := c.Errors.Last().Err
+		code, ok := domain.OAuthErrorCode(err)
+		if !ok || domain.KindOf(err) == domain.KindInternal {
+			return
+		}
+
+		status := http.StatusBadRequest
+		switch code {
+		case "invalid_client":
+			status = http.StatusUnauthorized
+			c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		case "invalid_token":
+			status = http.StatusUnauthorized
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		case "insufficient_scope":
+			status = http.StatusForbidden
+			c.Header("WWW-Authenticate", `Bearer error="insufficient_scope"`)
+		}
+
+		// Deskripsi diterjemahkan berdasarkan kode error domain
+		var de *domain.Error
+		errors.As(err, &de)
+		description, ok := i18n.Lookup(i18n.FromContext(c.Request.Context()), de.Code)
+		if !ok {
+			description = de.Message
+		}
+
+		c.Header("Cache-Control", "no-store")
+		c.JSON(status, OAuthError{Error: code, ErrorDescription: description})
+	}
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestMeta menyimpan actor, IP, user agent dan request ID ke context
+// agar usecase bisa mencatatnya di audit log. Harus dipasang setelah
+// RequestID dan AuthenticationMiddleware.
+func RequestMeta() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		meta := domain.RequestMeta{
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			RequestID: c.GetString(RequestIDKey),
+		}
+		if p, ok := CurrentPrincipal(c); ok {
+			meta.ActorID = &p.ID
+			meta.ActorType = p.Kind
+		}
+
+		c.Request = c.Request.WithContext(domain.WithRequestMeta(c.Request.Context(), meta))
+		c.Next()
+	}
+}
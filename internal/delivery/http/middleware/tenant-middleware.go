@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/usecase"
+	"github.com/Hilmarch27/gin-api/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// TenantHeader berisi slug atau ID organization
+	TenantHeader = "X-Organization"
+
+	// TenantKey menyimpan domain.Tenant aktif di context Gin
+	TenantKey = "tenant"
+
+	// TokenTenantKey menyimpan tenant dari claim org di access token
+	TokenTenantKey = "token_tenant"
+
+	// TenantParam adalah nama parameter path organization
+	// (misalnya /api/organizations/:org)
+	TenantParam = "org"
+)
+
+// Tenant menentukan organization aktif untuk request dari parameter path,
+// header X-Organization dan subdomain (<slug>.<baseDomain>). Tanpa
+// ketiganya, tenant dari access token yang dipakai. Sumber yang menunjuk
+// organization berbeda ditolak, dan user yang bukan anggota ditolak 403.
+// Membership dicek ulang di setiap request.
+// Harus dipasang setelah AuthenticationMiddleware.
+func Tenant(ou usecase.OrganizationUsecase, baseDomain string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		var org *domain.Organization
+		for _, ref := range tenantRefs(c, baseDomain) {
+			resolved, err := ou.Resolve(ctx, ref)
+			if err != nil {
+				c.Error(err)
+				c.Abort()
+				return
+			}
+			if org != nil && org.ID != resolved.ID {
+				c.Error(domain.ErrTenantMismatch)
+				c.Abort()
+				return
+			}
+			org = resolved
+		}
+
+		fromToken, hasToken := c.Get(TokenTenantKey)
+		tokenTenant, _ := fromToken.(domain.Tenant)
+
+		if org == nil && !hasToken {
+			c.Next()
+			return
+		}
+		tenant := tokenTenant
+		if org != nil {
+			tenant = domain.Tenant{ID: org.ID}
+		}
+
+		// Role selalu dibaca dari membership, bukan dari claim token, agar
+		// anggota yang dikeluarkan atau diturunkan perannya langsung
+		// kehilangan aksesnya tanpa menunggu token kedaluwarsa
+		if p, ok := CurrentPrincipal(c); ok {
+			// Service account bukan anggota organization mana pun
+			if !p.IsUser() {
+				c.Error(domain.ErrNotMember)
+				c.Abort()
+				return
+			}
+			membership, err := ou.Membership(ctx, tenant.ID, p.ID)
+			switch {
+			case errors.Is(err, domain.ErrMemberNotFound) && org == nil:
+				// Tenant bawaan token sudah tidak berlaku; request tetap
+				// dilayani tanpa tenant dan refresh berikutnya memilih
+				// organization lain
+				c.Next()
+				return
+			case errors.Is(err, domain.ErrMemberNotFound):
+				c.Error(domain.ErrNotMember.Wrap(err))
+				c.Abort()
+				return
+			case err != nil:
+				c.Error(err)
+				c.Abort()
+				return
+			}
+			tenant.Role = membership.Role
+		}
+
+		c.Set(TenantKey, tenant)
+		ctx = domain.WithTenant(ctx, tenant)
+		ctx = logger.WithContext(ctx, logger.FromContext(ctx).With("organization_id", tenant.ID.String()))
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// tenantRefs mengumpulkan referensi organization dari path, header dan
+// subdomain
+func tenantRefs(c *gin.Context, baseDomain string) []string {
+	var refs []string
+	if ref := c.Param(TenantParam); ref != "" {
+		refs = append(refs, ref)
+	}
+	if ref := c.GetHeader(TenantHeader); ref != "" {
+		refs = append(refs, ref)
+	}
+	if ref := subdomain(c.Request.Host, baseDomain); ref != "" {
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// subdomain mengembalikan label slug dari host <slug>.<baseDomain>.
+// Subdomain sistem seperti www diabaikan.
+func subdomain(host, baseDomain string) string {
+	if baseDomain == "" {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+
+	label, ok := strings.CutSuffix(host, "."+strings.ToLower(baseDomain))
+	if !ok || strings.Contains(label, ".") || !domain.ValidSlug(label) {
+		return ""
+	}
+	return label
+}
+
+// RequireOrgAdmin hanya meneruskan request dari owner atau admin
+// organization aktif
+func RequireOrgAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenant, ok := c.Get(TenantKey)
+		if !ok {
+			c.Error(domain.ErrNotMember)
+			c.Abort()
+			return
+		}
+		if t, ok := tenant.(domain.Tenant); !ok || !t.CanManageMembers() {
+			c.Error(domain.ErrOrgAdminRequired)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// memOrgs menyimpan organization dan role anggotanya di memori
+type memOrgs struct {
+	usecase.OrganizationUsecase
+
+	orgs    map[string]*domain.Organization // per slug
+	members map[uuid.UUID]map[uuid.UUID]string
+	lookups int
+}
+
+func (o *memOrgs) Resolve(_ context.Context, ref string) (*domain.Organization, error) {
+	org, ok := o.orgs[ref]
+	if !ok {
+		return nil, domain.ErrOrganizationNotFound
+	}
+	return org, nil
+}
+
+func (o *memOrgs) Membership(_ context.Context, orgID, userID uuid.UUID) (*domain.Membership, error) {
+	o.lookups++
+	role, ok := o.members[orgID][userID]
+	if !ok {
+		return nil, domain.ErrMemberNotFound
+	}
+	return &domain.Membership{OrganizationID: orgID, UserID: userID, Role: role}, nil
+}
+
+type tenantTest struct {
+	orgs   *memOrgs
+	acme   *domain.Organization
+	globex *domain.Organization
+	user   uuid.UUID
+}
+
+func newTenantTest() *tenantTest {
+	acme := &domain.Organization{ID: uuid.New(), Slug: "acme"}
+	globex := &domain.Organization{ID: uuid.New(), Slug: "globex"}
+	user := uuid.New()
+	return &tenantTest{
+		orgs: &memOrgs{
+			orgs:    map[string]*domain.Organization{"acme": acme, "globex": globex},
+			members: map[uuid.UUID]map[uuid.UUID]string{acme.ID: {user: domain.OrgRoleAdmin}, globex.ID: {}},
+		},
+		acme:   acme,
+		globex: globex,
+		user:   user,
+	}
+}
+
+// serve menjalankan request dengan principal dan tenant token yang sudah
+// diautentikasi, lalu mengembalikan tenant yang dilihat handler
+func (tt *tenantTest) serve(principal *domain.Principal, tokenTenant *domain.Tenant, header string, handlers ...gin.HandlerFunc) (int, *domain.Tenant) {
+	var seen *domain.Tenant
+	engine := gin.New()
+	engine.Use(ErrorHandler(), func(c *gin.Context) {
+		if principal != nil {
+			setPrincipal(c, principal)
+		}
+		if tokenTenant != nil {
+			c.Set(TokenTenantKey, *tokenTenant)
+		}
+	}, Tenant(tt.orgs, ""))
+	handlers = append(handlers, func(c *gin.Context) {
+		if tenant, ok := domain.TenantFrom(c.Request.Context()); ok {
+			seen = &tenant
+		}
+		c.Status(http.StatusNoContent)
+	})
+	engine.GET("/", handlers...)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if header != "" {
+		req.Header.Set(TenantHeader, header)
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w.Code, seen
+}
+
+func TestTenantChecksMembership(t *testing.T) {
+	tt := newTenantTest()
+	user := &domain.Principal{Kind: domain.PrincipalUser, ID: tt.user}
+	service := &domain.Principal{Kind: domain.PrincipalService, ID: uuid.New()}
+	// Role di claim token tidak dipercaya
+	fromToken := &domain.Tenant{ID: tt.acme.ID, Role: domain.OrgRoleOwner}
+	staleToken := &domain.Tenant{ID: tt.globex.ID, Role: domain.OrgRoleOwner}
+
+	tests := []struct {
+		name        string
+		principal   *domain.Principal
+		tokenTenant *domain.Tenant
+		header      string
+		want        int
+		wantTenant  *domain.Tenant
+	}{
+		{"token tenant", user, fromToken, "", http.StatusNoContent, &domain.Tenant{ID: tt.acme.ID, Role: domain.OrgRoleAdmin}},
+		{"header", user, nil, "acme", http.StatusNoContent, &domain.Tenant{ID: tt.acme.ID, Role: domain.OrgRoleAdmin}},
+		{"header overrides token", user, staleToken, "acme", http.StatusNoContent, &domain.Tenant{ID: tt.acme.ID, Role: domain.OrgRoleAdmin}},
+		{"removed from token tenant", user, staleToken, "", http.StatusNoContent, nil},
+		{"header for other organization", user, nil, "globex", http.StatusForbidden, nil},
+		{"unknown organization", user, nil, "initech", http.StatusNotFound, nil},
+		{"service account", service, nil, "acme", http.StatusForbidden, nil},
+		{"anonymous", nil, nil, "", http.StatusNoContent, nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, tenant := tt.serve(tc.principal, tc.tokenTenant, tc.header)
+			if status != tc.want {
+				t.Fatalf("status = %d, want %d", status, tc.want)
+			}
+			switch {
+			case tc.wantTenant == nil && tenant != nil:
+				t.Errorf("tenant = %+v, want none", *tenant)
+			case tc.wantTenant != nil && (tenant == nil || *tenant != *tc.wantTenant):
+				t.Errorf("tenant = %+v, want %+v", tenant, *tc.wantTenant)
+			}
+		})
+	}
+}
+
+func TestTenantRechecksMembershipEveryRequest(t *testing.T) {
+	tt := newTenantTest()
+	user := &domain.Principal{Kind: domain.PrincipalUser, ID: tt.user}
+	token := &domain.Tenant{ID: tt.acme.ID, Role: domain.OrgRoleAdmin}
+
+	if status, _ := tt.serve(user, token, "", RequireOrgAdmin()); status != http.StatusNoContent {
+		t.Fatalf("admin: status = %d", status)
+	}
+
+	// Diturunkan menjadi member dengan access token yang sama
+	tt.orgs.members[tt.acme.ID][tt.user] = domain.OrgRoleMember
+	if status, _ := tt.serve(user, token, "", RequireOrgAdmin()); status != http.StatusForbidden {
+		t.Fatalf("demoted: status = %d, want %d", status, http.StatusForbidden)
+	}
+
+	// Dikeluarkan: akses ke organization lewat header langsung ditolak
+	delete(tt.orgs.members[tt.acme.ID], tt.user)
+	if status, _ := tt.serve(user, token, "acme"); status != http.StatusForbidden {
+		t.Fatalf("removed: status = %d, want %d", status, http.StatusForbidden)
+	}
+	if tt.orgs.lookups != 3 {
+		t.Errorf("membership looked up %d times, want once per request", tt.orgs.lookups)
+	}
+}
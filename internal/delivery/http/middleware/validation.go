@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/pkg/i18n"
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError menjelaskan satu field request yang gagal validasi
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// fieldErrors mengekstrak detail per field dari error binding
+func fieldErrors(locale string, err error) []FieldError {
+	var verrs validator.ValidationErrors
+	if errors.As(err, &verrs) {
+		out := make([]FieldError, 0, len(verrs))
+		for _, fe := range verrs {
+			out = append(out, FieldError{
+				Field:   fe.Field(),
+				Rule:    fe.Tag(),
+				Message: validationMessage(locale, fe.Field(), fe.Tag(), fe.Param(), fe.Kind()),
+			})
+		}
+		return out
+	}
+
+	var violations domain.FieldViolations
+	if errors.As(err, &violations) {
+		out := make([]FieldError, 0, len(violations))
+		for _, v := range violations {
+			out = append(out, FieldError{
+				Field:   v.Field,
+				Rule:    v.Rule,
+				Message: validationMessage(locale, v.Field, v.Rule, "", reflect.String),
+			})
+		}
+		return out
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: i18n.T(locale, "validation.type", typeErr.Field, typeErr.Type.String()),
+		}}
+	}
+
+	return nil
+}
+
+func validationMessage(locale, field, tag, param string, kind reflect.Kind) string {
+	switch tag {
+	case "min", "max":
+		if kind == reflect.Int || kind == reflect.Int64 {
+			return i18n.T(locale, "validation."+tag+"_value", field, param)
+		}
+		return i18n.T(locale, "validation."+tag, field, param)
+	case "oneof":
+		return i18n.T(locale, "validation.oneof", field, strings.ReplaceAll(param, " ", ", "))
+	}
+	if msg, ok := i18n.Lookup(locale, "validation."+tag, field); ok {
+		return msg
+	}
+	return i18n.T(locale, "validation.invalid", field)
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/pkg/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+const APIVersionKey = "api_version"
+
+// apiLinkPrefixKey menyimpan prefix untuk header Link: prefix versi
+// pengganti untuk versi lama, selain itu prefix versi itu sendiri
+const apiLinkPrefixKey = "api_link_prefix"
+
+// VersionInfo berisi informasi versi API untuk APIVersion middleware
+type VersionInfo struct {
+	Name       string
+	Prefix     string
+	Deprecated time.Time
+	Sunset     time.Time
+	Successor  string
+}
+
+// APIVersion mencatat pemakaian per versi API dan, untuk versi lama,
+// menambahkan header Deprecation (RFC 9745), Sunset (RFC 8594) dan Link
+// ke path yang sama di versi penggantinya
+func APIVersion(v VersionInfo) gin.HandlerFunc {
+	deprecated := !v.Deprecated.IsZero()
+
+	return func(c *gin.Context) {
+		c.Set(APIVersionKey, v.Name)
+		if v.Successor != "" {
+			c.Set(apiLinkPrefixKey, v.Successor)
+		} else {
+			c.Set(apiLinkPrefixKey, v.Prefix)
+		}
+		metrics.APIVersionRequests.WithLabelValues(v.Name, fmt.Sprint(deprecated)).Inc()
+
+		if deprecated {
+			c.Header("Deprecation", fmt.Sprintf("@%d", v.Deprecated.Unix()))
+		}
+		if !v.Sunset.IsZero() {
+			c.Header("Sunset", v.Sunset.UTC().Format(http.TimeFormat))
+		}
+		if v.Successor != "" {
+			path := v.Successor + strings.TrimPrefix(c.Request.URL.Path, v.Prefix)
+			c.Header("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, path))
+		}
+
+		c.Next()
+	}
+}
+
+// RouteDeprecation menandai satu route lama di dalam versi API yang masih
+// berlaku. Successor adalah path pengganti relatif terhadap prefix versi;
+// parameter :nama diisi dari path request.
+type RouteDeprecation struct {
+	Deprecated time.Time
+	Sunset     time.Time
+	Successor  string
+}
+
+// DeprecatedRoute menambahkan header Deprecation, Sunset dan Link ke route
+// pengganti, seperti APIVersion untuk seluruh versi. Sunset yang lebih
+// awal dari sunset versinya yang dipakai.
+func DeprecatedRoute(d RouteDeprecation) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		d.SetHeaders(c)
+		c.Next()
+	}
+}
+
+// SetHeaders memasang header DeprecatedRoute langsung dari handler, untuk
+// route yang penggantinya baru diketahui setelah request diperiksa
+func (d RouteDeprecation) SetHeaders(c *gin.Context) {
+	c.Header("Deprecation", fmt.Sprintf("@%d", d.Deprecated.Unix()))
+
+	sunset := d.Sunset
+	if current, err := http.ParseTime(c.Writer.Header().Get("Sunset")); err == nil && current.Before(sunset) {
+		sunset = current
+	}
+	c.Header("Sunset", sunset.UTC().Format(http.TimeFormat))
+
+	path := d.Successor
+	for _, p := range c.Params {
+		path = strings.ReplaceAll(path, ":"+p.Key, p.Value)
+	}
+	c.Header("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, c.GetString(apiLinkPrefixKey)+path))
+}
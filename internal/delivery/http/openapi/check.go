@@ -0,0 +1,55 @@
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var ginParam = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+// PathFromGin mengubah path gin (/users/:id) ke format OpenAPI (/users/{id})
+func PathFromGin(path string) string {
+	return ginParam.ReplaceAllString(path, "{$1}")
+}
+
+// Check memastikan setiap route di bawah prefix versi (misalnya /v1) punya
+// entri di dokumen, dan sebaliknya. Route di luar prefix (route lama tanpa
+// versi, /metrics, /docs) dilewati. Path di dokumen relatif terhadap prefix.
+func Check(doc *Document, routes gin.RoutesInfo, prefix string) error {
+	registered := map[string]bool{}
+	var missing []string
+	for _, route := range routes {
+		rel, ok := strings.CutPrefix(route.Path, prefix)
+		if !ok || !strings.HasPrefix(rel, "/") {
+			continue
+		}
+		path := PathFromGin(rel)
+		method := strings.ToLower(route.Method)
+		registered[method+" "+path] = true
+
+		item, ok := doc.Paths[path]
+		if !ok || (*item)[method] == nil {
+			missing = append(missing, route.Method+" "+route.Path)
+		}
+	}
+
+	var stale []string
+	for path, item := range doc.Paths {
+		for method := range *item {
+			if !registered[method+" "+path] {
+				stale = append(stale, strings.ToUpper(method)+" "+path)
+			}
+		}
+	}
+
+	if len(missing) == 0 && len(stale) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	sort.Strings(stale)
+	return fmt.Errorf("openapi: routes without spec entry: %v; spec entries without route: %v", missing, stale)
+}
@@ -0,0 +1,73 @@
+package openapi_test
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/Hilmarch27/gin-api/internal/delivery/http/handler"
+	"github.com/Hilmarch27/gin-api/internal/delivery/http/openapi"
+	"github.com/Hilmarch27/gin-api/internal/delivery/http/router"
+	"github.com/gin-gonic/gin"
+)
+
+const testPrefix = "/v1"
+
+// newEngine memasang semua route aplikasi dengan handler tanpa usecase;
+// handler tidak dipanggil, hanya route-nya yang diperiksa
+func newEngine(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	auth := handler.NewAuthHandler(nil)
+	invitations := handler.NewInvitationHandler(nil, nil)
+	sso := handler.NewSSOHandler(nil, nil)
+	passkeys := handler.NewPasskeyHandler(nil, nil)
+	oauth := handler.NewOAuthHandler(nil)
+
+	routes := []router.RouteGroup{
+		router.NewPublicRouter(auth, invitations, sso, passkeys, handler.NewMagicLinkHandler(nil, nil), "secret"),
+		router.NewApiRouter(auth, handler.NewAvatarHandler(nil, 1<<20), handler.NewAuditHandler(nil), handler.NewOrganizationHandler(nil, nil), invitations, handler.NewAccessTokenHandler(nil), handler.NewServiceAccountHandler(nil), oauth, sso, passkeys, "secret"),
+		router.NewOAuthRouter(oauth),
+	}
+
+	engine := gin.New()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	r := router.NewRouter(engine, []router.Version{{Name: "v1", Prefix: testPrefix, Routes: routes}}, nil, nil, nil, nil, "", []byte("secret"), 0, logger, "test")
+	if err := r.SetupRoutes(); err != nil {
+		t.Fatalf("SetupRoutes: %v", err)
+	}
+	return engine
+}
+
+func TestCheckCoversAllRoutes(t *testing.T) {
+	engine := newEngine(t)
+
+	if err := openapi.Check(openapi.Build(testPrefix), engine.Routes(), testPrefix); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckReportsUndocumentedRoute(t *testing.T) {
+	engine := newEngine(t)
+	engine.GET(testPrefix+"/api/undocumented/:id", func(c *gin.Context) {})
+
+	err := openapi.Check(openapi.Build(testPrefix), engine.Routes(), testPrefix)
+	if err == nil {
+		t.Fatal("expected an error for a route without spec entry")
+	}
+	if !strings.Contains(err.Error(), "GET "+testPrefix+"/api/undocumented/:id") {
+		t.Errorf("error does not name the undocumented route: %v", err)
+	}
+}
+
+func TestCheckReportsStaleSpecEntry(t *testing.T) {
+	err := openapi.Check(openapi.Build(testPrefix), gin.New().Routes(), testPrefix)
+	if err == nil {
+		t.Fatal("expected an error for spec entries without route")
+	}
+	if !strings.Contains(err.Error(), "POST /auth/login") {
+		t.Errorf("error does not name the stale spec entry: %v", err)
+	}
+}
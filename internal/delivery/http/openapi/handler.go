@@ -0,0 +1,24 @@
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed docs.html
+var docsHTML []byte
+
+// SpecHandler melayani dokumen OpenAPI sebagai JSON
+func SpecHandler(doc *Document) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, doc)
+	}
+}
+
+// DocsHandler melayani UI dokumentasi yang membaca /openapi.json.
+// UI dibundel di binary sehingga tidak butuh akses ke CDN.
+func DocsHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docsHTML)
+}
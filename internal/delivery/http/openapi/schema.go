@@ -0,0 +1,211 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/google/uuid"
+)
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	uuidType = reflect.TypeOf(uuid.UUID{})
+)
+
+// schemaRegistry membangun JSON Schema dari struct Go (tag json dan
+// binding) dan menyimpan struct bernama di components/schemas
+type schemaRegistry struct {
+	schemas map[string]*Schema
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{schemas: map[string]*Schema{}}
+}
+
+// Ref mendaftarkan tipe v dan mengembalikan schema $ref ke tipe tersebut
+func (r *schemaRegistry) Ref(v any) *Schema {
+	return r.schemaFor(reflect.TypeOf(v))
+}
+
+func (r *schemaRegistry) schemaFor(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case uuidType:
+		return &Schema{Type: "string", Format: "uuid"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: r.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.structSchema(t)
+		}
+		if _, ok := r.schemas[t.Name()]; !ok {
+			// Daftarkan placeholder dulu agar tipe rekursif tidak loop
+			r.schemas[t.Name()] = &Schema{}
+			*r.schemas[t.Name()] = *r.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + t.Name()}
+	default:
+		// interface{} dan tipe lain: nilai JSON apa saja
+		return &Schema{}
+	}
+}
+
+func (r *schemaRegistry) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := jsonName(field)
+		if name == "-" {
+			continue
+		}
+
+		// Field embedded tanpa nama JSON digabung ke parent
+		if field.Anonymous && name == "" {
+			embedded := r.structSchema(indirect(field.Type))
+			for k, v := range embedded.Properties {
+				schema.Properties[k] = v
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := r.schemaFor(field.Type)
+		if applyBinding(prop, field.Tag.Get("binding"), indirect(field.Type).Kind()) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = prop
+	}
+
+	return schema
+}
+
+// queryParameters membangun parameter query dari struct dengan tag form
+func (r *schemaRegistry) queryParameters(v any) []Parameter {
+	t := indirect(reflect.TypeOf(v))
+
+	var params []Parameter
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.SplitN(field.Tag.Get("form"), ",", 2)[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		schema := r.schemaFor(field.Type)
+		required := applyBinding(schema, field.Tag.Get("binding"), indirect(field.Type).Kind())
+		params = append(params, Parameter{
+			Name:     name,
+			In:       "query",
+			Required: required,
+			Schema:   schema,
+		})
+	}
+	return params
+}
+
+// applyBinding menerjemahkan tag binding validator ke constraint JSON
+// Schema dan melaporkan apakah field wajib diisi
+func applyBinding(schema *Schema, tag string, kind reflect.Kind) (required bool) {
+	if tag == "" || schema.Ref != "" {
+		return strings.Contains(tag, "required")
+	}
+
+	numeric := kind >= reflect.Int && kind <= reflect.Float64
+	array := kind == reflect.Slice || kind == reflect.Array
+	rules := strings.Split(tag, ",")
+	for i, rule := range rules {
+		key, param, _ := strings.Cut(rule, "=")
+		switch key {
+		case "dive":
+			// Aturan setelah dive berlaku untuk setiap elemen
+			if schema.Items != nil {
+				applyBinding(schema.Items, strings.Join(rules[i+1:], ","), reflect.Invalid)
+			}
+			return required
+		case "scope":
+			for _, v := range domain.AccessTokenScopes {
+				schema.Enum = append(schema.Enum, v)
+			}
+		case "required":
+			required = true
+		case "email":
+			schema.Format = "email"
+		case "uuid":
+			schema.Format = "uuid"
+		case "url", "uri":
+			schema.Format = "uri"
+		case "datetime":
+			schema.Format = "date-time"
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, v)
+			}
+		case "min", "max":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			length := int(n)
+			switch {
+			case numeric && key == "min":
+				schema.Minimum = &n
+			case numeric:
+				schema.Maximum = &n
+			case array && key == "min":
+				schema.MinItems = &length
+			case array:
+				schema.MaxItems = &length
+			case key == "min":
+				schema.MinLength = &length
+			default:
+				schema.MaxLength = &length
+			}
+		}
+	}
+	return required
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	return name
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,1257 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/Hilmarch27/gin-api/internal/delivery/http/handler"
+	"github.com/Hilmarch27/gin-api/internal/delivery/http/middleware"
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// MessageResponse adalah body sukses berisi pesan (tanpa data)
+type MessageResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// Build menyusun dokumen OpenAPI untuk seluruh route yang didaftarkan
+// PublicRouter dan ApiRouter di bawah prefix versi server (misalnya /v1).
+// Setiap route baru wajib ditambahkan di sini; Check akan gagal jika ada
+// route tanpa entri spec.
+func Build(server string) *Document {
+	b := newBuilder()
+	b.doc.Servers = []Server{{URL: server, Description: "Current API version"}}
+
+	// Auth (public)
+	b.op(http.MethodPost, "/auth/register", &Operation{
+		OperationID: "register",
+		Summary:     "Register a new user",
+		Tags:        []string{"auth"},
+		RequestBody: b.jsonBody(domain.RegisterRequest{}),
+		Responses: b.responses(
+			b.message(http.StatusCreated, "User registered"),
+			http.StatusBadRequest, http.StatusConflict,
+		),
+	})
+	b.op(http.MethodPost, "/auth/login", &Operation{
+		OperationID: "login",
+		Summary:     "Log in with email and password",
+		Description: "Sets the `access_token` and `refresh_token` HttpOnly cookies. The token's active organization is the one selected with `X-Organization` (or the subdomain), otherwise the user's first organization. After too many consecutive wrong passwords the account is locked for a while and returns 429 `account_locked`.",
+		Tags:        []string{"auth"},
+		Parameters:  []Parameter{tenantHeaderParam},
+		RequestBody: b.jsonBody(domain.LoginRequest{}),
+		Responses: b.responses(
+			withCookies(b.message(http.StatusOK, "Logged in")),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusTooManyRequests,
+		),
+	})
+	b.op(http.MethodPost, "/auth/refresh", &Operation{
+		OperationID: "refreshToken",
+		Summary:     "Rotate the access and refresh tokens",
+		Description: "Reads the `refresh_token` cookie and sets new token cookies.",
+		Tags:        []string{"auth"},
+		Security:    []map[string][]string{{"refreshCookie": {}}},
+		Responses: b.responses(
+			withCookies(b.message(http.StatusOK, "Tokens refreshed")),
+			http.StatusUnauthorized,
+		),
+	})
+
+	b.op(http.MethodPost, "/auth/invitations/preview", &Operation{
+		OperationID: "previewInvitation",
+		Summary:     "Show the organization and email of an invitation token",
+		Description: "`account_exists` tells whether accepting requires the existing account's password or sign-up details.",
+		Tags:        []string{"auth"},
+		RequestBody: b.jsonBody(domain.InvitationTokenRequest{}),
+		Responses: b.responses(
+			b.data(http.StatusOK, "Invitation", domain.InvitationPreview{}),
+			http.StatusBadRequest, http.StatusConflict,
+		),
+	})
+	b.op(http.MethodPost, "/auth/invitations/accept", &Operation{
+		OperationID: "acceptInvitation",
+		Summary:     "Accept an organization invitation",
+		Description: "A logged-in user accepts with the token only; the account email must match the invitation. Without a session, `password` of the existing account with the invited email is required, or `name` and `password` to create that account. Sets token cookies with the organization as the active tenant.",
+		Tags:        []string{"auth"},
+		Security:    []map[string][]string{{}, {"cookieAuth": {}}},
+		RequestBody: b.jsonBody(domain.AcceptInvitationRequest{}),
+		Responses: b.responses(
+			withCookies(b.data(http.StatusOK, "Invitation accepted", domain.OrganizationResponse{})),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusConflict,
+		),
+	})
+
+	// Login lewat identity provider OIDC eksternal (SSO)
+	b.op(http.MethodGet, "/auth/sso", &Operation{
+		OperationID: "listSSOProviders",
+		Summary:     "List the identity providers available for single sign-on",
+		Tags:        []string{"auth"},
+		Responses: b.responses(
+			b.data(http.StatusOK, "Identity providers", []domain.SSOProviderResponse{}),
+		),
+	})
+	b.op(http.MethodGet, "/auth/sso/{provider}", &Operation{
+		OperationID: "startSSO",
+		Summary:     "Start logging in with an identity provider",
+		Description: "Opened in the user's browser, which is redirected to the provider's login page. `return_to` must be on an allowed origin, otherwise the configured success page is used. With `link=true` and a login session the provider is linked to the authenticated user instead of logging in.",
+		Tags:        []string{"auth"},
+		Security:    []map[string][]string{{}, {"cookieAuth": {}}},
+		Parameters:  append([]Parameter{ssoProviderParam}, b.schemas.queryParameters(domain.SSOStartRequest{})...),
+		Responses: b.responses(
+			found("Redirect to the identity provider"),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound,
+		),
+	})
+	b.op(http.MethodGet, "/auth/sso/{provider}/callback", &Operation{
+		OperationID: "ssoCallback",
+		Summary:     "Complete logging in with an identity provider",
+		Description: "The identity provider redirects the browser here. A known identity logs in its user. A new identity is linked to the user with the same email (compared case-insensitively) only if both the provider and this service have verified that email; an unverified local account fails with `sso_link_required` and must link the provider from a signed-in session. Without a matching user, a new user is created if the provider allows it. On success the token cookies are set and the browser is redirected to `return_to`; on failure it is redirected to the configured error page with an `error` code.",
+		Tags:        []string{"auth"},
+		Parameters:  append([]Parameter{ssoProviderParam}, b.schemas.queryParameters(domain.SSOCallbackRequest{})...),
+		Responses: b.responses(
+			withCookies(found("Redirect to `return_to` or the error page")),
+			http.StatusBadRequest,
+		),
+	})
+
+	// Login tanpa password dengan passkey (WebAuthn)
+	b.op(http.MethodPost, "/auth/passkeys/options", &Operation{
+		OperationID: "passkeyLoginOptions",
+		Summary:     "Start logging in with a passkey",
+		Description: "Returns the options for `navigator.credentials.get()` and sets the `passkey_session` cookie referencing the challenge, which is valid for 5 minutes and can be verified once. No email is needed: the browser offers the passkeys saved for this site.",
+		Tags:        []string{"auth"},
+		Responses: b.responses(
+			b.data(http.StatusOK, "WebAuthn request options", protocol.CredentialAssertion{}),
+		),
+	})
+	b.op(http.MethodPost, "/auth/passkeys/login", &Operation{
+		OperationID: "passkeyLogin",
+		Summary:     "Log in with a passkey",
+		Description: "`credential` is the result of `navigator.credentials.get()` with the options from `POST /auth/passkeys/options`. Sets the `access_token` and `refresh_token` HttpOnly cookies like `POST /auth/login`. A passkey whose signature counter did not increase is rejected as possibly cloned. An account locked after too many wrong passwords returns 429 `account_locked`.",
+		Tags:        []string{"auth"},
+		Parameters:  []Parameter{tenantHeaderParam},
+		RequestBody: b.jsonBody(domain.PasskeyLoginRequest{}),
+		Responses: b.responses(
+			withCookies(b.message(http.StatusOK, "Logged in")),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusTooManyRequests,
+		),
+	})
+
+	// Login tanpa password lewat link di email
+	b.op(http.MethodPost, "/auth/magic-link", &Operation{
+		OperationID: "sendMagicLink",
+		Summary:     "Email a one-time login link",
+		Description: "The link opens the configured frontend page with a `token` query parameter and expires after a few minutes. The link is sent in the background, so the response and its timing are the same whether or not an account exists for the email, and an address that already received the maximum number of links in the throttle window silently gets no new one.",
+		Tags:        []string{"auth"},
+		RequestBody: b.jsonBody(domain.MagicLinkRequest{}),
+		Responses: b.responses(
+			b.message(http.StatusAccepted, "Login link sent if the account exists"),
+			http.StatusBadRequest,
+		),
+	})
+	b.op(http.MethodPost, "/auth/magic-link/verify", &Operation{
+		OperationID: "verifyMagicLink",
+		Summary:     "Log in with a login link",
+		Description: "`token` comes from the link in the email. The frontend page should only call this after a user action, so email scanners that prefetch the link do not use it up. Each token works once. Sets the `access_token` and `refresh_token` HttpOnly cookies like `POST /auth/login`; an account locked after too many wrong passwords returns 429 `account_locked`.",
+		Tags:        []string{"auth"},
+		Parameters:  []Parameter{tenantHeaderParam},
+		RequestBody: b.jsonBody(domain.VerifyMagicLinkRequest{}),
+		Responses: b.responses(
+			withCookies(b.message(http.StatusOK, "Logged in")),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusTooManyRequests,
+		),
+	})
+
+	// Profil user yang sedang login
+	b.op(http.MethodGet, "/api/users/me", &Operation{
+		OperationID: "getCurrentUser",
+		Summary:     "Get the authenticated user's profile",
+		Tags:        []string{"users"},
+		Security:    scoped(domain.ScopeProfileRead),
+		Parameters:  []Parameter{ifNoneMatchParam},
+		Responses: notModified(b.responses(
+			withETag(b.data(http.StatusOK, "Current user", domain.UserResponse{})),
+			http.StatusUnauthorized, http.StatusNotFound,
+		)),
+	})
+	b.op(http.MethodPatch, "/api/users/me", &Operation{
+		OperationID: "updateCurrentUser",
+		Summary:     "Update the authenticated user's profile",
+		Description: "Accepts `application/json` (only the fields present are changed), `application/merge-patch+json` or `application/json-patch+json`. Patches that change `role` are rejected with 403 `field_not_writable`; only admins can change it. `avatar_url` is read-only and only changes through the avatar endpoints.",
+		Tags:        []string{"users"},
+		Security:    scoped(domain.ScopeProfileWrite),
+		Parameters:  []Parameter{ifMatchParam},
+		RequestBody: b.userPatchBody(domain.UpdateProfileRequest{}),
+		Responses: b.responses(
+			withETag(b.userUpdated("Profile updated")),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict,
+			http.StatusPreconditionFailed, http.StatusUnsupportedMediaType,
+		),
+	})
+	b.op(http.MethodDelete, "/api/users/me", &Operation{
+		OperationID: "deleteCurrentUser",
+		Summary:     "Delete the authenticated user's account",
+		Description: "Requires the current password as confirmation and clears the token cookies.",
+		Tags:        []string{"users"},
+		Security:    scoped(domain.ScopeProfileWrite),
+		Parameters:  []Parameter{ifMatchParam},
+		RequestBody: b.jsonBody(domain.DeleteAccountRequest{}),
+		Responses: b.responses(
+			b.message(http.StatusOK, "Account deleted"),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict,
+			http.StatusPreconditionFailed,
+		),
+	})
+
+	// Route user lama, dilayani sampai sunset dengan header Deprecation
+	b.op(http.MethodGet, "/api/users", &Operation{
+		OperationID: "getCurrentUserLegacy",
+		Summary:     "Get the authenticated user's profile (deprecated)",
+		Description: "Use `GET /api/users/me`. Responds with `Deprecation`, `Sunset` and `Link` headers.",
+		Tags:        []string{"users"},
+		Security:    scoped(domain.ScopeProfileRead),
+		Parameters:  []Parameter{ifNoneMatchParam},
+		Deprecated:  true,
+		Responses: notModified(b.responses(
+			withETag(b.data(http.StatusOK, "Current user", domain.UserResponse{})),
+			http.StatusUnauthorized, http.StatusNotFound,
+		)),
+	})
+	b.op(http.MethodPatch, "/api/users/{id}", &Operation{
+		OperationID: "updateUserLegacy",
+		Summary:     "Update a user (deprecated)",
+		Description: "With the authenticated user's own ID this behaves like `PATCH /api/users/me`, otherwise like `PATCH /api/admin/users/{id}` and requires an admin. Responds with `Deprecation`, `Sunset` and `Link` headers.",
+		Tags:        []string{"users"},
+		Security:    append(scoped(domain.ScopeProfileWrite), map[string][]string{"bearerAuth": {domain.ScopeAdminWrite}}),
+		Parameters:  []Parameter{userIDParam, ifMatchParam},
+		Deprecated:  true,
+		RequestBody: b.userPatchBody(domain.UpdateRequest{}),
+		Responses: b.responses(
+			withETag(b.userUpdated("User updated")),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict,
+			http.StatusPreconditionFailed, http.StatusUnsupportedMediaType,
+		),
+	})
+	b.op(http.MethodDelete, "/api/users/{id}", &Operation{
+		OperationID: "deleteUserLegacy",
+		Summary:     "Delete a user (deprecated)",
+		Description: "With the authenticated user's own ID this behaves like `DELETE /api/users/me` and requires the current password, otherwise like `DELETE /api/admin/users/{id}` and requires an admin. Responds with `Deprecation`, `Sunset` and `Link` headers.",
+		Tags:        []string{"users"},
+		Security:    append(scoped(domain.ScopeProfileWrite), map[string][]string{"bearerAuth": {domain.ScopeAdminWrite}}),
+		Parameters:  []Parameter{userIDParam, ifMatchParam},
+		Deprecated:  true,
+		Responses: b.responses(
+			b.message(http.StatusOK, "User deleted"),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict,
+			http.StatusPreconditionFailed,
+		),
+	})
+
+	b.op(http.MethodPut, "/api/users/me/avatar", &Operation{
+		OperationID: "uploadAvatar",
+		Summary:     "Upload or replace the authenticated user's avatar",
+		Description: "The image is re-encoded without metadata (EXIF) and stored as square variants: `large` (512px), `medium` (256px) and `small` (64px). `avatar_url` is set to the large variant; it cannot be changed through `PATCH`.",
+		Tags:        []string{"users"},
+		Security:    scoped(domain.ScopeProfileWrite),
+		RequestBody: fileBody(handler.AvatarFormField, handler.AvatarMediaTypes...),
+		Responses: b.responses(
+			b.data(http.StatusOK, "Updated profile", domain.UserResponse{}),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound,
+			http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType,
+		),
+	})
+	b.op(http.MethodDelete, "/api/users/me/avatar", &Operation{
+		OperationID: "deleteAvatar",
+		Summary:     "Remove the authenticated user's uploaded avatar",
+		Tags:        []string{"users"},
+		Security:    scoped(domain.ScopeProfileWrite),
+		Responses: b.responses(
+			b.message(http.StatusOK, "Avatar removed"),
+			http.StatusUnauthorized, http.StatusNotFound,
+		),
+	})
+	b.op(http.MethodGet, "/api/users/me/tokens", &Operation{
+		OperationID: "listAccessTokens",
+		Summary:     "List the authenticated user's personal access tokens",
+		Description: "Revoked tokens are not listed. Only the `prefix` of each token is shown.",
+		Tags:        []string{"users"},
+		Security:    scoped(domain.ScopeProfileRead),
+		Responses: b.responses(
+			b.data(http.StatusOK, "Access tokens", []domain.AccessTokenResponse{}),
+			http.StatusUnauthorized,
+		),
+	})
+	b.op(http.MethodPost, "/api/users/me/tokens", &Operation{
+		OperationID: "createAccessToken",
+		Summary:     "Create a personal access token",
+		Description: "Requires a login session. `token` is only returned in this response; send it as `Authorization: Bearer <token>`. Without `expires_at` the token does not expire. Admin scopes require the admin role.",
+		Tags:        []string{"users"},
+		Security:    cookieAuth,
+		RequestBody: b.jsonBody(domain.CreateAccessTokenRequest{}),
+		Responses: b.responses(
+			b.data(http.StatusCreated, "Access token created", domain.CreatedAccessTokenResponse{}),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden,
+		),
+	})
+	b.op(http.MethodDelete, "/api/users/me/tokens/{token_id}", &Operation{
+		OperationID: "revokeAccessToken",
+		Summary:     "Revoke a personal access token",
+		Description: "Requires a login session.",
+		Tags:        []string{"users"},
+		Security:    cookieAuth,
+		Parameters:  []Parameter{tokenIDParam},
+		Responses: b.responses(
+			b.message(http.StatusOK, "Access token revoked"),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+		),
+	})
+
+	// Organization (tenant)
+	b.op(http.MethodGet, "/api/organizations", &Operation{
+		OperationID: "listOrganizations",
+		Summary:     "List the organizations the authenticated user belongs to",
+		Tags:        []string{"organizations"},
+		Security:    scoped(domain.ScopeOrganizationsRead),
+		Responses: b.responses(
+			b.data(http.StatusOK, "Organizations with the user's role", []domain.OrganizationResponse{}),
+			http.StatusUnauthorized,
+		),
+	})
+	b.op(http.MethodPost, "/api/organizations", &Operation{
+		OperationID: "createOrganization",
+		Summary:     "Create an organization",
+		Description: "The authenticated user becomes its owner. `slug` is used in subdomains, paths and the `X-Organization` header.",
+		Tags:        []string{"organizations"},
+		Security:    scoped(domain.ScopeOrganizationsWrite),
+		RequestBody: b.jsonBody(domain.CreateOrganizationRequest{}),
+		Responses: b.responses(
+			b.data(http.StatusCreated, "Organization created", domain.OrganizationResponse{}),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusConflict,
+		),
+	})
+	b.op(http.MethodGet, "/api/organizations/{org}", &Operation{
+		OperationID: "getOrganization",
+		Summary:     "Get an organization",
+		Tags:        []string{"organizations"},
+		Security:    scoped(domain.ScopeOrganizationsRead),
+		Parameters:  []Parameter{orgParam},
+		Responses: b.responses(
+			b.data(http.StatusOK, "Organization", domain.OrganizationResponse{}),
+			http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+		),
+	})
+	b.op(http.MethodPost, "/api/organizations/{org}/switch", &Operation{
+		OperationID: "switchOrganization",
+		Summary:     "Make an organization the active tenant",
+		Description: "Sets new token cookies whose `org` claim is this organization.",
+		Tags:        []string{"organizations"},
+		Security:    cookieAuth,
+		Parameters:  []Parameter{orgParam},
+		Responses: b.responses(
+			withCookies(b.message(http.StatusOK, "Active organization switched")),
+			http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+		),
+	})
+	b.op(http.MethodGet, "/api/organizations/{org}/members", &Operation{
+		OperationID: "listMembers",
+		Summary:     "List organization members",
+		Tags:        []string{"organizations"},
+		Security:    scoped(domain.ScopeOrganizationsRead),
+		Parameters:  []Parameter{orgParam},
+		Responses: b.responses(
+			b.data(http.StatusOK, "Members", []domain.MemberResponse{}),
+			http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+		),
+	})
+	b.op(http.MethodPost, "/api/organizations/{org}/members", &Operation{
+		OperationID: "addMember",
+		Summary:     "Add a user to the organization",
+		Description: "Requires the `owner` or `admin` role. Only owners can add owners.",
+		Tags:        []string{"organizations"},
+		Security:    scoped(domain.ScopeOrganizationsWrite),
+		Parameters:  []Parameter{orgParam},
+		RequestBody: b.jsonBody(domain.AddMemberRequest{}),
+		Responses: b.responses(
+			b.data(http.StatusCreated, "Member added", domain.MemberResponse{}),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict,
+		),
+	})
+	b.op(http.MethodPatch, "/api/organizations/{org}/members/{user_id}", &Operation{
+		OperationID: "updateMember",
+		Summary:     "Change a member's role",
+		Description: "Requires the `owner` or `admin` role. Only owners can grant or revoke `owner`, and the last owner cannot be demoted.",
+		Tags:        []string{"organizations"},
+		Security:    scoped(domain.ScopeOrganizationsWrite),
+		Parameters:  []Parameter{orgParam, memberIDParam},
+		RequestBody: b.jsonBody(domain.UpdateMemberRequest{}),
+		Responses: b.responses(
+			b.data(http.StatusOK, "Member updated", domain.MemberResponse{}),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict,
+		),
+	})
+	b.op(http.MethodDelete, "/api/organizations/{org}/members/{user_id}", &Operation{
+		OperationID: "removeMember",
+		Summary:     "Remove a member or leave the organization",
+		Description: "Members can remove themselves. Removing others requires the `owner` or `admin` role, and only owners can remove owners. The last owner cannot leave.",
+		Tags:        []string{"organizations"},
+		Security:    scoped(domain.ScopeOrganizationsWrite),
+		Parameters:  []Parameter{orgParam, memberIDParam},
+		Responses: b.responses(
+			b.message(http.StatusOK, "Member removed"),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict,
+		),
+	})
+
+	b.op(http.MethodGet, "/api/organizations/{org}/invitations", &Operation{
+		OperationID: "listInvitations",
+		Summary:     "List organization invitations, newest first",
+		Description: "Requires the `owner` or `admin` role.",
+		Tags:        []string{"organizations"},
+		Security:    scoped(domain.ScopeOrganizationsRead),
+		Parameters:  append([]Parameter{orgParam}, b.schemas.queryParameters(handler.InvitationQuery{})...),
+		Responses: b.responses(
+			b.data(http.StatusOK, "Invitations", []domain.InvitationResponse{}),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+		),
+	})
+	b.op(http.MethodPost, "/api/organizations/{org}/invitations", &Operation{
+		OperationID: "createInvitation",
+		Summary:     "Invite an email address to the organization",
+		Description: "Requires the `owner` or `admin` role. Only owners can invite owners. The invitation link is emailed and expires after 7 days by default.",
+		Tags:        []string{"organizations"},
+		Security:    scoped(domain.ScopeOrganizationsWrite),
+		Parameters:  []Parameter{orgParam},
+		RequestBody: b.jsonBody(domain.CreateInvitationRequest{}),
+		Responses: b.responses(
+			b.data(http.StatusCreated, "Invitation sent", domain.InvitationResponse{}),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict,
+		),
+	})
+	b.op(http.MethodPost, "/api/organizations/{org}/invitations/{invitation_id}/resend", &Operation{
+		OperationID: "resendInvitation",
+		Summary:     "Resend an invitation",
+		Description: "Issues a new link (the previous one stops working) and restarts the expiry.",
+		Tags:        []string{"organizations"},
+		Security:    scoped(domain.ScopeOrganizationsWrite),
+		Parameters:  []Parameter{orgParam, invitationIDParam},
+		Responses: b.responses(
+			b.data(http.StatusOK, "Invitation resent", domain.InvitationResponse{}),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict,
+		),
+	})
+	b.op(http.MethodDelete, "/api/organizations/{org}/invitations/{invitation_id}", &Operation{
+		OperationID: "revokeInvitation",
+		Summary:     "Revoke an invitation",
+		Tags:        []string{"organizations"},
+		Security:    scoped(domain.ScopeOrganizationsWrite),
+		Parameters:  []Parameter{orgParam, invitationIDParam},
+		Responses: b.responses(
+			b.message(http.StatusOK, "Invitation revoked"),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict,
+		),
+	})
+
+	// Admin
+	b.op(http.MethodGet, "/api/admin", &Operation{
+		OperationID: "adminDashboard",
+		Summary:     "Admin dashboard summary",
+		Tags:        []string{"admin"},
+		Security:    scoped(domain.ScopeAdminRead),
+		Responses: b.responses(
+			b.response(http.StatusOK, "Dashboard", &Schema{Type: "object"}),
+			http.StatusUnauthorized, http.StatusForbidden,
+		),
+	})
+	b.op(http.MethodGet, "/api/admin/users", &Operation{
+		OperationID: "listUsers",
+		Summary:     "List users, newest first",
+		Description: "`q` matches name or email. `total` is the number of matching users across all pages. With an active organization only its members are listed.",
+		Tags:        []string{"admin"},
+		Security:    scoped(domain.ScopeAdminRead),
+		Parameters:  append(b.schemas.queryParameters(handler.UserQuery{}), tenantHeaderParam),
+		Responses: b.responses(
+			b.response(http.StatusOK, "Users", &Schema{
+				Type: "object",
+				Properties: map[string]*Schema{
+					"status": {Type: "string"},
+					"data":   {Type: "array", Items: b.schemas.Ref(domain.UserResponse{})},
+					"total":  {Type: "integer"},
+				},
+				Required: []string{"status", "data", "total"},
+			}),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden,
+		),
+	})
+	b.op(http.MethodGet, "/api/admin/users/{id}", &Operation{
+		OperationID: "getUser",
+		Summary:     "Get a user",
+		Tags:        []string{"admin"},
+		Security:    scoped(domain.ScopeAdminRead),
+		Parameters:  []Parameter{userIDParam, ifNoneMatchParam},
+		Responses: notModified(b.responses(
+			withETag(b.data(http.StatusOK, "User", domain.UserResponse{})),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+		)),
+	})
+	b.op(http.MethodPatch, "/api/admin/users/{id}", &Operation{
+		OperationID: "updateUser",
+		Summary:     "Update a user",
+		Description: "Accepts `application/json`, `application/merge-patch+json` or `application/json-patch+json`. Admins can change every field of the user document, including `role`. `If-Match` is required so an admin never overwrites a change they have not seen; without it the request fails with 428 `precondition_required`.",
+		Tags:        []string{"admin"},
+		Security:    scoped(domain.ScopeAdminWrite),
+		Parameters:  []Parameter{userIDParam, requiredIfMatchParam},
+		RequestBody: b.userPatchBody(domain.UpdateRequest{}),
+		Responses: b.responses(
+			withETag(b.userUpdated("User updated")),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict,
+			http.StatusPreconditionFailed, http.StatusUnsupportedMediaType, http.StatusPreconditionRequired,
+		),
+	})
+	b.op(http.MethodDelete, "/api/admin/users/{id}", &Operation{
+		OperationID: "deleteUser",
+		Summary:     "Delete a user",
+		Description: "`If-Match` is required; without it the request fails with 428 `precondition_required`.",
+		Tags:        []string{"admin"},
+		Security:    scoped(domain.ScopeAdminWrite),
+		Parameters:  []Parameter{userIDParam, requiredIfMatchParam},
+		Responses: b.responses(
+			b.message(http.StatusOK, "User deleted"),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict,
+			http.StatusPreconditionFailed, http.StatusPreconditionRequired,
+		),
+	})
+	b.op(http.MethodGet, "/api/admin/audit", &Operation{
+		OperationID: "listAuditLogs",
+		Summary:     "List audit log entries, newest first",
+		Description: "Use `next_before_seq` from the response as `before_seq` to fetch the next page.",
+		Tags:        []string{"admin"},
+		Security:    scoped(domain.ScopeAdminRead),
+		Parameters:  b.schemas.queryParameters(handler.AuditQuery{}),
+		Responses: b.responses(
+			b.response(http.StatusOK, "Audit log entries", &Schema{
+				Type: "object",
+				Properties: map[string]*Schema{
+					"status":          {Type: "string"},
+					"data":            {Type: "array", Items: b.schemas.Ref(domain.AuditLog{})},
+					"next_before_seq": {Type: "integer"},
+				},
+				Required: []string{"status", "data"},
+			}),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden,
+		),
+	})
+	b.op(http.MethodGet, "/api/admin/audit/verify", &Operation{
+		OperationID: "verifyAuditLog",
+		Summary:     "Verify the audit log hash chain",
+		Description: "Entries are spread over several hash chains (`chain`); each entry must point to the previous entry of its chain. Verification is incremental: each chain keeps a checkpoint at its last verified entry, so a call only checks entries written since the previous one (`checked`), and a call cut short by the request timeout resumes where it stopped. `broken_at` is the `seq` of the first entry that does not verify; `last_hash` is the hash of the newest verified entry.",
+		Tags:        []string{"admin"},
+		Security:    scoped(domain.ScopeAdminRead),
+		Responses: b.responses(
+			b.data(http.StatusOK, "Verification result", domain.AuditVerification{}),
+			http.StatusUnauthorized, http.StatusForbidden,
+		),
+	})
+
+	// Service account
+	b.op(http.MethodGet, "/api/admin/service-accounts", &Operation{
+		OperationID: "listServiceAccounts",
+		Summary:     "List service accounts, newest first",
+		Tags:        []string{"admin"},
+		Security:    scoped(domain.ScopeAdminRead),
+		Responses: b.responses(
+			b.data(http.StatusOK, "Service accounts", []domain.ServiceAccountResponse{}),
+			http.StatusUnauthorized,
+		),
+	})
+	b.op(http.MethodPost, "/api/admin/service-accounts", &Operation{
+		OperationID: "createServiceAccount",
+		Summary:     "Create a service account",
+		Description: "Requires a login session. Service accounts authenticate other backend services with API keys and can only use operations allowed by their `scopes`. `role` defaults to `service`; admin scopes require the `admin` role.",
+		Tags:        []string{"admin"},
+		Security:    cookieAuth,
+		RequestBody: b.jsonBody(domain.CreateServiceAccountRequest{}),
+		Responses: b.responses(
+			b.data(http.StatusCreated, "Service account created", domain.ServiceAccountResponse{}),
+			http.StatusBadRequest, http.StatusUnauthorized,
+		),
+	})
+	b.op(http.MethodGet, "/api/admin/service-accounts/{service_account_id}", &Operation{
+		OperationID: "getServiceAccount",
+		Summary:     "Get a service account",
+		Tags:        []string{"admin"},
+		Security:    scoped(domain.ScopeAdminRead),
+		Parameters:  []Parameter{serviceAccountIDParam},
+		Responses: b.responses(
+			b.data(http.StatusOK, "Service account", domain.ServiceAccountResponse{}),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound,
+		),
+	})
+	b.op(http.MethodDelete, "/api/admin/service-accounts/{service_account_id}", &Operation{
+		OperationID: "deleteServiceAccount",
+		Summary:     "Delete a service account",
+		Description: "Requires a login session. All API keys of the service account are revoked.",
+		Tags:        []string{"admin"},
+		Security:    cookieAuth,
+		Parameters:  []Parameter{serviceAccountIDParam},
+		Responses: b.responses(
+			b.message(http.StatusOK, "Service account deleted"),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound,
+		),
+	})
+	b.op(http.MethodGet, "/api/admin/service-accounts/{service_account_id}/keys", &Operation{
+		OperationID: "listAPIKeys",
+		Summary:     "List the API keys of a service account",
+		Description: "Revoked keys are not listed; keys past `expires_at` are listed until revoked. Only the `key_id` of each key is shown.",
+		Tags:        []string{"admin"},
+		Security:    scoped(domain.ScopeAdminRead),
+		Parameters:  []Parameter{serviceAccountIDParam},
+		Responses: b.responses(
+			b.data(http.StatusOK, "API keys", []domain.APIKeyResponse{}),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound,
+		),
+	})
+	b.op(http.MethodPost, "/api/admin/service-accounts/{service_account_id}/keys", &Operation{
+		OperationID: "createAPIKey",
+		Summary:     "Create an API key",
+		Description: "Requires a login session. `key` (`gsk_<key_id>_<secret>`) is only returned in this response; the service sends it as `Authorization: Bearer <key>`. `allowed_ips` takes IP addresses or CIDR ranges; requests from other addresses are rejected with 403. Without `allowed_ips` every address is allowed, and without `expires_at` the key does not expire.",
+		Tags:        []string{"admin"},
+		Security:    cookieAuth,
+		Parameters:  []Parameter{serviceAccountIDParam},
+		RequestBody: b.jsonBody(domain.CreateAPIKeyRequest{}),
+		Responses: b.responses(
+			b.data(http.StatusCreated, "API key created", domain.CreatedAPIKeyResponse{}),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound,
+		),
+	})
+	b.op(http.MethodPost, "/api/admin/service-accounts/{service_account_id}/keys/{key_id}/rotate", &Operation{
+		OperationID: "rotateAPIKey",
+		Summary:     "Rotate an API key",
+		Description: "Requires a login session. Creates a replacement key with the same name and `allowed_ips`. The old key keeps working for `overlap_seconds` (server default when omitted, 24 hours unless configured) so the service can switch without downtime; `0` revokes it immediately. The body is optional.",
+		Tags:        []string{"admin"},
+		Security:    cookieAuth,
+		Parameters:  []Parameter{serviceAccountIDParam, apiKeyIDParam},
+		RequestBody: &RequestBody{Content: b.jsonBody(domain.RotateAPIKeyRequest{}).Content},
+		Responses: b.responses(
+			b.data(http.StatusCreated, "API key rotated", domain.CreatedAPIKeyResponse{}),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound,
+		),
+	})
+	b.op(http.MethodDelete, "/api/admin/service-accounts/{service_account_id}/keys/{key_id}", &Operation{
+		OperationID: "revokeAPIKey",
+		Summary:     "Revoke an API key",
+		Description: "Requires a login session.",
+		Tags:        []string{"admin"},
+		Security:    cookieAuth,
+		Parameters:  []Parameter{serviceAccountIDParam, apiKeyIDParam},
+		Responses: b.responses(
+			b.message(http.StatusOK, "API key revoked"),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound,
+		),
+	})
+
+	// OAuth 2.1 / OpenID Connect provider
+	b.op(http.MethodGet, "/oauth/authorize", &Operation{
+		OperationID: "oauthAuthorize",
+		Summary:     "Start the OAuth authorization code flow",
+		Description: "Opened in the user's browser. Only the `code` response type with PKCE (`S256`) is supported. Without a login session the browser is redirected to the login page with `return_to`; if the user has not yet approved the requested scopes it is redirected to the consent page with a signed `request`. Otherwise the browser is redirected to `redirect_uri` with `code`, `iss` and `state`, or with `error` and `error_description`. An unknown client or unregistered `redirect_uri` is not redirected and returns a problem instead. `prompt=none` never shows the login or consent page.",
+		Tags:        []string{"oauth"},
+		Parameters:  b.schemas.queryParameters(domain.AuthorizeRequest{}),
+		Responses: b.responses(
+			found("Redirect to the login page, consent page or client redirect URI"),
+			http.StatusBadRequest, http.StatusNotFound,
+		),
+	})
+	b.op(http.MethodGet, "/oauth/consent", &Operation{
+		OperationID: "getOAuthConsent",
+		Summary:     "Get the client and scopes to show on the consent page",
+		Description: "Requires a login session. `request` is the query value the consent page was opened with.",
+		Tags:        []string{"oauth"},
+		Security:    cookieAuth,
+		Parameters: []Parameter{{
+			Name:     "request",
+			In:       "query",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		}},
+		Responses: b.responses(
+			b.data(http.StatusOK, "Consent details", domain.ConsentDetails{}),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound,
+		),
+	})
+	b.op(http.MethodPost, "/oauth/consent", &Operation{
+		OperationID: "submitOAuthConsent",
+		Summary:     "Approve or deny an authorization request",
+		Description: "Requires a login session. The consent page sends the browser to `redirect_to`, the client redirect URI with a `code` or an `access_denied` error.",
+		Tags:        []string{"oauth"},
+		Security:    cookieAuth,
+		RequestBody: b.jsonBody(domain.ConsentRequest{}),
+		Responses: b.responses(
+			b.data(http.StatusOK, "Redirect target", domain.AuthorizeResponse{}),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound,
+		),
+	})
+	b.op(http.MethodPost, "/oauth/token", &Operation{
+		OperationID: "oauthToken",
+		Summary:     "Exchange a grant for tokens",
+		Description: "Supports the `authorization_code`, `refresh_token` and `client_credentials` grants. Confidential clients authenticate with HTTP Basic or `client_id` and `client_secret` in the body; public clients only send `client_id`. Refresh tokens are rotated on every use and are only issued when `offline_access` was granted; reusing a code or refresh token revokes every token issued from it. Errors use the OAuth format instead of problem+json.",
+		Tags:        []string{"oauth"},
+		RequestBody: formBody(b.schemas.Ref(domain.TokenRequest{})),
+		Responses: b.oauthResponses(
+			b.response(http.StatusOK, "Tokens", b.schemas.Ref(domain.TokenResponse{})),
+			http.StatusBadRequest, http.StatusUnauthorized,
+		),
+	})
+	b.op(http.MethodPost, "/oauth/revoke", &Operation{
+		OperationID: "oauthRevoke",
+		Summary:     "Revoke a refresh token",
+		Description: "RFC 7009 token revocation. Every token issued from the same authorization code is revoked. Unknown tokens are answered with 200.",
+		Tags:        []string{"oauth"},
+		RequestBody: formBody(b.schemas.Ref(domain.RevokeTokenRequest{})),
+		Responses: b.oauthResponses(
+			statusResponse{http.StatusOK, &Response{Description: "Token revoked"}},
+			http.StatusBadRequest, http.StatusUnauthorized,
+		),
+	})
+	for method, id := range map[string]string{http.MethodGet: "getOAuthUserInfo", http.MethodPost: "postOAuthUserInfo"} {
+		b.op(method, "/oauth/userinfo", &Operation{
+			OperationID: id,
+			Summary:     "Get the claims of the user an OAuth access token was issued for",
+			Description: "Send the OAuth access token as `Authorization: Bearer <token>`. The token must include the `openid` scope; `profile` and `email` add the matching claims.",
+			Tags:        []string{"oauth"},
+			Responses: b.oauthResponses(
+				b.response(http.StatusOK, "User claims", b.schemas.Ref(domain.UserInfo{})),
+				http.StatusUnauthorized, http.StatusForbidden,
+			),
+		})
+	}
+	b.op(http.MethodGet, "/api/users/me/authorizations", &Operation{
+		OperationID: "listOAuthGrants",
+		Summary:     "List the applications the authenticated user has authorized",
+		Tags:        []string{"users"},
+		Security:    scoped(domain.ScopeProfileRead),
+		Responses: b.responses(
+			b.data(http.StatusOK, "Authorized applications", []domain.OAuthGrantResponse{}),
+			http.StatusUnauthorized,
+		),
+	})
+	b.op(http.MethodDelete, "/api/users/me/authorizations/{client_id}", &Operation{
+		OperationID: "revokeOAuthGrant",
+		Summary:     "Revoke an application's access",
+		Description: "Requires a login session. The consent and every refresh token of the application are revoked; the application has to ask for consent again.",
+		Tags:        []string{"users"},
+		Security:    cookieAuth,
+		Parameters:  []Parameter{oauthClientIDParam},
+		Responses: b.responses(
+			b.message(http.StatusOK, "Access revoked"),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound,
+		),
+	})
+	b.op(http.MethodGet, "/api/users/me/identities", &Operation{
+		OperationID: "listIdentities",
+		Summary:     "List the identity providers linked to the authenticated user",
+		Tags:        []string{"users"},
+		Security:    scoped(domain.ScopeProfileRead),
+		Responses: b.responses(
+			b.data(http.StatusOK, "Linked identities", []domain.IdentityResponse{}),
+			http.StatusUnauthorized,
+		),
+	})
+	b.op(http.MethodDelete, "/api/users/me/identities/{identity_id}", &Operation{
+		OperationID: "unlinkIdentity",
+		Summary:     "Unlink an identity provider",
+		Description: "Requires a login session. The account must keep another way to log in (password, another identity or a passkey).",
+		Tags:        []string{"users"},
+		Security:    cookieAuth,
+		Parameters:  []Parameter{identityIDParam},
+		Responses: b.responses(
+			b.message(http.StatusOK, "Identity unlinked"),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusConflict,
+		),
+	})
+	b.op(http.MethodGet, "/api/users/me/passkeys", &Operation{
+		OperationID: "listPasskeys",
+		Summary:     "List the authenticated user's passkeys",
+		Tags:        []string{"users"},
+		Security:    scoped(domain.ScopeProfileRead),
+		Responses: b.responses(
+			b.data(http.StatusOK, "Passkeys", []domain.PasskeyResponse{}),
+			http.StatusUnauthorized,
+		),
+	})
+	b.op(http.MethodPost, "/api/users/me/passkeys/options", &Operation{
+		OperationID: "passkeyRegistrationOptions",
+		Summary:     "Start registering a passkey",
+		Description: "Requires a login session. Returns the options for `navigator.credentials.create()` and sets the `passkey_session` cookie referencing the challenge, which is valid for 5 minutes and can be verified once. Passkeys already registered are excluded.",
+		Tags:        []string{"users"},
+		Security:    cookieAuth,
+		Responses: b.responses(
+			b.data(http.StatusOK, "WebAuthn creation options", protocol.CredentialCreation{}),
+			http.StatusUnauthorized, http.StatusForbidden,
+		),
+	})
+	b.op(http.MethodPost, "/api/users/me/passkeys", &Operation{
+		OperationID: "registerPasskey",
+		Summary:     "Register a passkey",
+		Description: "Requires a login session. `credential` is the result of `navigator.credentials.create()` with the options from `POST /api/users/me/passkeys/options`. Without `name` the passkey is named \"Passkey\".",
+		Tags:        []string{"users"},
+		Security:    cookieAuth,
+		RequestBody: b.jsonBody(domain.RegisterPasskeyRequest{}),
+		Responses: b.responses(
+			b.data(http.StatusCreated, "Passkey registered", domain.PasskeyResponse{}),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusConflict,
+		),
+	})
+	b.op(http.MethodPatch, "/api/users/me/passkeys/{passkey_id}", &Operation{
+		OperationID: "renamePasskey",
+		Summary:     "Rename a passkey",
+		Description: "Requires a login session.",
+		Tags:        []string{"users"},
+		Security:    cookieAuth,
+		Parameters:  []Parameter{passkeyIDParam},
+		RequestBody: b.jsonBody(domain.RenamePasskeyRequest{}),
+		Responses: b.responses(
+			b.data(http.StatusOK, "Passkey renamed", domain.PasskeyResponse{}),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+		),
+	})
+	b.op(http.MethodDelete, "/api/users/me/passkeys/{passkey_id}", &Operation{
+		OperationID: "deletePasskey",
+		Summary:     "Remove a passkey",
+		Description: "Requires a login session. The account must keep another way to log in (password, an identity provider or another passkey).",
+		Tags:        []string{"users"},
+		Security:    cookieAuth,
+		Parameters:  []Parameter{passkeyIDParam},
+		Responses: b.responses(
+			b.message(http.StatusOK, "Passkey removed"),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict,
+		),
+	})
+	b.op(http.MethodGet, "/api/admin/oauth-clients", &Operation{
+		OperationID: "listOAuthClients",
+		Summary:     "List OAuth clients, newest first",
+		Tags:        []string{"admin"},
+		Security:    scoped(domain.ScopeAdminRead),
+		Responses: b.responses(
+			b.data(http.StatusOK, "OAuth clients", []domain.OAuthClientResponse{}),
+			http.StatusUnauthorized,
+		),
+	})
+	b.op(http.MethodPost, "/api/admin/oauth-clients", &Operation{
+		OperationID: "createOAuthClient",
+		Summary:     "Register an OAuth client",
+		Description: "Requires a login session. `client_secret` is only returned in this response and only for confidential clients. `authorization_code` requires at least one `redirect_uri`, matched exactly; `refresh_token` requires `authorization_code`, and public clients cannot use `client_credentials`. With `skip_consent` users are not asked for consent (first-party clients).",
+		Tags:        []string{"admin"},
+		Security:    cookieAuth,
+		RequestBody: b.jsonBody(domain.CreateOAuthClientRequest{}),
+		Responses: b.responses(
+			b.data(http.StatusCreated, "OAuth client created", domain.CreatedOAuthClientResponse{}),
+			http.StatusBadRequest, http.StatusUnauthorized,
+		),
+	})
+	b.op(http.MethodGet, "/api/admin/oauth-clients/{client_id}", &Operation{
+		OperationID: "getOAuthClient",
+		Summary:     "Get an OAuth client",
+		Tags:        []string{"admin"},
+		Security:    scoped(domain.ScopeAdminRead),
+		Parameters:  []Parameter{oauthClientIDParam},
+		Responses: b.responses(
+			b.data(http.StatusOK, "OAuth client", domain.OAuthClientResponse{}),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound,
+		),
+	})
+	b.op(http.MethodDelete, "/api/admin/oauth-clients/{client_id}", &Operation{
+		OperationID: "deleteOAuthClient",
+		Summary:     "Delete an OAuth client",
+		Description: "Requires a login session. All refresh tokens of the client are revoked; access tokens stay valid until they expire.",
+		Tags:        []string{"admin"},
+		Security:    cookieAuth,
+		Parameters:  []Parameter{oauthClientIDParam},
+		Responses: b.responses(
+			b.message(http.StatusOK, "OAuth client deleted"),
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound,
+		),
+	})
+
+	return b.doc
+}
+
+var maxIdempotencyKeyLength = 255
+
+var (
+	// cookieAuth hanya menerima sesi login (cookie access_token)
+	cookieAuth = []map[string][]string{{"cookieAuth": {}}}
+
+	userIDParam = Parameter{
+		Name:     "id",
+		In:       "path",
+		Required: true,
+		Schema:   &Schema{Type: "string", Format: "uuid"},
+	}
+
+	orgParam = Parameter{
+		Name:        middleware.TenantParam,
+		In:          "path",
+		Required:    true,
+		Description: "Organization slug or ID",
+		Schema:      &Schema{Type: "string"},
+	}
+
+	memberIDParam = Parameter{
+		Name:     "user_id",
+		In:       "path",
+		Required: true,
+		Schema:   &Schema{Type: "string", Format: "uuid"},
+	}
+
+	tokenIDParam = Parameter{
+		Name:     "token_id",
+		In:       "path",
+		Required: true,
+		Schema:   &Schema{Type: "string", Format: "uuid"},
+	}
+
+	serviceAccountIDParam = Parameter{
+		Name:     "service_account_id",
+		In:       "path",
+		Required: true,
+		Schema:   &Schema{Type: "string", Format: "uuid"},
+	}
+
+	apiKeyIDParam = Parameter{
+		Name:     "key_id",
+		In:       "path",
+		Required: true,
+		Schema:   &Schema{Type: "string", Format: "uuid"},
+	}
+
+	oauthClientIDParam = Parameter{
+		Name:     "client_id",
+		In:       "path",
+		Required: true,
+		Schema:   &Schema{Type: "string", Format: "uuid"},
+	}
+
+	identityIDParam = Parameter{
+		Name:     "identity_id",
+		In:       "path",
+		Required: true,
+		Schema:   &Schema{Type: "string", Format: "uuid"},
+	}
+
+	passkeyIDParam = Parameter{
+		Name:     "passkey_id",
+		In:       "path",
+		Required: true,
+		Schema:   &Schema{Type: "string", Format: "uuid"},
+	}
+
+	ssoProviderParam = Parameter{
+		Name:        "provider",
+		In:          "path",
+		Required:    true,
+		Description: "Identity provider name from `GET /auth/sso`",
+		Schema:      &Schema{Type: "string"},
+	}
+
+	invitationIDParam = Parameter{
+		Name:     "invitation_id",
+		In:       "path",
+		Required: true,
+		Schema:   &Schema{Type: "string", Format: "uuid"},
+	}
+
+	tenantHeaderParam = Parameter{
+		Name:        middleware.TenantHeader,
+		In:          "header",
+		Description: "Organization slug or ID to act in. Defaults to the active organization of the access token.",
+		Schema:      &Schema{Type: "string"},
+	}
+
+	ifMatchParam = Parameter{
+		Name:        "If-Match",
+		In:          "header",
+		Description: "ETag from a previous response. The request fails with 412 if the user has changed since.",
+		Schema:      &Schema{Type: "string"},
+	}
+	requiredIfMatchParam = Parameter{
+		Name:        ifMatchParam.Name,
+		In:          ifMatchParam.In,
+		Description: ifMatchParam.Description,
+		Required:    true,
+		Schema:      ifMatchParam.Schema,
+	}
+
+	idempotencyKeyParam = Parameter{
+		Name:        middleware.IdempotencyKeyHeader,
+		In:          "header",
+		Description: "Unique key (1-255 printable characters) for safely retrying the request. A retry with the same key and body replays the stored response with `Idempotent-Replayed: true`.",
+		Schema:      &Schema{Type: "string", MaxLength: &maxIdempotencyKeyLength},
+	}
+
+	ifNoneMatchParam = Parameter{
+		Name:        "If-None-Match",
+		In:          "header",
+		Description: "ETag from a previous response. Returns 304 if the user is unchanged.",
+		Schema:      &Schema{Type: "string"},
+	}
+)
+
+type builder struct {
+	doc     *Document
+	schemas *schemaRegistry
+}
+
+func newBuilder() *builder {
+	schemas := newSchemaRegistry()
+	schemas.Ref(middleware.Problem{})
+
+	return &builder{
+		schemas: schemas,
+		doc: &Document{
+			OpenAPI: "3.1.0",
+			Info: Info{
+				Title:       "gin-api",
+				Version:     "1.0.0",
+				Description: "Authentication and user management API. Errors are returned as `application/problem+json` (RFC 7807). Unversioned paths (without the `/v1` prefix) are deprecated and respond with `Deprecation`, `Sunset` and `Link` headers. POST, PATCH and DELETE requests accept an `Idempotency-Key` header; successful responses are stored for 24 hours by default and replayed on retries. Requests act within one organization (tenant), selected by the `{org}` path parameter, the `X-Organization` header, the subdomain or the access token's active organization; user data is only visible to members of that organization. Scripts can authenticate with a personal access token, and other backend services with a service account API key, in the `Authorization: Bearer` header; each operation lists the scope such a credential needs. Operations on the authenticated user (`/api/users/me`, organizations) are not available to service accounts. The API is also an OAuth 2.1 / OpenID Connect provider; discovery is served at `/.well-known/openid-configuration` outside the versioned prefix. Users can also log in with external OpenID Connect identity providers (single sign-on) or with passkeys (WebAuthn).",
+			},
+			Paths: map[string]*PathItem{},
+			Components: Components{
+				Schemas: schemas.schemas,
+				SecuritySchemes: map[string]*SecurityScheme{
+					"cookieAuth":    {Type: "apiKey", In: "cookie", Name: "access_token"},
+					"bearerAuth":    {Type: "http", Scheme: "bearer", Description: "Personal access token (`gpat_...`), service account API key (`gsk_...`) or access JWT. Personal access tokens and API keys only reach operations whose required scope they hold."},
+					"refreshCookie": {Type: "apiKey", In: "cookie", Name: "refresh_token"},
+				},
+			},
+			Tags: []Tag{
+				{Name: "auth", Description: "Registration, login (password, single sign-on or passkey), token refresh and invitation acceptance"},
+				{Name: "users", Description: "User profiles"},
+				{Name: "organizations", Description: "Organizations (tenants), their members and invitations"},
+				{Name: "admin", Description: "Admin-only endpoints"},
+				{Name: "oauth", Description: "OAuth 2.1 / OpenID Connect provider for third-party applications"},
+			},
+		},
+	}
+}
+
+func (b *builder) op(method, path string, op *Operation) {
+	switch method {
+	case http.MethodPost, http.MethodPatch, http.MethodDelete:
+		b.idempotent(op)
+	}
+
+	// Personal access token tanpa scope yang dibutuhkan, atau token pada
+	// operasi khusus sesi login, ditolak 403
+	for _, requirement := range op.Security {
+		if _, ok := requirement["cookieAuth"]; ok {
+			b.problems(op, http.StatusForbidden)
+			break
+		}
+	}
+
+	item, ok := b.doc.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		b.doc.Paths[path] = item
+	}
+	(*item)[strings.ToLower(method)] = op
+}
+
+// idempotent mendokumentasikan header Idempotency-Key beserta response
+// 409 (request sama masih diproses) dan 422 (key dipakai request lain)
+func (b *builder) idempotent(op *Operation) {
+	op.Parameters = append(op.Parameters, idempotencyKeyParam)
+	b.problems(op, http.StatusConflict, http.StatusUnprocessableEntity)
+}
+
+// problems menambahkan response problem+json untuk status yang belum
+// didokumentasikan operasi
+func (b *builder) problems(op *Operation, statuses ...int) {
+	problem := &MediaType{Schema: b.schemas.Ref(middleware.Problem{})}
+	for _, status := range statuses {
+		if _, ok := op.Responses[strconv.Itoa(status)]; ok {
+			continue
+		}
+		op.Responses[strconv.Itoa(status)] = &Response{
+			Description: http.StatusText(status),
+			Content:     map[string]*MediaType{middleware.ProblemContentType: problem},
+		}
+	}
+}
+
+func (b *builder) jsonBody(v any) *RequestBody {
+	return &RequestBody{
+		Required: true,
+		Content:  map[string]*MediaType{"application/json": {Schema: b.schemas.Ref(v)}},
+	}
+}
+
+// statusResponse adalah pasangan status code dan response sukses
+type statusResponse struct {
+	status   int
+	response *Response
+}
+
+// JSONPatchOperation adalah satu operasi JSON Patch (RFC 6902)
+type JSONPatchOperation struct {
+	Op    string `json:"op" binding:"required,oneof=add remove replace move copy test"`
+	Path  string `json:"path" binding:"required"`
+	From  string `json:"from,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// userPatchBody menerima JSON biasa (plain) serta JSON Merge Patch dan JSON
+// Patch terhadap dokumen user
+func (b *builder) userPatchBody(plain any) *RequestBody {
+	body := b.jsonBody(plain)
+
+	// Merge patch: semua field opsional, null mengosongkan nilai
+	merge := *b.schemas.structSchema(reflect.TypeOf(domain.UserDocument{}))
+	merge.Required = nil
+	merge.Description = "RFC 7396 merge patch against the user document. `null` clears a field."
+	body.Content[handler.MergePatchContentType] = &MediaType{Schema: &merge}
+
+	body.Content[handler.JSONPatchContentType] = &MediaType{Schema: &Schema{
+		Type:        "array",
+		Description: "RFC 6902 operations against the user document, e.g. `/display_name`.",
+		Items:       b.schemas.Ref(JSONPatchOperation{}),
+	}}
+	return body
+}
+
+// formBody adalah body application/x-www-form-urlencoded, dipakai endpoint
+// OAuth yang mengikuti RFC 6749
+func formBody(schema *Schema) *RequestBody {
+	return &RequestBody{
+		Required: true,
+		Content:  map[string]*MediaType{"application/x-www-form-urlencoded": {Schema: schema}},
+	}
+}
+
+// fileBody adalah body multipart/form-data dengan satu field file
+func fileBody(field string, mediaTypes ...string) *RequestBody {
+	return &RequestBody{
+		Required: true,
+		Content: map[string]*MediaType{"multipart/form-data": {Schema: &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				field: {Type: "string", Format: "binary", Description: "One of: " + strings.Join(mediaTypes, ", ")},
+			},
+			Required: []string{field},
+		}}},
+	}
+}
+
+func (b *builder) response(status int, desc string, schema *Schema) statusResponse {
+	return statusResponse{status, &Response{
+		Description: desc,
+		Content:     map[string]*MediaType{"application/json": {Schema: schema}},
+	}}
+}
+
+func (b *builder) message(status int, desc string) statusResponse {
+	return b.response(status, desc, b.schemas.Ref(MessageResponse{}))
+}
+
+func (b *builder) data(status int, desc string, v any) statusResponse {
+	return b.response(status, desc, &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"status": {Type: "string"},
+			"data":   b.schemas.Ref(v),
+		},
+		Required: []string{"status", "data"},
+	})
+}
+
+// responses menggabungkan response sukses dengan response error
+// problem+json untuk setiap status code error yang diberikan
+func (b *builder) responses(success statusResponse, errorStatuses ...int) map[string]*Response {
+	out := map[string]*Response{
+		strconv.Itoa(success.status): success.response,
+	}
+
+	problem := &MediaType{Schema: b.schemas.Ref(middleware.Problem{})}
+	for _, status := range append(errorStatuses, http.StatusInternalServerError) {
+		out[strconv.Itoa(status)] = &Response{
+			Description: http.StatusText(status),
+			Content:     map[string]*MediaType{middleware.ProblemContentType: problem},
+		}
+	}
+	return out
+}
+
+// oauthResponses seperti responses, tetapi error dari client OAuth memakai
+// format {"error": ...} (RFC 6749 bagian 5.2)
+func (b *builder) oauthResponses(success statusResponse, errorStatuses ...int) map[string]*Response {
+	out := b.responses(success)
+
+	oauthError := &MediaType{Schema: b.schemas.Ref(middleware.OAuthError{})}
+	for _, status := range errorStatuses {
+		out[strconv.Itoa(status)] = &Response{
+			Description: http.StatusText(status),
+			Content:     map[string]*MediaType{"application/json": oauthError},
+		}
+	}
+	return out
+}
+
+// found adalah response redirect 302 dengan header Location
+func found(desc string) statusResponse {
+	return statusResponse{http.StatusFound, &Response{
+		Description: desc,
+		Headers: map[string]*Header{
+			"Location": {Schema: &Schema{Type: "string", Format: "uri"}},
+		},
+	}}
+}
+
+// userUpdated adalah response sukses update user: pesan dan user terbaru
+func (b *builder) userUpdated(desc string) statusResponse {
+	return b.response(http.StatusOK, desc, &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"status":  {Type: "string"},
+			"message": {Type: "string"},
+			"data":    b.schemas.Ref(domain.UserResponse{}),
+		},
+		Required: []string{"status", "message", "data"},
+	})
+}
+
+func withETag(sr statusResponse) statusResponse {
+	if sr.response.Headers == nil {
+		sr.response.Headers = map[string]*Header{}
+	}
+	sr.response.Headers["ETag"] = &Header{
+		Description: "Current version of the user, for `If-Match` and `If-None-Match`",
+		Schema:      &Schema{Type: "string"},
+	}
+	return sr
+}
+
+// notModified menambahkan response 304 untuk GET bersyarat If-None-Match
+func notModified(responses map[string]*Response) map[string]*Response {
+	responses[strconv.Itoa(http.StatusNotModified)] = &Response{Description: "Not Modified"}
+	return responses
+}
+
+// scoped menerima sesi login atau bearer token; personal access token harus
+// memiliki scope tersebut
+func scoped(scope string) []map[string][]string {
+	return []map[string][]string{{"cookieAuth": {}}, {"bearerAuth": {scope}}}
+}
+
+func withCookies(sr statusResponse) statusResponse {
+	sr.response.Headers = map[string]*Header{
+		"Set-Cookie": {
+			Description: "`access_token` and `refresh_token` HttpOnly cookies",
+			Schema:      &Schema{Type: "string"},
+		},
+	}
+	return sr
+}
@@ -0,0 +1,18 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// NewEngine membuat engine Gin tanpa middleware bawaan (logging request
+// ditangani RequestLogger). Header X-Forwarded-For dan X-Real-IP hanya
+// dipercaya dari alamat di trustedProxies (IP atau CIDR); nil berarti
+// c.ClientIP() selalu alamat koneksi, sehingga allowlist IP API key dan
+// audit log tidak bisa dipalsukan lewat header.
+func NewEngine(trustedProxies []string) (*gin.Engine, error) {
+	engine := gin.New()
+	if err := engine.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, err
+	}
+	return engine, nil
+}
@@ -0,0 +1,81 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Hilmarch27/gin-api/internal/delivery/http/middleware"
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// allowlistServices menerima API key apa pun selama IP client ada di
+// allowlist, dan mencatat IP yang diperiksa
+type allowlistServices struct {
+	usecase.ServiceAccountUsecase
+
+	key  domain.APIKey
+	seen string
+}
+
+func (s *allowlistServices) Authenticate(_ context.Context, _, ip string) (*domain.ServiceAccount, *domain.APIKey, error) {
+	s.seen = ip
+	if !s.key.AllowsIP(ip) {
+		return nil, nil, domain.ErrIPNotAllowed
+	}
+	return &domain.ServiceAccount{ID: uuid.New()}, &s.key, nil
+}
+
+func TestEngineIgnoresSpoofedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		proxies []string
+		remote  string
+		want    int
+		wantIP  string
+	}{
+		{"no trusted proxy", nil, "203.0.113.7:4000", http.StatusForbidden, "203.0.113.7"},
+		{"untrusted sender", []string{"192.0.2.0/24"}, "203.0.113.7:4000", http.StatusForbidden, "203.0.113.7"},
+		{"trusted proxy", []string{"192.0.2.0/24"}, "192.0.2.10:4000", http.StatusNoContent, "10.0.0.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := NewEngine(tt.proxies)
+			if err != nil {
+				t.Fatal(err)
+			}
+			services := &allowlistServices{key: domain.APIKey{ID: uuid.New(), AllowedIPs: []string{"10.0.0.5"}}}
+			engine.Use(middleware.ErrorHandler())
+			engine.Use(middleware.AuthenticationMiddleware(nil, nil, services))
+			engine.GET("/", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remote
+			req.Header.Set("Authorization", "Bearer "+domain.APIKeyPrefix+"key_secret")
+			req.Header.Set("X-Forwarded-For", "10.0.0.5")
+			req.Header.Set("X-Real-IP", "10.0.0.5")
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			if services.seen != tt.wantIP {
+				t.Errorf("client IP = %q, want %q", services.seen, tt.wantIP)
+			}
+		})
+	}
+}
+
+func TestNewEngineRejectsInvalidProxy(t *testing.T) {
+	if _, err := NewEngine([]string{"not-an-ip"}); err == nil {
+		t.Fatal("expected error")
+	}
+}
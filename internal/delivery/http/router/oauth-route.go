@@ -0,0 +1,40 @@
+package router
+
+import (
+	"github.com/Hilmarch27/gin-api/internal/delivery/http/handler"
+	"github.com/Hilmarch27/gin-api/internal/delivery/http/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// OAuthRouter memasang endpoint OAuth 2.1 / OpenID Connect provider.
+// Dokumen discovery dan JWKS ada di root (/.well-known) dan dipasang
+// terpisah dari versi API.
+type OAuthRouter struct {
+	oauthHandler *handler.OAuthHandler
+}
+
+func NewOAuthRouter(oauthHandler *handler.OAuthHandler) *OAuthRouter {
+	return &OAuthRouter{
+		oauthHandler: oauthHandler,
+	}
+}
+
+func (r *OAuthRouter) Setup(rg *gin.RouterGroup) {
+	oauth := rg.Group("/oauth")
+	{
+		// Dibuka browser user; tanpa sesi login diarahkan ke halaman login
+		oauth.GET("/authorize", r.oauthHandler.Authorize)
+
+		// Consent screen hanya bisa disetujui dari sesi login
+		consent := oauth.Group("/consent", middleware.RequireCredentials(), middleware.RequireSession())
+		consent.GET("", r.oauthHandler.ConsentDetails)
+		consent.POST("", r.oauthHandler.Consent)
+
+		// Endpoint yang dipanggil client OAuth memakai format error OAuth
+		client := oauth.Group("", middleware.OAuthErrors())
+		client.POST("/token", r.oauthHandler.Token)
+		client.POST("/revoke", r.oauthHandler.Revoke)
+		client.GET("/userinfo", r.oauthHandler.UserInfo)
+		client.POST("/userinfo", r.oauthHandler.UserInfo)
+	}
+}
@@ -1,45 +1,207 @@
 package router
 
 import (
+	"time"
+
 	"github.com/Hilmarch27/gin-api/internal/delivery/http/handler"
 	"github.com/Hilmarch27/gin-api/internal/delivery/http/middleware"
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/pkg/i18n"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Route user lama (GET /api/users, PATCH dan DELETE /api/users/:id) dari
+// sebelum /api/users/me dan /api/admin/users, tetap dilayani sampai sunset
+var (
+	legacyUsersDeprecated = time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	legacyUsersSunset     = time.Date(2027, 4, 19, 0, 0, 0, 0, time.UTC)
 )
 
+func deprecatedUserRoute(successor string) middleware.RouteDeprecation {
+	return middleware.RouteDeprecation{
+		Deprecated: legacyUsersDeprecated,
+		Sunset:     legacyUsersSunset,
+		Successor:  successor,
+	}
+}
+
+// legacyUserHandler melayani route lama PATCH/DELETE /api/users/:id. Jika
+// :id adalah user yang sedang login, request diteruskan ke self (pengganti
+// /api/users/me), selain itu ke admin (pengganti /api/admin/users/:id)
+// setelah role admin diperiksa. Scope diperiksa di sini karena bergantung
+// pada handler yang dipilih.
+func legacyUserHandler(self, admin gin.HandlerFunc) gin.HandlerFunc {
+	selfRoute := deprecatedUserRoute("/api/users/me")
+	adminRoute := deprecatedUserRoute("/api/admin/users/:id")
+
+	return func(c *gin.Context) {
+		p, ok := middleware.CurrentPrincipal(c)
+		if !ok {
+			c.Error(domain.ErrUnauthorized)
+			return
+		}
+
+		if id, err := uuid.Parse(c.Param("id")); err == nil && p.IsUser() && id == p.ID {
+			selfRoute.SetHeaders(c)
+			if !p.HasScope(domain.ScopeProfileWrite) {
+				c.Error(domain.ErrInsufficientScope)
+				return
+			}
+			self(c)
+			return
+		}
+
+		adminRoute.SetHeaders(c)
+		if !p.IsAdmin() {
+			c.Error(domain.ErrAdminRequired)
+			return
+		}
+		if !p.HasScope(domain.ScopeAdminWrite) {
+			c.Error(domain.ErrInsufficientScope)
+			return
+		}
+		admin(c)
+	}
+}
+
 type ApiRouter struct {
-    authHandler *handler.AuthHandler
-	jwtSecret string
+	authHandler   *handler.AuthHandler
+	avatarHandler *handler.AvatarHandler
+	auditHandler  *handler.AuditHandler
+	orgHandler    *handler.OrganizationHandler
+	invHandler    *handler.InvitationHandler
+	tokenHandler  *handler.AccessTokenHandler
+	saHandler     *handler.ServiceAccountHandler
+	oauthHandler  *handler.OAuthHandler
+	ssoHandler    *handler.SSOHandler
+	pkHandler     *handler.PasskeyHandler
+	jwtSecret     string
 }
 
-func NewApiRouter(authHandler *handler.AuthHandler, jwtSecret string) *ApiRouter {
+func NewApiRouter(authHandler *handler.AuthHandler, avatarHandler *handler.AvatarHandler, auditHandler *handler.AuditHandler, orgHandler *handler.OrganizationHandler, invHandler *handler.InvitationHandler, tokenHandler *handler.AccessTokenHandler, saHandler *handler.ServiceAccountHandler, oauthHandler *handler.OAuthHandler, ssoHandler *handler.SSOHandler, pkHandler *handler.PasskeyHandler, jwtSecret string) *ApiRouter {
 	return &ApiRouter{
-        authHandler: authHandler,
-		jwtSecret: jwtSecret,
+		authHandler:   authHandler,
+		avatarHandler: avatarHandler,
+		auditHandler:  auditHandler,
+		orgHandler:    orgHandler,
+		invHandler:    invHandler,
+		tokenHandler:  tokenHandler,
+		saHandler:     saHandler,
+		oauthHandler:  oauthHandler,
+		ssoHandler:    ssoHandler,
+		pkHandler:     pkHandler,
+		jwtSecret:     jwtSecret,
 	}
 }
 
-func (r *ApiRouter) Setup(engine *gin.Engine) {
-    api := engine.Group("/api")
-    api.Use(middleware.RequireCredentials())
-    {   
-        users := api.Group("/users")
-        users.GET("", r.authHandler.GetUserByID)
-        users.PATCH("/:id", r.authHandler.Update)
-        users.DELETE("/:id", r.authHandler.Delete)
-    }
-    // Tambahkan route admin di sini
-    admin := api.Group("/admin")
-    admin.Use(middleware.RequireAdmin()) // Tambahkan middleware role admin
-    {
-        admin.GET("", func(c *gin.Context) {
-            c.JSON(200, gin.H{
-                "status": "success", 
-                "message": "Admin Dashboard",
-                "data": gin.H{
-                    "total_users": 100,
-                    "total_products": 50,
-                },
-            })
-        })
-    }
-}
\ No newline at end of file
+func (r *ApiRouter) Setup(rg *gin.RouterGroup) {
+	// Personal access token dan API key hanya boleh memakai route sesuai
+	// scope-nya; sesi login tidak dibatasi scope
+	profileRead := middleware.RequireScope(domain.ScopeProfileRead)
+	profileWrite := middleware.RequireScope(domain.ScopeProfileWrite)
+	orgsRead := middleware.RequireScope(domain.ScopeOrganizationsRead)
+	orgsWrite := middleware.RequireScope(domain.ScopeOrganizationsWrite)
+	adminRead := middleware.RequireScope(domain.ScopeAdminRead)
+	adminWrite := middleware.RequireScope(domain.ScopeAdminWrite)
+
+	api := rg.Group("/api")
+	api.Use(middleware.RequireCredentials())
+	{
+		// Profil user yang sedang login
+		me := api.Group("/users/me")
+		me.GET("", profileRead, r.authHandler.GetMe)
+		me.PATCH("", profileWrite, r.authHandler.UpdateMe)
+		me.DELETE("", profileWrite, r.authHandler.DeleteMe)
+		me.PUT("/avatar", profileWrite, r.avatarHandler.Upload)
+		me.DELETE("/avatar", profileWrite, r.avatarHandler.Delete)
+
+		// Personal access token hanya bisa dibuat dan dicabut dari sesi login
+		me.GET("/tokens", profileRead, r.tokenHandler.List)
+		me.POST("/tokens", middleware.RequireSession(), r.tokenHandler.Create)
+		me.DELETE("/tokens/:token_id", middleware.RequireSession(), r.tokenHandler.Revoke)
+
+		// Aplikasi OAuth yang sudah diberi akses oleh user
+		me.GET("/authorizations", profileRead, r.oauthHandler.ListGrants)
+		me.DELETE("/authorizations/:client_id", middleware.RequireSession(), r.oauthHandler.RevokeGrant)
+
+		// Identity provider SSO yang terhubung ke user
+		me.GET("/identities", profileRead, r.ssoHandler.ListIdentities)
+		me.DELETE("/identities/:identity_id", middleware.RequireSession(), r.ssoHandler.Unlink)
+
+		// Passkey (WebAuthn) hanya bisa didaftarkan dan diubah dari sesi login
+		me.GET("/passkeys", profileRead, r.pkHandler.List)
+		me.POST("/passkeys/options", middleware.RequireSession(), r.pkHandler.RegistrationOptions)
+		me.POST("/passkeys", middleware.RequireSession(), r.pkHandler.Register)
+		me.PATCH("/passkeys/:passkey_id", middleware.RequireSession(), r.pkHandler.Rename)
+		me.DELETE("/passkeys/:passkey_id", middleware.RequireSession(), r.pkHandler.Delete)
+
+		// Route lama, diteruskan ke /users/me atau /admin/users/:id
+		legacy := api.Group("/users")
+		legacy.GET("", middleware.DeprecatedRoute(deprecatedUserRoute("/api/users/me")), profileRead, r.authHandler.GetMe)
+		legacy.PATCH("/:id", legacyUserHandler(r.authHandler.UpdateMe, r.authHandler.Update))
+		legacy.DELETE("/:id", legacyUserHandler(r.authHandler.DeleteMe, r.authHandler.Delete))
+	}
+	{
+		// Organization (tenant) dan anggotanya. Keanggotaan pada :org sudah
+		// diperiksa middleware Tenant.
+		orgs := api.Group("/organizations")
+		orgs.GET("", orgsRead, r.orgHandler.List)
+		orgs.POST("", orgsWrite, r.orgHandler.Create)
+
+		org := orgs.Group("/:" + middleware.TenantParam)
+		org.GET("", orgsRead, r.orgHandler.Get)
+		org.POST("/switch", middleware.RequireSession(), r.orgHandler.Switch)
+		org.GET("/members", orgsRead, r.orgHandler.ListMembers)
+		org.DELETE("/members/:user_id", orgsWrite, r.orgHandler.RemoveMember)
+
+		orgAdmin := org.Group("", middleware.RequireOrgAdmin())
+		orgAdmin.POST("/members", orgsWrite, r.orgHandler.AddMember)
+		orgAdmin.PATCH("/members/:user_id", orgsWrite, r.orgHandler.UpdateMember)
+		orgAdmin.GET("/invitations", orgsRead, r.invHandler.List)
+		orgAdmin.POST("/invitations", orgsWrite, r.invHandler.Create)
+		orgAdmin.POST("/invitations/:invitation_id/resend", orgsWrite, r.invHandler.Resend)
+		orgAdmin.DELETE("/invitations/:invitation_id", orgsWrite, r.invHandler.Revoke)
+	}
+	// Tambahkan route admin di sini
+	admin := api.Group("/admin")
+	admin.Use(middleware.RequireAdmin()) // Tambahkan middleware role admin
+	{
+		admin.GET("", adminRead, func(c *gin.Context) {
+			c.JSON(200, gin.H{
+				"status":  "success",
+				"message": i18n.T(i18n.FromContext(c.Request.Context()), "admin_dashboard"),
+				"data": gin.H{
+					"total_users":    100,
+					"total_products": 50,
+				},
+			})
+		})
+		users := admin.Group("/users")
+		users.GET("", adminRead, r.authHandler.ListUsers)
+		users.GET("/:id", adminRead, r.authHandler.GetUser)
+		users.PATCH("/:id", adminWrite, r.authHandler.Update)
+		users.DELETE("/:id", adminWrite, r.authHandler.Delete)
+
+		admin.GET("/audit", adminRead, r.auditHandler.List)
+		admin.GET("/audit/verify", adminRead, r.auditHandler.Verify)
+
+		// Service account dan API key hanya bisa dikelola dari sesi login
+		sa := admin.Group("/service-accounts")
+		sa.GET("", adminRead, r.saHandler.List)
+		sa.POST("", middleware.RequireSession(), r.saHandler.Create)
+		sa.GET("/:service_account_id", adminRead, r.saHandler.Get)
+		sa.DELETE("/:service_account_id", middleware.RequireSession(), r.saHandler.Delete)
+		sa.GET("/:service_account_id/keys", adminRead, r.saHandler.ListKeys)
+		sa.POST("/:service_account_id/keys", middleware.RequireSession(), r.saHandler.CreateKey)
+		sa.POST("/:service_account_id/keys/:key_id/rotate", middleware.RequireSession(), r.saHandler.RotateKey)
+		sa.DELETE("/:service_account_id/keys/:key_id", middleware.RequireSession(), r.saHandler.RevokeKey)
+
+		// Registry client OAuth ("Log in with our account")
+		oc := admin.Group("/oauth-clients")
+		oc.GET("", adminRead, r.oauthHandler.ListClients)
+		oc.POST("", middleware.RequireSession(), r.oauthHandler.CreateClient)
+		oc.GET("/:client_id", adminRead, r.oauthHandler.GetClient)
+		oc.DELETE("/:client_id", middleware.RequireSession(), r.oauthHandler.DeleteClient)
+	}
+}
@@ -5,24 +5,50 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-type PublicRouter  struct {
-	authHandler *handler.AuthHandler
-	jwtSecret   string
+type PublicRouter struct {
+	authHandler       *handler.AuthHandler
+	invitationHandler *handler.InvitationHandler
+	ssoHandler        *handler.SSOHandler
+	passkeyHandler    *handler.PasskeyHandler
+	magicLinkHandler  *handler.MagicLinkHandler
+	jwtSecret         string
 }
 
-func NewPublicRouter(authHandler *handler.AuthHandler, jwtSecret string) *PublicRouter {
+func NewPublicRouter(authHandler *handler.AuthHandler, invitationHandler *handler.InvitationHandler, ssoHandler *handler.SSOHandler, passkeyHandler *handler.PasskeyHandler, magicLinkHandler *handler.MagicLinkHandler, jwtSecret string) *PublicRouter {
 	return &PublicRouter{
-		authHandler: authHandler,
-		jwtSecret:   jwtSecret,
+		authHandler:       authHandler,
+		invitationHandler: invitationHandler,
+		ssoHandler:        ssoHandler,
+		passkeyHandler:    passkeyHandler,
+		magicLinkHandler:  magicLinkHandler,
+		jwtSecret:         jwtSecret,
 	}
 }
 
-func (r *PublicRouter) Setup(engine *gin.Engine) {
+func (r *PublicRouter) Setup(rg *gin.RouterGroup) {
 	// Public routes
-	auth := engine.Group("/auth")
+	auth := rg.Group("/auth")
 	{
 		auth.POST("/register", r.authHandler.Register)
 		auth.POST("/login", r.authHandler.Login)
 		auth.POST("/refresh", r.authHandler.RefreshToken)
+
+		// Invitation bisa diterima tanpa login (akun lama atau sign-up)
+		auth.POST("/invitations/preview", r.invitationHandler.Preview)
+		auth.POST("/invitations/accept", r.invitationHandler.Accept)
+
+		// Login lewat identity provider OIDC eksternal
+		auth.GET("/sso", r.ssoHandler.Providers)
+		auth.GET("/sso/:provider", r.ssoHandler.Start)
+		auth.GET("/sso/:provider/callback", r.ssoHandler.Callback)
+
+		// Login tanpa password dengan passkey (WebAuthn)
+		auth.POST("/passkeys/options", r.passkeyHandler.LoginOptions)
+		auth.POST("/passkeys/login", r.passkeyHandler.Login)
+
+		// Login tanpa password lewat link di email. Verifikasi hanya lewat
+		// POST dari halaman frontend, tidak ada GET yang memakai token.
+		auth.POST("/magic-link", r.magicLinkHandler.Send)
+		auth.POST("/magic-link/verify", r.magicLinkHandler.Verify)
 	}
-}
\ No newline at end of file
+}
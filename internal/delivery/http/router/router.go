@@ -1,38 +1,114 @@
 package router
 
 import (
-    "github.com/gin-gonic/gin"
-    "github.com/Hilmarch27/gin-api/internal/delivery/http/middleware"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/delivery/http/middleware"
+	"github.com/Hilmarch27/gin-api/internal/delivery/http/openapi"
+	"github.com/Hilmarch27/gin-api/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 type Router struct {
-    engine     *gin.Engine
-    auth       *PublicRouter
-    api        *ApiRouter
-    jwtSecret  []byte
+	engine      *gin.Engine
+	versions    []Version
+	idempotency usecase.IdempotencyUsecase
+	orgs        usecase.OrganizationUsecase
+	tokens      usecase.AccessTokenUsecase
+	services    usecase.ServiceAccountUsecase
+	baseDomain  string
+	jwtSecret   []byte
+	dbTimeout   time.Duration
+	logger      *slog.Logger
+	serviceName string
 }
 
-func NewRouter(engine *gin.Engine, authRouter *PublicRouter, apiRouter *ApiRouter, jwtSecret []byte) *Router {
-    return &Router{
-        engine: engine,
-        auth:   authRouter,
-        api:    apiRouter,
-        jwtSecret: jwtSecret,
-    }
+// NewRouter membuat router utama. Versi pertama di versions adalah versi
+// yang didokumentasikan di /openapi.json. Idempotency boleh nil untuk
+// menonaktifkan dukungan header Idempotency-Key, dan orgs boleh nil untuk
+// menonaktifkan resolusi tenant. tokens dan services boleh nil untuk menolak
+// personal access token dan API key. baseDomain (misalnya example.com) mengaktifkan resolusi
+// tenant dari subdomain.
+func NewRouter(engine *gin.Engine, versions []Version, idempotency usecase.IdempotencyUsecase, orgs usecase.OrganizationUsecase, tokens usecase.AccessTokenUsecase, services usecase.ServiceAccountUsecase, baseDomain string, jwtSecret []byte, dbTimeout time.Duration, logger *slog.Logger, serviceName string) *Router {
+	return &Router{
+		engine:      engine,
+		versions:    versions,
+		idempotency: idempotency,
+		orgs:        orgs,
+		tokens:      tokens,
+		services:    services,
+		baseDomain:  baseDomain,
+		jwtSecret:   jwtSecret,
+		dbTimeout:   dbTimeout,
+		logger:      logger,
+		serviceName: serviceName,
+	}
 }
 
-func (r *Router) SetupRoutes() {
-    // Setup global middlewares
-    r.engine.Use(gin.Logger())
-    r.engine.Use(gin.Recovery())
-    
-    // Add authentication middleware globally
-    r.engine.Use(middleware.AuthenticationMiddleware(r.jwtSecret))
-
-    // Setup route groups
-    // Auth routes (public)
-    r.auth.Setup(r.engine)
-    
-    // API routes (private)
-    r.api.Setup(r.engine)
+// SetupRoutes memasang middleware dan semua route, lalu memastikan setiap
+// route punya entri di spesifikasi OpenAPI
+func (r *Router) SetupRoutes() error {
+	// Setup global middlewares
+	// Span HTTP dibuat paling awal (membaca header traceparent dari client)
+	r.engine.Use(otelgin.Middleware(r.serviceName))
+	r.engine.Use(middleware.RequestID())
+	r.engine.Use(middleware.RequestLogger(r.logger))
+	r.engine.Use(middleware.Metrics())
+	r.engine.Use(middleware.Recovery())
+
+	// Render error dari handler dan middleware sebagai problem+json
+	r.engine.Use(middleware.ErrorHandler())
+	r.engine.NoRoute(middleware.NoRoute)
+
+	// Batasi waktu request agar query lambat tidak menahan handler
+	r.engine.Use(middleware.RequestTimeout(r.dbTimeout))
+
+	// Tentukan bahasa response (en/id)
+	r.engine.Use(middleware.Locale())
+
+	// Add authentication middleware globally (JWT, personal access token
+	// atau API key)
+	r.engine.Use(middleware.AuthenticationMiddleware(r.jwtSecret, r.tokens, r.services))
+
+	// Organization aktif (tenant) dari path, header, subdomain atau token
+	if r.orgs != nil {
+		r.engine.Use(middleware.Tenant(r.orgs, r.baseDomain))
+	}
+
+	// Actor, IP dan request ID untuk audit log
+	r.engine.Use(middleware.RequestMeta())
+
+	// Replay response POST/PATCH/DELETE yang diulang dengan Idempotency-Key
+	if r.idempotency != nil {
+		r.engine.Use(middleware.Idempotency(r.idempotency))
+	}
+
+	// Setup route groups per versi API (misalnya /v1/auth, /v1/api)
+	for _, v := range r.versions {
+		group := r.engine.Group(v.Prefix, middleware.APIVersion(middleware.VersionInfo{
+			Name:       v.Name,
+			Prefix:     v.Prefix,
+			Deprecated: v.Deprecated,
+			Sunset:     v.Sunset,
+			Successor:  v.Successor,
+		}))
+		for _, routes := range v.Routes {
+			routes.Setup(group)
+		}
+	}
+
+	if len(r.versions) == 0 {
+		return errors.New("router: no API version configured")
+	}
+	current := r.versions[0]
+
+	// Dokumentasi API, path di spec relatif terhadap prefix versi
+	doc := openapi.Build(current.Prefix)
+	r.engine.GET("/openapi.json", openapi.SpecHandler(doc))
+	r.engine.GET("/docs", openapi.DocsHandler)
+
+	return openapi.Check(doc, r.engine.Routes(), current.Prefix)
 }
@@ -0,0 +1,30 @@
+package router
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RouteGroup adalah sekumpulan route yang dipasang di bawah satu versi API
+type RouteGroup interface {
+	Setup(rg *gin.RouterGroup)
+}
+
+// Version mendeskripsikan satu versi API. Setiap versi punya RouteGroup
+// sendiri, sehingga handler v1 dan v2 bisa berjalan berdampingan.
+type Version struct {
+	Name   string // label metrik dan header, misalnya "v1"
+	Prefix string // prefix path, misalnya "/v1"; kosong untuk route lama tanpa versi
+
+	// Deprecated dan Sunset (zero berarti tidak diset) dikirim sebagai
+	// header Deprecation dan Sunset pada setiap response versi ini
+	Deprecated time.Time
+	Sunset     time.Time
+
+	// Successor adalah prefix versi pengganti, dipakai untuk header
+	// Link rel="successor-version"
+	Successor string
+
+	Routes []RouteGroup
+}
@@ -0,0 +1,75 @@
+// Package validation mengatur validator gin (tag binding) yang dipakai
+// handler dan middleware
+package validation
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// Setup membuat validator gin melaporkan nama field JSON (misalnya
+// "email") alih-alih nama field struct ("Email") dan mendaftarkan rule
+// tambahan
+func Setup() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return f.Name
+		}
+		return name
+	})
+	v.RegisterValidation("slug", func(fl validator.FieldLevel) bool {
+		return domain.ValidSlug(fl.Field().String())
+	})
+	v.RegisterValidation("scope", func(fl validator.FieldLevel) bool {
+		return domain.ValidScope(fl.Field().String())
+	})
+	v.RegisterValidation("oauth_scope", func(fl validator.FieldLevel) bool {
+		return domain.ValidOAuthScope(fl.Field().String())
+	})
+	v.RegisterValidation("ip_prefix", func(fl validator.FieldLevel) bool {
+		_, err := domain.ParseIPPrefix(fl.Field().String())
+		return err == nil
+	})
+}
+
+// Fields memvalidasi tag binding pada struct v, hanya untuk field dengan
+// nama JSON di fields. Dipakai untuk dokumen PATCH agar data lama yang
+// tidak disentuh patch tidak ikut divalidasi.
+func Fields(v any, fields ...string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	engine, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return binding.Validator.ValidateStruct(v)
+	}
+
+	// StructPartial memakai nama field Go, bukan nama JSON
+	wanted := map[string]bool{}
+	for _, f := range fields {
+		wanted[f] = true
+	}
+	t := reflect.Indirect(reflect.ValueOf(v)).Type()
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		if wanted[strings.SplitN(t.Field(i).Tag.Get("json"), ",", 2)[0]] {
+			names = append(names, t.Field(i).Name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return engine.StructPartial(v, names...)
+}
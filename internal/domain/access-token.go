@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AccessTokenPrefix menandai personal access token sehingga bisa dibedakan
+// dari JWT di header Authorization (dan mudah dikenali secret scanner)
+const AccessTokenPrefix = "gpat_"
+
+// Scope personal access token. Sesi login (cookie JWT) tidak dibatasi scope.
+const (
+	ScopeProfileRead        = "profile:read"
+	ScopeProfileWrite       = "profile:write"
+	ScopeOrganizationsRead  = "organizations:read"
+	ScopeOrganizationsWrite = "organizations:write"
+	ScopeAdminRead          = "admin:read"
+	ScopeAdminWrite         = "admin:write"
+)
+
+// AccessTokenScopes adalah semua scope yang bisa dipilih
+var AccessTokenScopes = []string{
+	ScopeProfileRead,
+	ScopeProfileWrite,
+	ScopeOrganizationsRead,
+	ScopeOrganizationsWrite,
+	ScopeAdminRead,
+	ScopeAdminWrite,
+}
+
+// ValidScope melaporkan apakah scope dikenal
+func ValidScope(scope string) bool {
+	return slices.Contains(AccessTokenScopes, scope)
+}
+
+// AdminScope melaporkan apakah scope hanya boleh dimiliki admin
+func AdminScope(scope string) bool {
+	return strings.HasPrefix(scope, "admin:")
+}
+
+// IsAccessToken melaporkan apakah credential berformat personal access token
+func IsAccessToken(credential string) bool {
+	return strings.HasPrefix(credential, AccessTokenPrefix)
+}
+
+// PersonalAccessToken adalah token milik user untuk script dan CI. Token
+// hanya ditampilkan sekali saat dibuat; yang disimpan hanya hash SHA-256
+// dan Prefix (awal token) untuk dikenali di daftar token.
+type PersonalAccessToken struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key"`
+	UserID     uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Name       string     `gorm:"size:100;not null"`
+	Prefix     string     `gorm:"size:16;not null"`
+	TokenHash  string     `gorm:"size:64;not null;uniqueIndex"`
+	Scopes     []string   `gorm:"type:jsonb;serializer:json;not null"`
+	ExpiresAt  *time.Time `gorm:""`
+	LastUsedAt *time.Time `gorm:""`
+	LastUsedIP string     `gorm:"size:45"`
+	RevokedAt  *time.Time `gorm:""`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+
+	User *User `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+func (t *PersonalAccessToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// Active melaporkan apakah token belum dicabut dan belum kedaluwarsa
+func (t *PersonalAccessToken) Active(now time.Time) bool {
+	return t.RevokedAt == nil && (t.ExpiresAt == nil || now.Before(*t.ExpiresAt))
+}
+
+// CreateAccessTokenRequest membuat personal access token. ExpiresAt kosong
+// berarti token tidak kedaluwarsa.
+type CreateAccessTokenRequest struct {
+	Name      string     `json:"name" binding:"required,max=100"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1,dive,scope"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type AccessTokenResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP string     `json:"last_used_ip,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func NewAccessTokenResponse(t *PersonalAccessToken) *AccessTokenResponse {
+	return &AccessTokenResponse{
+		ID:         t.ID,
+		Name:       t.Name,
+		Prefix:     t.Prefix,
+		Scopes:     t.Scopes,
+		ExpiresAt:  t.ExpiresAt,
+		LastUsedAt: t.LastUsedAt,
+		LastUsedIP: t.LastUsedIP,
+		CreatedAt:  t.CreatedAt,
+	}
+}
+
+// CreatedAccessTokenResponse berisi token utuh, hanya dikirim sekali
+type CreatedAccessTokenResponse struct {
+	AccessTokenResponse
+	Token string `json:"token"`
+}
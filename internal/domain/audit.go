@@ -0,0 +1,239 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Aksi yang dicatat di audit log
+const (
+	AuditLogin          = "auth.login"
+	AuditLoginFailed    = "auth.login_failed"
+	AuditAccountLocked  = "auth.account_locked"
+	AuditTokenRefreshed = "auth.token_refreshed"
+	AuditUserRegistered = "user.registered"
+	AuditUserUpdated    = "user.updated"
+	AuditRoleChanged    = "user.role_changed"
+	AuditUserDeleted    = "user.deleted"
+
+	AuditAccessTokenCreated = "auth.access_token_created"
+	AuditAccessTokenRevoked = "auth.access_token_revoked"
+
+	AuditServiceAccountCreated = "service_account.created"
+	AuditServiceAccountDeleted = "service_account.deleted"
+	AuditAPIKeyCreated         = "service_account.api_key_created"
+	AuditAPIKeyRotated         = "service_account.api_key_rotated"
+	AuditAPIKeyRevoked         = "service_account.api_key_revoked"
+
+	AuditOAuthClientCreated      = "oauth.client_created"
+	AuditOAuthClientDeleted      = "oauth.client_deleted"
+	AuditOAuthConsentGranted     = "oauth.consent_granted"
+	AuditOAuthConsentRevoked     = "oauth.consent_revoked"
+	AuditOAuthCodeReused         = "oauth.authorization_code_reused"
+	AuditOAuthRefreshTokenReused = "oauth.refresh_token_reused"
+
+	AuditIdentityLinked   = "auth.identity_linked"
+	AuditIdentityUnlinked = "auth.identity_unlinked"
+
+	AuditPasskeyRegistered    = "auth.passkey_registered"
+	AuditPasskeyRemoved       = "auth.passkey_removed"
+	AuditPasskeyCloneDetected = "auth.passkey_clone_detected"
+
+	AuditMagicLinkSent = "auth.magic_link_sent"
+
+	AuditOrganizationCreated = "organization.created"
+	AuditMemberAdded         = "organization.member_added"
+	AuditMemberRoleChanged   = "organization.member_role_changed"
+	AuditMemberRemoved       = "organization.member_removed"
+	AuditInvitationCreated   = "organization.invitation_created"
+	AuditInvitationResent    = "organization.invitation_resent"
+	AuditInvitationRevoked   = "organization.invitation_revoked"
+	AuditInvitationAccepted  = "organization.invitation_accepted"
+)
+
+// Cara login yang dicatat di metadata audit auth.login. Login SSO memakai
+// "sso:<nama provider>".
+const (
+	LoginMethodPassword  = "password"
+	LoginMethodSSO       = "sso:"
+	LoginMethodPasskey   = "passkey"
+	LoginMethodMagicLink = "magic_link"
+)
+
+// AuditLog adalah satu entri audit yang append-only. Setiap entri menyimpan
+// hash entri sebelumnya di rantai yang sama (PrevHash) sehingga perubahan
+// atau penghapusan entri lama akan memutus rantai hash dan terdeteksi saat
+// verifikasi. Entri dibagi ke beberapa rantai (Chain) agar penulisan audit
+// tidak diserialisasi secara global; Seq tetap urutan global.
+type AuditLog struct {
+	ID         uuid.UUID     `gorm:"type:uuid;primary_key" json:"id"`
+	Seq        int64         `gorm:"uniqueIndex;not null;index:idx_audit_logs_chain_seq,priority:2" json:"seq"`
+	Chain      int           `gorm:"not null;default:0;index:idx_audit_logs_chain_seq,priority:1" json:"chain"`
+	OccurredAt time.Time     `gorm:"index;not null" json:"occurred_at"`
+	ActorID    *uuid.UUID    `gorm:"type:uuid;index" json:"actor_id,omitempty"`
+	ActorType  string        `gorm:"size:16" json:"actor_type,omitempty"` // Jenis principal (user/service)
+	TargetID   *uuid.UUID    `gorm:"type:uuid;index" json:"target_id,omitempty"`
+	Action     string        `gorm:"index;not null" json:"action"`
+	IP         string        `json:"ip,omitempty"`
+	UserAgent  string        `json:"user_agent,omitempty"`
+	RequestID  string        `gorm:"index" json:"request_id,omitempty"`
+	Changes    AuditChanges  `gorm:"type:jsonb" json:"changes,omitempty"`
+	Metadata   AuditMetadata `gorm:"type:jsonb" json:"metadata,omitempty"`
+	PrevHash   string        `gorm:"not null" json:"prev_hash"`
+	Hash       string        `gorm:"uniqueIndex;not null" json:"hash"`
+}
+
+// FieldChange menyimpan nilai field sebelum dan sesudah perubahan
+type FieldChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// AuditChanges adalah diff before/after per field, disimpan sebagai jsonb
+type AuditChanges map[string]FieldChange
+
+func (c AuditChanges) Value() (driver.Value, error) { return jsonValue(c) }
+func (c *AuditChanges) Scan(src any) error          { return jsonScan(src, c) }
+
+// AuditMetadata berisi konteks tambahan, misalnya email pada login gagal
+type AuditMetadata map[string]string
+
+func (m AuditMetadata) Value() (driver.Value, error) { return jsonValue(m) }
+func (m *AuditMetadata) Scan(src any) error          { return jsonScan(src, m) }
+
+func jsonValue[T ~map[string]V, V any](v T) (driver.Value, error) {
+	if len(v) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func jsonScan(src any, dest any) error {
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, dest)
+	case string:
+		return json.Unmarshal([]byte(v), dest)
+	default:
+		return errors.New("unsupported jsonb source type")
+	}
+}
+
+func (a *AuditLog) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// AuditFilter adalah filter untuk query audit log. BeforeSeq dipakai
+// sebagai cursor pagination (entri terbaru lebih dulu).
+type AuditFilter struct {
+	ActorID   *uuid.UUID
+	TargetID  *uuid.UUID
+	Action    string
+	From      *time.Time
+	To        *time.Time
+	BeforeSeq int64
+	Limit     int
+}
+
+// AuditCheckpoint adalah entri terakhir yang sudah diverifikasi di satu
+// rantai, sehingga verifikasi berikutnya hanya memeriksa entri baru
+type AuditCheckpoint struct {
+	Chain      int       `gorm:"primaryKey;autoIncrement:false"`
+	Seq        int64     `gorm:"not null"`
+	Hash       string    `gorm:"not null"`
+	VerifiedAt time.Time `gorm:"not null"`
+}
+
+// AuditVerification adalah hasil verifikasi rantai hash audit log. Checked
+// adalah jumlah entri yang baru diverifikasi pada pemeriksaan ini dan
+// LastHash hash entri terbaru yang sudah terverifikasi.
+type AuditVerification struct {
+	Valid    bool   `json:"valid"`
+	Checked  int64  `json:"checked"`
+	BrokenAt *int64 `json:"broken_at,omitempty"`
+	LastHash string `json:"last_hash,omitempty"`
+}
+
+// RequestMeta adalah informasi request yang ikut dicatat di audit log
+type RequestMeta struct {
+	ActorID   *uuid.UUID
+	ActorType string
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+type requestMetaKey struct{}
+
+func WithRequestMeta(ctx context.Context, meta RequestMeta) context.Context {
+	return context.WithValue(ctx, requestMetaKey{}, meta)
+}
+
+func RequestMetaFrom(ctx context.Context) RequestMeta {
+	meta, _ := ctx.Value(requestMetaKey{}).(RequestMeta)
+	return meta
+}
+
+// ComputeHash menghitung hash SHA-256 entri berdasarkan seluruh isinya
+// dan PrevHash. Field dihash dalam urutan tetap agar hasilnya stabil.
+func (a *AuditLog) ComputeHash() string {
+	payload := struct {
+		Seq        int64         `json:"seq"`
+		Chain      int           `json:"chain,omitempty"` // Entri lama (rantai 0) tetap cocok
+		OccurredAt string        `json:"occurred_at"`
+		ActorID    *uuid.UUID    `json:"actor_id"`
+		ActorType  string        `json:"actor_type,omitempty"` // Entri lama tanpa actor_type tetap cocok
+		TargetID   *uuid.UUID    `json:"target_id"`
+		Action     string        `json:"action"`
+		IP         string        `json:"ip"`
+		UserAgent  string        `json:"user_agent"`
+		RequestID  string        `json:"request_id"`
+		Changes    AuditChanges  `json:"changes"`
+		Metadata   AuditMetadata `json:"metadata"`
+		PrevHash   string        `json:"prev_hash"`
+	}{
+		Seq:        a.Seq,
+		Chain:      a.Chain,
+		OccurredAt: a.OccurredAt.UTC().Format(time.RFC3339Nano),
+		ActorID:    a.ActorID,
+		ActorType:  a.ActorType,
+		TargetID:   a.TargetID,
+		Action:     a.Action,
+		IP:         a.IP,
+		UserAgent:  a.UserAgent,
+		RequestID:  a.RequestID,
+		Changes:    a.Changes,
+		Metadata:   a.Metadata,
+		PrevHash:   a.PrevHash,
+	}
+
+	// Map kosong disimpan sebagai NULL, samakan agar hash tetap cocok
+	if len(payload.Changes) == 0 {
+		payload.Changes = nil
+	}
+	if len(payload.Metadata) == 0 {
+		payload.Metadata = nil
+	}
+
+	// json.Marshal mengurutkan key map sehingga hasilnya deterministik
+	b, _ := json.Marshal(payload)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
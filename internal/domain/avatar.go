@@ -0,0 +1,35 @@
+package domain
+
+import "database/sql/driver"
+
+// Nama varian avatar, dari yang terbesar
+const (
+	AvatarLarge  = "large"
+	AvatarMedium = "medium"
+	AvatarSmall  = "small"
+)
+
+// AvatarVariant adalah satu ukuran avatar yang tersimpan di storage
+type AvatarVariant struct {
+	Key  string `json:"key"`
+	URL  string `json:"url"`
+	Size int    `json:"size"` // lebar dan tinggi dalam pixel
+}
+
+// AvatarVariants memetakan nama varian ke object di storage (kolom jsonb)
+type AvatarVariants map[string]AvatarVariant
+
+func (v AvatarVariants) Value() (driver.Value, error) { return jsonValue(v) }
+func (v *AvatarVariants) Scan(src any) error          { return jsonScan(src, v) }
+
+// URLs mengembalikan nama varian ke URL untuk response
+func (v AvatarVariants) URLs() map[string]string {
+	if len(v) == 0 {
+		return nil
+	}
+	urls := make(map[string]string, len(v))
+	for name, variant := range v {
+		urls[name] = variant.URL
+	}
+	return urls
+}
@@ -0,0 +1,162 @@
+package domain
+
+import "errors"
+
+// ErrorKind mengelompokkan error domain, dipakai delivery layer untuk
+// menentukan status code tanpa perlu tahu detail tiap error
+type ErrorKind int
+
+const (
+	KindInternal ErrorKind = iota
+	KindNotFound
+	KindConflict
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindRateLimited
+	KindTooLarge
+	KindUnsupportedMedia
+	KindPreconditionFailed
+	KindPreconditionRequired
+	KindUnprocessable
+)
+
+// Error adalah error domain yang aman ditampilkan ke client.
+// Err menyimpan penyebab asli (misalnya error database) untuk logging.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	Err     error
+}
+
+func NewError(kind ErrorKind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is membuat errors.Is(err, ErrUserNotFound) tetap true walaupun error sudah di-Wrap
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Wrap mengembalikan salinan error dengan penyebab asli
+func (e *Error) Wrap(err error) *Error {
+	wrapped := *e
+	wrapped.Err = err
+	return &wrapped
+}
+
+// KindOf mengembalikan ErrorKind dari err, KindInternal jika bukan error domain
+func KindOf(err error) ErrorKind {
+	var de *Error
+	if errors.As(err, &de) {
+		return de.Kind
+	}
+	return KindInternal
+}
+
+var (
+	ErrInvalidInput                = NewError(KindValidation, "invalid_input", "invalid input")
+	ErrInvalidUserID               = NewError(KindValidation, "invalid_user_id", "invalid user ID")
+	ErrUserNotFound                = NewError(KindNotFound, "user_not_found", "user not found")
+	ErrEmailTaken                  = NewError(KindConflict, "email_taken", "email is already registered")
+	ErrInvalidCredentials          = NewError(KindUnauthorized, "invalid_credentials", "invalid credentials")
+	ErrInvalidPassword             = NewError(KindForbidden, "invalid_password", "password confirmation failed")
+	ErrInvalidRefreshToken         = NewError(KindUnauthorized, "invalid_refresh_token", "invalid refresh token")
+	ErrUnauthorized                = NewError(KindUnauthorized, "unauthorized", "unauthorized")
+	ErrForbidden                   = NewError(KindForbidden, "forbidden", "forbidden")
+	ErrAdminRequired               = NewError(KindForbidden, "admin_required", "admin access required")
+	ErrRateLimited                 = NewError(KindRateLimited, "rate_limited", "too many requests")
+	ErrAccountLocked               = NewError(KindRateLimited, "account_locked", "account is temporarily locked after too many failed logins")
+	ErrFileRequired                = NewError(KindValidation, "file_required", "file is required")
+	ErrFileTooLarge                = NewError(KindTooLarge, "file_too_large", "file is too large")
+	ErrUnsupportedImage            = NewError(KindUnsupportedMedia, "unsupported_image", "unsupported image type")
+	ErrInvalidImage                = NewError(KindValidation, "invalid_image", "image could not be decoded")
+	ErrInvalidPatch                = NewError(KindValidation, "invalid_patch", "patch document is invalid")
+	ErrPatchTestFailed             = NewError(KindConflict, "patch_test_failed", "patch test operation failed")
+	ErrUnsupportedPatch            = NewError(KindUnsupportedMedia, "unsupported_patch_type", "unsupported patch content type")
+	ErrFieldNotWritable            = NewError(KindForbidden, "field_not_writable", "field cannot be changed by the caller")
+	ErrPreconditionFailed          = NewError(KindPreconditionFailed, "precondition_failed", "resource version does not match If-Match")
+	ErrPreconditionRequired        = NewError(KindPreconditionRequired, "precondition_required", "If-Match header is required")
+	ErrUserModified                = NewError(KindConflict, "concurrent_modification", "user was modified concurrently")
+	ErrIdempotencyKey              = NewError(KindValidation, "invalid_idempotency_key", "Idempotency-Key must be 1-255 printable characters")
+	ErrIdempotencyReused           = NewError(KindUnprocessable, "idempotency_key_reused", "Idempotency-Key was already used with a different request")
+	ErrIdempotencyInFlight         = NewError(KindConflict, "idempotency_in_flight", "a request with this Idempotency-Key is still being processed")
+	ErrRequestTooLarge             = NewError(KindTooLarge, "request_too_large", "request body is too large")
+	ErrOrganizationNotFound        = NewError(KindNotFound, "organization_not_found", "organization not found")
+	ErrSlugTaken                   = NewError(KindConflict, "slug_taken", "organization slug is already taken")
+	ErrTenantMismatch              = NewError(KindValidation, "tenant_mismatch", "request refers to more than one organization")
+	ErrNotMember                   = NewError(KindForbidden, "not_member", "not a member of the organization")
+	ErrOrgAdminRequired            = NewError(KindForbidden, "org_admin_required", "organization owner or admin role required")
+	ErrOwnerRequired               = NewError(KindForbidden, "owner_required", "organization owner role required")
+	ErrMemberExists                = NewError(KindConflict, "member_exists", "user is already a member of the organization")
+	ErrMemberNotFound              = NewError(KindNotFound, "member_not_found", "membership not found")
+	ErrLastOwner                   = NewError(KindConflict, "last_owner", "organization must keep at least one owner")
+	ErrInvitationNotFound          = NewError(KindNotFound, "invitation_not_found", "invitation not found")
+	ErrInvalidInvitationID         = NewError(KindValidation, "invalid_invitation_id", "invalid invitation ID")
+	ErrInvitationPending           = NewError(KindConflict, "invitation_pending", "a pending invitation for this email already exists")
+	ErrInvalidInvitation           = NewError(KindValidation, "invalid_invitation", "invitation token is invalid or has been replaced")
+	ErrInvitationExpired           = NewError(KindConflict, "invitation_expired", "invitation has expired")
+	ErrInvitationClosed            = NewError(KindConflict, "invitation_closed", "invitation was already accepted or revoked")
+	ErrInvitationEmail             = NewError(KindForbidden, "invitation_email_mismatch", "invitation was sent to a different email")
+	ErrSignupDetails               = NewError(KindValidation, "signup_details_required", "name and password are required to create an account")
+	ErrAccessTokenNotFound         = NewError(KindNotFound, "access_token_not_found", "access token not found")
+	ErrInvalidAccessTokenID        = NewError(KindValidation, "invalid_access_token_id", "invalid access token ID")
+	ErrInvalidExpiry               = NewError(KindValidation, "invalid_expiry", "expiry must be in the future")
+	ErrScopeNotAllowed             = NewError(KindForbidden, "scope_not_allowed", "admin scopes require the admin role")
+	ErrInsufficientScope           = NewError(KindForbidden, "insufficient_scope", "access token or API key lacks the required scope")
+	ErrSessionRequired             = NewError(KindForbidden, "session_required", "this action requires a login session, not an access token or API key")
+	ErrUserRequired                = NewError(KindForbidden, "user_required", "this action is only available to users, not service accounts")
+	ErrServiceAccountNotFound      = NewError(KindNotFound, "service_account_not_found", "service account not found")
+	ErrInvalidServiceAccountID     = NewError(KindValidation, "invalid_service_account_id", "invalid service account ID")
+	ErrAPIKeyNotFound              = NewError(KindNotFound, "api_key_not_found", "API key not found")
+	ErrInvalidAPIKeyID             = NewError(KindValidation, "invalid_api_key_id", "invalid API key ID")
+	ErrIPNotAllowed                = NewError(KindForbidden, "ip_not_allowed", "API key is not allowed from this IP address")
+	ErrOAuthClientNotFound         = NewError(KindNotFound, "oauth_client_not_found", "OAuth client not found")
+	ErrInvalidOAuthClientID        = NewError(KindValidation, "invalid_oauth_client_id", "invalid OAuth client ID")
+	ErrUnknownOAuthClient          = NewError(KindValidation, "unknown_oauth_client", "client_id is not a registered OAuth client")
+	ErrInvalidRedirectURI          = NewError(KindValidation, "invalid_redirect_uri", "redirect_uri is not registered for the client")
+	ErrOAuthGrantNotFound          = NewError(KindNotFound, "oauth_grant_not_found", "application authorization not found")
+	ErrInvalidAuthorizationRequest = NewError(KindValidation, "invalid_authorization_request", "authorization request is invalid or has expired")
+	ErrPKCERequired                = NewError(KindValidation, "pkce_required", "code_challenge with method S256 is required")
+	ErrUnsupportedResponseType     = NewError(KindValidation, "unsupported_response_type", "only response_type=code is supported")
+	ErrUnsupportedGrantType        = NewError(KindValidation, "unsupported_grant_type", "grant type is not supported")
+	ErrUnauthorizedClient          = NewError(KindForbidden, "unauthorized_client", "client is not allowed to use this grant type")
+	ErrInvalidScope                = NewError(KindValidation, "invalid_scope", "requested scope is invalid or not allowed for the client")
+	ErrInvalidClient               = NewError(KindUnauthorized, "invalid_client", "client authentication failed")
+	ErrInvalidGrant                = NewError(KindValidation, "invalid_grant", "authorization grant is invalid, expired or revoked")
+	ErrAccessDenied                = NewError(KindForbidden, "access_denied", "the user denied the authorization request")
+	ErrLoginRequired               = NewError(KindUnauthorized, "login_required", "the user is not logged in")
+	ErrConsentRequired             = NewError(KindForbidden, "consent_required", "the user has not approved the requested scopes")
+	ErrInvalidToken                = NewError(KindUnauthorized, "invalid_token", "access token is invalid or expired")
+	ErrSSOProviderNotFound         = NewError(KindNotFound, "sso_provider_not_found", "SSO provider not found")
+	ErrInvalidSSOState             = NewError(KindValidation, "invalid_sso_state", "SSO login session is invalid or has expired")
+	ErrSSOFailed                   = NewError(KindUnauthorized, "sso_failed", "the identity provider did not complete the login")
+	ErrSSOEmailRequired            = NewError(KindForbidden, "sso_email_required", "the identity provider did not return an email address")
+	ErrSSOEmailNotVerified         = NewError(KindForbidden, "sso_email_not_verified", "the identity provider has not verified the email address")
+	ErrSSOEmailDomain              = NewError(KindForbidden, "sso_email_domain_not_allowed", "the email domain is not allowed for this identity provider")
+	ErrSSOAccountNotFound          = NewError(KindForbidden, "sso_account_not_found", "no account exists for this identity")
+	ErrSSOLinkRequired             = NewError(KindConflict, "sso_link_required", "an account with this email already exists; sign in and link the identity provider from the account")
+	ErrIdentityLinked              = NewError(KindConflict, "identity_already_linked", "the identity is already linked to another account")
+	ErrIdentityNotFound            = NewError(KindNotFound, "identity_not_found", "linked identity not found")
+	ErrInvalidIdentityID           = NewError(KindValidation, "invalid_identity_id", "invalid identity ID")
+	ErrLastLoginMethod             = NewError(KindConflict, "last_login_method", "the account must keep at least one way to log in")
+	ErrPasskeyNotFound             = NewError(KindNotFound, "passkey_not_found", "passkey not found")
+	ErrInvalidPasskeyID            = NewError(KindValidation, "invalid_passkey_id", "invalid passkey ID")
+	ErrInvalidPasskeySession       = NewError(KindValidation, "invalid_passkey_session", "passkey request is invalid or has expired")
+	ErrPasskeyRegistrationFailed   = NewError(KindValidation, "passkey_registration_failed", "the passkey could not be verified")
+	ErrPasskeyExists               = NewError(KindConflict, "passkey_already_registered", "the passkey is already registered")
+	ErrInvalidMagicLink            = NewError(KindUnauthorized, "invalid_magic_link", "login link is invalid, expired or already used")
+)
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"time"
+)
+
+// Status record idempotency
+const (
+	IdempotencyInProgress = "in_progress"
+	IdempotencyCompleted  = "completed"
+)
+
+// IdempotencyRecord menyimpan hasil request yang dikirim dengan header
+// Idempotency-Key. Key berlaku per Scope (user ID, atau "anonymous:<hash IP>"
+// untuk request anonim) sehingga client berbeda tidak bisa memakai response
+// milik client lain.
+type IdempotencyRecord struct {
+	Scope       string             `gorm:"primaryKey;size:64"`
+	Key         string             `gorm:"primaryKey;size:255"`
+	Method      string             `gorm:"size:10;not null"`
+	Path        string             `gorm:"not null"`
+	Fingerprint string             `gorm:"size:64;not null"` // SHA-256 method, path dan body
+	State       string             `gorm:"size:16;not null"`
+	StatusCode  int                `gorm:"not null;default:0"`
+	Headers     IdempotencyHeaders `gorm:"type:jsonb"`
+	Body        []byte             `gorm:"type:bytea"`
+	CreatedAt   time.Time          `gorm:"not null"`
+	ExpiresAt   time.Time          `gorm:"index;not null"`
+}
+
+// IdempotencyHeaders adalah header response yang ikut di-replay
+type IdempotencyHeaders map[string]string
+
+func (h IdempotencyHeaders) Value() (driver.Value, error) { return jsonValue(h) }
+func (h *IdempotencyHeaders) Scan(src any) error          { return jsonScan(src, h) }
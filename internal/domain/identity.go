@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// FederatedIdentity menghubungkan user dengan akun di identity provider
+// OIDC eksternal. Subject adalah claim sub dari provider, unik per
+// provider.
+type FederatedIdentity struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Provider    string     `gorm:"size:50;not null;uniqueIndex:idx_federated_identities_subject"`
+	Subject     string     `gorm:"size:255;not null;uniqueIndex:idx_federated_identities_subject"`
+	Email       string     `gorm:"size:255"`
+	LastLoginAt *time.Time `gorm:""`
+	CreatedAt   time.Time
+
+	User *User `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+func (i *FederatedIdentity) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// SSOStartRequest adalah query untuk memulai login SSO. ReturnTo adalah
+// URL frontend (atau URL authorize OAuth) tujuan setelah login. Link
+// menghubungkan provider ke user yang sedang login alih-alih login.
+type SSOStartRequest struct {
+	ReturnTo string `form:"return_to"`
+	Link     bool   `form:"link"`
+}
+
+// SSOCallbackRequest adalah query yang dikirim identity provider ke callback
+type SSOCallbackRequest struct {
+	Code             string `form:"code"`
+	State            string `form:"state"`
+	Error            string `form:"error"`
+	ErrorDescription string `form:"error_description"`
+}
+
+// SSOResult adalah hasil callback SSO yang berhasil
+type SSOResult struct {
+	User     *User
+	ReturnTo string
+
+	// Linked berarti provider dihubungkan ke user yang sudah login;
+	// tidak perlu menerbitkan sesi baru
+	Linked bool
+}
+
+type SSOProviderResponse struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+type IdentityResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	Provider    string     `json:"provider"`
+	Email       string     `json:"email,omitempty"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func NewIdentityResponse(i *FederatedIdentity) *IdentityResponse {
+	return &IdentityResponse{
+		ID:          i.ID,
+		Provider:    i.Provider,
+		Email:       i.Email,
+		LastLoginAt: i.LastLoginAt,
+		CreatedAt:   i.CreatedAt,
+	}
+}
@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Status invitation, diturunkan dari AcceptedAt, RevokedAt dan ExpiresAt
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationRevoked  = "revoked"
+	InvitationExpired  = "expired"
+)
+
+// Invitation mengundang email ke organization dengan role tertentu. Token
+// yang dikirim lewat email tidak disimpan, hanya hash SHA-256-nya, sehingga
+// kirim ulang (resend) otomatis membatalkan token lama.
+type Invitation struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key"`
+	OrganizationID uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_invitations_open,where:accepted_at IS NULL AND revoked_at IS NULL"`
+	Email          string     `gorm:"not null;uniqueIndex:idx_invitations_open"`
+	Role           string     `gorm:"size:16;not null"`
+	InviterID      uuid.UUID  `gorm:"type:uuid;not null"`
+	TokenHash      string     `gorm:"size:64;not null"`
+	ExpiresAt      time.Time  `gorm:"not null"`
+	SentCount      int        `gorm:"not null;default:0"`
+	LastSentAt     *time.Time `gorm:""`
+	AcceptedAt     *time.Time `gorm:""`
+	AcceptedBy     *uuid.UUID `gorm:"type:uuid"`
+	RevokedAt      *time.Time `gorm:""`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+
+	Organization *Organization `gorm:"constraint:OnDelete:CASCADE"`
+	Inviter      *User         `gorm:"foreignKey:InviterID;constraint:OnDelete:CASCADE"`
+}
+
+func (i *Invitation) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// Status mengembalikan status invitation pada waktu now
+func (i *Invitation) Status(now time.Time) string {
+	switch {
+	case i.AcceptedAt != nil:
+		return InvitationAccepted
+	case i.RevokedAt != nil:
+		return InvitationRevoked
+	case !now.Before(i.ExpiresAt):
+		return InvitationExpired
+	default:
+		return InvitationPending
+	}
+}
+
+type CreateInvitationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=owner admin member"`
+}
+
+// InvitationTokenRequest berisi token dari link invitation
+type InvitationTokenRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// AcceptInvitationRequest menerima invitation. User yang sudah login cukup
+// mengirim token; tanpa login, password akun yang sudah ada (email sama)
+// atau name dan password untuk akun baru wajib diisi.
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Name     string `json:"name,omitempty" binding:"omitempty,min=3"`
+	Password string `json:"password,omitempty" binding:"omitempty,min=6"`
+}
+
+// InvitationFilter adalah filter daftar invitation satu organization
+type InvitationFilter struct {
+	OrganizationID uuid.UUID
+	Status         string
+}
+
+type InvitationResponse struct {
+	ID             uuid.UUID  `json:"id"`
+	OrganizationID uuid.UUID  `json:"organization_id"`
+	Email          string     `json:"email"`
+	Role           string     `json:"role"`
+	Status         string     `json:"status"`
+	InviterID      uuid.UUID  `json:"inviter_id"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	SentCount      int        `json:"sent_count"`
+	LastSentAt     *time.Time `json:"last_sent_at,omitempty"`
+	AcceptedAt     *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func NewInvitationResponse(inv *Invitation, now time.Time) *InvitationResponse {
+	return &InvitationResponse{
+		ID:             inv.ID,
+		OrganizationID: inv.OrganizationID,
+		Email:          inv.Email,
+		Role:           inv.Role,
+		Status:         inv.Status(now),
+		InviterID:      inv.InviterID,
+		ExpiresAt:      inv.ExpiresAt,
+		SentCount:      inv.SentCount,
+		LastSentAt:     inv.LastSentAt,
+		AcceptedAt:     inv.AcceptedAt,
+		RevokedAt:      inv.RevokedAt,
+		CreatedAt:      inv.CreatedAt,
+	}
+}
+
+// InvitationPreview ditampilkan ke pemegang token sebelum menerima
+// invitation. AccountExists menentukan apakah client meminta password
+// akun lama atau data sign-up.
+type InvitationPreview struct {
+	Organization  OrganizationResponse `json:"organization"`
+	Email         string               `json:"email"`
+	Role          string               `json:"role"`
+	InviterName   string               `json:"inviter_name"`
+	ExpiresAt     time.Time            `json:"expires_at"`
+	AccountExists bool                 `json:"account_exists"`
+}
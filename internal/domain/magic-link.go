@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MagicLink adalah link login sekali pakai yang dikirim ke email user.
+// Seperti invitation, token-nya tidak disimpan, hanya hash SHA-256-nya.
+// Record yang sudah dipakai atau expired tetap disimpan sampai window
+// throttle lewat, karena dipakai untuk menghitung jumlah pengiriman per
+// alamat email.
+type MagicLink struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Email     string     `gorm:"not null;index:idx_magic_links_email_created,priority:1"`
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	UsedAt    *time.Time `gorm:""`
+	CreatedAt time.Time  `gorm:"index:idx_magic_links_email_created,priority:2"`
+
+	User *User `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+func (m *MagicLink) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
+
+type MagicLinkRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// VerifyMagicLinkRequest menukar token dari link email dengan sesi login
+type VerifyMagicLinkRequest struct {
+	Token string `json:"token" binding:"required"`
+}
@@ -0,0 +1,402 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Scope OpenID Connect yang diminta client atas nama user
+const (
+	ScopeOpenID        = "openid"
+	ScopeProfile       = "profile"
+	ScopeEmail         = "email"
+	ScopeOfflineAccess = "offline_access"
+)
+
+// OIDCUserScopes adalah scope yang hanya bermakna jika ada user, sehingga
+// tidak boleh diminta lewat grant client_credentials
+var OIDCUserScopes = []string{ScopeOpenID, ScopeProfile, ScopeEmail, ScopeOfflineAccess}
+
+// Grant type OAuth 2.1 yang didukung
+const (
+	GrantAuthorizationCode = "authorization_code"
+	GrantRefreshToken      = "refresh_token"
+	GrantClientCredentials = "client_credentials"
+)
+
+// oauthScopePattern adalah karakter scope-token menurut RFC 6749 bagian 3.3
+var oauthScopePattern = regexp.MustCompile(`^[\x21\x23-\x5B\x5D-\x7E]{1,64}$`)
+
+// ValidOAuthScope melaporkan apakah s boleh dipakai sebagai scope client
+func ValidOAuthScope(s string) bool {
+	return oauthScopePattern.MatchString(s)
+}
+
+// OAuthClient adalah aplikasi yang terdaftar untuk "Log in with our
+// account". ID dipakai sebagai client_id. Client confidential menyimpan
+// hash SHA-256 dari secret; client public (SPA, aplikasi mobile) tidak
+// punya secret dan wajib memakai PKCE.
+type OAuthClient struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key"`
+	Name         string     `gorm:"size:100;not null"`
+	SecretHash   string     `gorm:"size:64"`
+	Public       bool       `gorm:"not null;default:false"`
+	RedirectURIs []string   `gorm:"type:jsonb;serializer:json;not null"`
+	GrantTypes   []string   `gorm:"type:jsonb;serializer:json;not null"`
+	Scopes       []string   `gorm:"type:jsonb;serializer:json;not null"`
+	SkipConsent  bool       `gorm:"not null;default:false"`
+	CreatedBy    *uuid.UUID `gorm:"type:uuid"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    gorm.DeletedAt `gorm:"index"`
+}
+
+func (OAuthClient) TableName() string {
+	return "oauth_clients"
+}
+
+func (c *OAuthClient) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// AllowsGrant melaporkan apakah client boleh memakai grant type tersebut
+func (c *OAuthClient) AllowsGrant(grant string) bool {
+	return slices.Contains(c.GrantTypes, grant)
+}
+
+// AllowsRedirectURI mencocokkan redirect_uri secara persis (OAuth 2.1 tidak
+// mengizinkan pencocokan prefix atau wildcard)
+func (c *OAuthClient) AllowsRedirectURI(uri string) bool {
+	return slices.Contains(c.RedirectURIs, uri)
+}
+
+// AllowsScopes melaporkan apakah semua scope terdaftar untuk client
+func (c *OAuthClient) AllowsScopes(scopes []string) bool {
+	for _, s := range scopes {
+		if !slices.Contains(c.Scopes, s) {
+			return false
+		}
+	}
+	return true
+}
+
+// OAuthAuthorizationCode adalah authorization code yang sekali pakai dan
+// berumur pendek. Yang disimpan hanya hash-nya. Refresh token yang
+// diterbitkan dari code ini memakai ID code sebagai FamilyID, sehingga
+// pemakaian ulang code bisa mencabut semuanya.
+type OAuthAuthorizationCode struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key"`
+	CodeHash      string     `gorm:"size:64;not null;uniqueIndex"`
+	ClientID      uuid.UUID  `gorm:"type:uuid;not null;index"`
+	UserID        uuid.UUID  `gorm:"type:uuid;not null;index"`
+	RedirectURI   string     `gorm:"type:text;not null"`
+	Scopes        []string   `gorm:"type:jsonb;serializer:json;not null"`
+	CodeChallenge string     `gorm:"size:128;not null"`
+	Nonce         string     `gorm:"size:255"`
+	ExpiresAt     time.Time  `gorm:"not null"`
+	UsedAt        *time.Time `gorm:""`
+	CreatedAt     time.Time
+
+	User   *User        `gorm:"constraint:OnDelete:CASCADE"`
+	Client *OAuthClient `gorm:"foreignKey:ClientID;constraint:OnDelete:CASCADE"`
+}
+
+func (OAuthAuthorizationCode) TableName() string {
+	return "oauth_authorization_codes"
+}
+
+func (c *OAuthAuthorizationCode) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// OAuthRefreshToken dirotasi setiap dipakai. Token lama yang dipakai lagi
+// dianggap bocor dan seluruh family-nya dicabut.
+type OAuthRefreshToken struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key"`
+	FamilyID  uuid.UUID  `gorm:"type:uuid;not null;index"`
+	ClientID  uuid.UUID  `gorm:"type:uuid;not null;index"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index"`
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex"`
+	Scopes    []string   `gorm:"type:jsonb;serializer:json;not null"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	RevokedAt *time.Time `gorm:""`
+	CreatedAt time.Time
+
+	User   *User        `gorm:"constraint:OnDelete:CASCADE"`
+	Client *OAuthClient `gorm:"foreignKey:ClientID;constraint:OnDelete:CASCADE"`
+}
+
+func (OAuthRefreshToken) TableName() string {
+	return "oauth_refresh_tokens"
+}
+
+func (t *OAuthRefreshToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// Covers melaporkan apakah scope termasuk scope refresh token, untuk
+// permintaan access token dengan scope yang lebih sempit
+func (t *OAuthRefreshToken) Covers(scopes []string) bool {
+	for _, s := range scopes {
+		if !slices.Contains(t.Scopes, s) {
+			return false
+		}
+	}
+	return true
+}
+
+// OAuthConsent adalah scope yang sudah disetujui user untuk client.
+// Authorization berikutnya dengan scope yang sudah tercakup tidak perlu
+// menampilkan consent screen lagi.
+type OAuthConsent struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ClientID  uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Scopes    []string  `gorm:"type:jsonb;serializer:json;not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	User   *User        `gorm:"constraint:OnDelete:CASCADE"`
+	Client *OAuthClient `gorm:"foreignKey:ClientID;constraint:OnDelete:CASCADE"`
+}
+
+func (OAuthConsent) TableName() string {
+	return "oauth_consents"
+}
+
+// Covers melaporkan apakah consent sudah mencakup semua scope
+func (c *OAuthConsent) Covers(scopes []string) bool {
+	for _, s := range scopes {
+		if !slices.Contains(c.Scopes, s) {
+			return false
+		}
+	}
+	return true
+}
+
+// CreateOAuthClientRequest mendaftarkan client. RedirectURIs wajib untuk
+// grant authorization_code; client public tidak boleh memakai
+// client_credentials.
+type CreateOAuthClientRequest struct {
+	Name         string   `json:"name" binding:"required,max=100"`
+	RedirectURIs []string `json:"redirect_uris,omitempty" binding:"max=20,dive,url"`
+	GrantTypes   []string `json:"grant_types" binding:"required,min=1,dive,oneof=authorization_code refresh_token client_credentials"`
+	Scopes       []string `json:"scopes" binding:"required,min=1,max=50,dive,oauth_scope"`
+	Public       bool     `json:"public,omitempty"`
+	SkipConsent  bool     `json:"skip_consent,omitempty"`
+}
+
+type OAuthClientResponse struct {
+	ClientID     uuid.UUID  `json:"client_id"`
+	Name         string     `json:"name"`
+	Public       bool       `json:"public"`
+	RedirectURIs []string   `json:"redirect_uris"`
+	GrantTypes   []string   `json:"grant_types"`
+	Scopes       []string   `json:"scopes"`
+	SkipConsent  bool       `json:"skip_consent"`
+	CreatedBy    *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+func NewOAuthClientResponse(c *OAuthClient) *OAuthClientResponse {
+	return &OAuthClientResponse{
+		ClientID:     c.ID,
+		Name:         c.Name,
+		Public:       c.Public,
+		RedirectURIs: c.RedirectURIs,
+		GrantTypes:   c.GrantTypes,
+		Scopes:       c.Scopes,
+		SkipConsent:  c.SkipConsent,
+		CreatedBy:    c.CreatedBy,
+		CreatedAt:    c.CreatedAt,
+	}
+}
+
+// CreatedOAuthClientResponse berisi client secret utuh, hanya dikirim sekali
+// dan kosong untuk client public
+type CreatedOAuthClientResponse struct {
+	OAuthClientResponse
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// AuthorizeRequest adalah parameter query endpoint authorize. PKCE (S256)
+// wajib untuk semua client.
+type AuthorizeRequest struct {
+	ResponseType        string `form:"response_type" binding:"required"`
+	ClientID            string `form:"client_id" binding:"required"`
+	RedirectURI         string `form:"redirect_uri"`
+	Scope               string `form:"scope"`
+	State               string `form:"state"`
+	CodeChallenge       string `form:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method" binding:"omitempty,oneof=S256"`
+	Nonce               string `form:"nonce"`
+	Prompt              string `form:"prompt" binding:"omitempty,oneof=none login consent"`
+}
+
+// ConsentRequest menyimpan keputusan user di consent screen. Request
+// adalah nilai query request yang diterima halaman consent.
+type ConsentRequest struct {
+	Request string `json:"request" binding:"required"`
+	Approve bool   `json:"approve"`
+}
+
+// ConsentDetails adalah data yang ditampilkan di consent screen
+type ConsentDetails struct {
+	ClientID    uuid.UUID `json:"client_id"`
+	ClientName  string    `json:"client_name"`
+	RedirectURI string    `json:"redirect_uri"`
+	Scopes      []string  `json:"scopes"`
+
+	// GrantedScopes adalah scope yang sudah pernah disetujui sebelumnya
+	GrantedScopes []string `json:"granted_scopes"`
+}
+
+// AuthorizeResponse berisi URL tujuan setelah authorize atau consent
+type AuthorizeResponse struct {
+	RedirectTo string `json:"redirect_to"`
+}
+
+// TokenRequest adalah body (application/x-www-form-urlencoded) endpoint
+// token. Credential client boleh dikirim lewat HTTP Basic atau body.
+type TokenRequest struct {
+	GrantType    string `form:"grant_type" json:"grant_type" binding:"required"`
+	Code         string `form:"code" json:"code,omitempty"`
+	RedirectURI  string `form:"redirect_uri" json:"redirect_uri,omitempty"`
+	CodeVerifier string `form:"code_verifier" json:"code_verifier,omitempty"`
+	RefreshToken string `form:"refresh_token" json:"refresh_token,omitempty"`
+	Scope        string `form:"scope" json:"scope,omitempty"`
+	ClientID     string `form:"client_id" json:"client_id,omitempty"`
+	ClientSecret string `form:"client_secret" json:"client_secret,omitempty"`
+}
+
+// RevokeTokenRequest adalah body endpoint revocation (RFC 7009)
+type RevokeTokenRequest struct {
+	Token         string `form:"token" json:"token" binding:"required"`
+	TokenTypeHint string `form:"token_type_hint" json:"token_type_hint,omitempty"`
+	ClientID      string `form:"client_id" json:"client_id,omitempty"`
+	ClientSecret  string `form:"client_secret" json:"client_secret,omitempty"`
+}
+
+// ClientCredentials adalah client_id dan client_secret dari request token
+type ClientCredentials struct {
+	ID     string
+	Secret string
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// UserInfo adalah claim standar OpenID Connect yang dibangun dari User.
+// Claim profile dan email hanya diisi jika scope-nya diberikan.
+type UserInfo struct {
+	Subject   string `json:"sub"`
+	Name      string `json:"name,omitempty"`
+	Nickname  string `json:"nickname,omitempty"`
+	Picture   string `json:"picture,omitempty"`
+	Zoneinfo  string `json:"zoneinfo,omitempty"`
+	Locale    string `json:"locale,omitempty"`
+	UpdatedAt int64  `json:"updated_at,omitempty"`
+	Email     string `json:"email,omitempty"`
+}
+
+func NewUserInfo(u *User, scopes []string) *UserInfo {
+	info := &UserInfo{Subject: u.ID.String()}
+	if slices.Contains(scopes, ScopeProfile) {
+		info.Name = u.Name
+		info.Nickname = u.DisplayName
+		info.Picture = u.AvatarURL
+		info.Zoneinfo = u.Timezone
+		info.Locale = u.Locale
+		info.UpdatedAt = u.UpdatedAt.Unix()
+	}
+	if slices.Contains(scopes, ScopeEmail) {
+		info.Email = u.Email
+	}
+	return info
+}
+
+// OAuthGrantResponse adalah aplikasi yang sudah diberi akses oleh user
+type OAuthGrantResponse struct {
+	ClientID   uuid.UUID `json:"client_id"`
+	ClientName string    `json:"client_name"`
+	Scopes     []string  `json:"scopes"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func NewOAuthGrantResponse(c *OAuthConsent) *OAuthGrantResponse {
+	grant := &OAuthGrantResponse{
+		ClientID:  c.ClientID,
+		Scopes:    c.Scopes,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+	if c.Client != nil {
+		grant.ClientName = c.Client.Name
+	}
+	return grant
+}
+
+// OpenIDConfiguration adalah dokumen discovery
+// /.well-known/openid-configuration
+type OpenIDConfiguration struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserinfoEndpoint                  string   `json:"userinfo_endpoint"`
+	RevocationEndpoint                string   `json:"revocation_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	ResponseModesSupported            []string `json:"response_modes_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	PromptValuesSupported             []string `json:"prompt_values_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+
+	AuthorizationResponseIssParameterSupported bool `json:"authorization_response_iss_parameter_supported"`
+}
+
+// oauthErrorCodes memetakan error domain ke kode error OAuth (RFC 6749
+// bagian 4.1.2.1 dan 5.2) untuk error yang kodenya bukan kode OAuth
+var oauthErrorCodes = map[string]string{
+	"invalid_input":                 "invalid_request",
+	"pkce_required":                 "invalid_request",
+	"invalid_authorization_request": "invalid_request",
+}
+
+// OAuthErrorCode mengembalikan kode error OAuth untuk err. Error domain
+// yang kodenya sudah kode OAuth dikembalikan apa adanya; false berarti err
+// bukan error domain (misalnya error database).
+func OAuthErrorCode(err error) (string, bool) {
+	var de *Error
+	if !errors.As(err, &de) {
+		return "", false
+	}
+	if code, ok := oauthErrorCodes[de.Code]; ok {
+		return code, true
+	}
+	return de.Code, true
+}
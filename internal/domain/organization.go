@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Role anggota di dalam satu organization. Berbeda dengan User.Role yang
+// berlaku global (admin platform).
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleAdmin  = "admin"
+	OrgRoleMember = "member"
+)
+
+// slugPattern adalah label DNS huruf kecil sehingga slug bisa dipakai
+// sebagai subdomain
+var slugPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{1,61}[a-z0-9])$`)
+
+// reservedSlugs tidak boleh dipakai karena bentrok dengan subdomain sistem
+var reservedSlugs = map[string]bool{"www": true, "api": true, "app": true, "admin": true}
+
+// ValidSlug melaporkan apakah s boleh dipakai sebagai slug organization
+func ValidSlug(s string) bool {
+	return slugPattern.MatchString(s) && !reservedSlugs[s]
+}
+
+// Organization adalah tenant. Data user hanya terlihat oleh anggota
+// organization yang sama (lihat Membership).
+type Organization struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
+	Slug      string         `gorm:"size:63;uniqueIndex;not null" json:"slug"` // Dipakai di subdomain, path dan header X-Organization
+	Name      string         `gorm:"not null" json:"name"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (o *Organization) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == uuid.Nil {
+		o.ID = uuid.New()
+	}
+	return nil
+}
+
+// Membership menghubungkan user dengan organization beserta role-nya
+type Membership struct {
+	OrganizationID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID         uuid.UUID `gorm:"type:uuid;primaryKey;index"`
+	Role           string    `gorm:"size:16;not null"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+
+	Organization *Organization `gorm:"constraint:OnDelete:CASCADE"`
+	User         *User         `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+	Slug string `json:"slug" binding:"required,slug"`
+}
+
+type AddMemberRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+	Role   string    `json:"role" binding:"required,oneof=owner admin member"`
+}
+
+type UpdateMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=owner admin member"`
+}
+
+// OrganizationResponse adalah organization beserta role user yang meminta
+type OrganizationResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Slug      string    `json:"slug"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewOrganizationResponse(org *Organization, role string) *OrganizationResponse {
+	return &OrganizationResponse{
+		ID:        org.ID,
+		Slug:      org.Slug,
+		Name:      org.Name,
+		Role:      role,
+		CreatedAt: org.CreatedAt,
+	}
+}
+
+// MemberResponse adalah anggota organization
+type MemberResponse struct {
+	UserID      uuid.UUID `json:"user_id"`
+	Name        string    `json:"name"`
+	Email       string    `json:"email"`
+	DisplayName string    `json:"display_name"`
+	AvatarURL   string    `json:"avatar_url"`
+	Role        string    `json:"role"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
+
+// NewMemberResponse memetakan Membership (dengan User ter-preload)
+func NewMemberResponse(m *Membership) *MemberResponse {
+	resp := &MemberResponse{UserID: m.UserID, Role: m.Role, JoinedAt: m.CreatedAt}
+	if m.User != nil {
+		resp.Name = m.User.Name
+		resp.Email = m.User.Email
+		resp.DisplayName = m.User.DisplayName
+		resp.AvatarURL = m.User.AvatarURL
+	}
+	return resp
+}
+
+// Tenant adalah organization aktif untuk satu request. Role kosong berarti
+// request belum terautentikasi (misalnya login ke organization tertentu)
+// atau dilakukan admin platform yang bukan anggota.
+type Tenant struct {
+	ID   uuid.UUID
+	Role string
+}
+
+// CanManageMembers melaporkan apakah role di tenant boleh menambah,
+// mengubah dan menghapus anggota
+func (t Tenant) CanManageMembers() bool {
+	return t.Role == OrgRoleOwner || t.Role == OrgRoleAdmin
+}
+
+type tenantKey struct{}
+
+// WithTenant menyimpan tenant aktif ke context. Semua query UserRepository
+// dengan context ini dibatasi ke anggota tenant tersebut.
+func WithTenant(ctx context.Context, tenant Tenant) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// WithoutTenant menghapus tenant dari context untuk operasi lintas tenant,
+// misalnya mencari akun yang diundang berdasarkan email
+func WithoutTenant(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tenantKey{}, nil)
+}
+
+func TenantFrom(ctx context.Context) (Tenant, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(Tenant)
+	return tenant, ok
+}
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Passkey adalah credential WebAuthn milik user. CredentialID dan PublicKey
+// berasal dari authenticator saat registrasi; SignCount disimpan setiap
+// login untuk mendeteksi authenticator yang digandakan.
+type Passkey struct {
+	ID              uuid.UUID  `gorm:"type:uuid;primary_key"`
+	UserID          uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Name            string     `gorm:"size:100;not null"`
+	CredentialID    []byte     `gorm:"not null;uniqueIndex"`
+	PublicKey       []byte     `gorm:"not null"`
+	AttestationType string     `gorm:"size:32"`
+	AAGUID          []byte     `gorm:""`
+	SignCount       int64      `gorm:"not null;default:0"`
+	Transports      []string   `gorm:"type:jsonb;serializer:json"`
+	BackupEligible  bool       `gorm:"not null;default:false"`
+	BackupState     bool       `gorm:"not null;default:false"`
+	LastUsedAt      *time.Time `gorm:""`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+
+	User *User `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+func (p *Passkey) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// PasskeyChallenge adalah sesi ceremony WebAuthn di antara request options
+// dan verifikasi. Session berisi challenge yang harus ditandatangani
+// authenticator; browser hanya memegang ID-nya di cookie. Record dihapus
+// saat dipakai sehingga challenge tidak bisa di-replay.
+type PasskeyChallenge struct {
+	ID        uuid.UUID            `gorm:"type:uuid;primary_key"`
+	Type      string               `gorm:"size:32;not null"`
+	Session   webauthn.SessionData `gorm:"type:jsonb;serializer:json;not null"`
+	ExpiresAt time.Time            `gorm:"not null;index"`
+	CreatedAt time.Time
+}
+
+func (c *PasskeyChallenge) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// RegisterPasskeyRequest menyelesaikan registrasi passkey. Credential adalah
+// hasil navigator.credentials.create() dari browser. Name kosong diganti
+// nama default.
+type RegisterPasskeyRequest struct {
+	Name       string                              `json:"name" binding:"max=100"`
+	Credential protocol.CredentialCreationResponse `json:"credential"`
+}
+
+// PasskeyLoginRequest menyelesaikan login passkey. Credential adalah hasil
+// navigator.credentials.get() dari browser.
+type PasskeyLoginRequest struct {
+	Credential protocol.CredentialAssertionResponse `json:"credential"`
+}
+
+type RenamePasskeyRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+type PasskeyResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	AAGUID     *uuid.UUID `json:"aaguid,omitempty"`
+	Transports []string   `json:"transports,omitempty"`
+	// Synced berarti passkey dicadangkan ke akun cloud (misalnya iCloud
+	// Keychain atau Google Password Manager)
+	Synced     bool       `json:"synced"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func NewPasskeyResponse(p *Passkey) *PasskeyResponse {
+	resp := &PasskeyResponse{
+		ID:         p.ID,
+		Name:       p.Name,
+		Transports: p.Transports,
+		Synced:     p.BackupState,
+		LastUsedAt: p.LastUsedAt,
+		CreatedAt:  p.CreatedAt,
+	}
+	// AAGUID nol berarti authenticator tidak mengungkap modelnya
+	if aaguid, err := uuid.FromBytes(p.AAGUID); err == nil && aaguid != uuid.Nil {
+		resp.AAGUID = &aaguid
+	}
+	return resp
+}
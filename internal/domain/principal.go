@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// Jenis principal yang bisa mengautentikasi request
+const (
+	PrincipalUser    = "user"
+	PrincipalService = "service"
+)
+
+// Principal adalah pihak yang mengirim request: user (lewat sesi login atau
+// personal access token) atau service account (lewat API key).
+type Principal struct {
+	Kind   string
+	ID     uuid.UUID
+	Role   string
+	Locale string
+
+	// Scopes membatasi aksi personal access token dan API key
+	Scopes []string
+
+	// CredentialID adalah ID personal access token atau API key yang dipakai,
+	// nil untuk sesi login
+	CredentialID *uuid.UUID
+}
+
+func (p *Principal) IsUser() bool {
+	return p.Kind == PrincipalUser
+}
+
+func (p *Principal) IsService() bool {
+	return p.Kind == PrincipalService
+}
+
+func (p *Principal) IsAdmin() bool {
+	return p.Role == "admin"
+}
+
+// Session melaporkan apakah principal adalah user dengan sesi login
+func (p *Principal) Session() bool {
+	return p.IsUser() && p.CredentialID == nil
+}
+
+// HasScope melaporkan apakah principal boleh melakukan aksi dengan scope
+// tersebut; sesi login selalu boleh
+func (p *Principal) HasScope(scope string) bool {
+	return p.Session() || slices.Contains(p.Scopes, scope)
+}
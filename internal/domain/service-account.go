@@ -0,0 +1,197 @@
+package domain
+
+import (
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// APIKeyPrefix menandai API key service account. Format lengkapnya
+// gsk_<key id>_<secret>; key id dipakai untuk mencari key di database.
+const APIKeyPrefix = "gsk_"
+
+// IsAPIKey melaporkan apakah credential berformat API key
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
+
+// ServiceAccount adalah principal untuk service lain (tanpa user manusia).
+// Service account tidak bisa login; ia mengautentikasi dengan API key dan
+// hanya boleh melakukan aksi sesuai Scopes.
+type ServiceAccount struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key"`
+	Name        string     `gorm:"size:100;not null"`
+	Description string     `gorm:"type:text"`
+	Role        string     `gorm:"size:16;not null"`
+	Scopes      []string   `gorm:"type:jsonb;serializer:json;not null"`
+	CreatedBy   *uuid.UUID `gorm:"type:uuid"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+}
+
+func (s *ServiceAccount) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// APIKey adalah credential service account. Secret hanya ditampilkan sekali
+// saat key dibuat; yang disimpan hanya hash SHA-256 dari key utuh. Key
+// lama yang dirotasi tetap berlaku sampai ExpiresAt (masa overlap) agar
+// service sempat berpindah ke key baru.
+type APIKey struct {
+	ID               uuid.UUID  `gorm:"type:uuid;primary_key"`
+	ServiceAccountID uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Name             string     `gorm:"size:100;not null"`
+	KeyID            string     `gorm:"size:32;not null;uniqueIndex"`
+	SecretHash       string     `gorm:"size:64;not null"`
+	AllowedIPs       []string   `gorm:"type:jsonb;serializer:json;not null"`
+	ExpiresAt        *time.Time `gorm:""`
+	RotatedFromID    *uuid.UUID `gorm:"type:uuid"`
+	LastUsedAt       *time.Time `gorm:""`
+	LastUsedIP       string     `gorm:"size:45"`
+	RevokedAt        *time.Time `gorm:""`
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+
+	ServiceAccount *ServiceAccount `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+func (k *APIKey) BeforeCreate(tx *gorm.DB) error {
+	if k.ID == uuid.Nil {
+		k.ID = uuid.New()
+	}
+	return nil
+}
+
+// Active melaporkan apakah key belum dicabut dan belum kedaluwarsa
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// AllowsIP melaporkan apakah ip termasuk allowlist. Allowlist kosong
+// menerima semua IP.
+func (k *APIKey) AllowsIP(ip string) bool {
+	if len(k.AllowedIPs) == 0 {
+		return true
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, allowed := range k.AllowedIPs {
+		prefix, err := ParseIPPrefix(allowed)
+		if err == nil && prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseIPPrefix menerima CIDR (10.0.0.0/8) atau satu alamat IP, yang
+// diperlakukan sebagai prefix /32 atau /128
+func ParseIPPrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// CreateServiceAccountRequest membuat service account. Scope admin hanya
+// untuk role admin.
+type CreateServiceAccountRequest struct {
+	Name        string   `json:"name" binding:"required,max=100"`
+	Description string   `json:"description,omitempty" binding:"max=500"`
+	Role        string   `json:"role,omitempty" binding:"omitempty,oneof=service admin"`
+	Scopes      []string `json:"scopes" binding:"required,min=1,dive,scope"`
+}
+
+// CreateAPIKeyRequest membuat API key. AllowedIPs berisi alamat IP atau
+// CIDR; kosong berarti semua IP diterima. ExpiresAt kosong berarti key
+// tidak kedaluwarsa.
+type CreateAPIKeyRequest struct {
+	Name       string     `json:"name" binding:"required,max=100"`
+	AllowedIPs []string   `json:"allowed_ips,omitempty" binding:"max=50,dive,ip_prefix"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+// RotateAPIKeyRequest membuat key pengganti dengan nama dan allowlist yang
+// sama. Key lama tetap berlaku selama OverlapSeconds (default dari
+// konfigurasi); 0 mencabutnya saat itu juga.
+type RotateAPIKeyRequest struct {
+	OverlapSeconds *int       `json:"overlap_seconds,omitempty" binding:"omitempty,min=0,max=2592000"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+}
+
+type ServiceAccountResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Role        string     `json:"role"`
+	Scopes      []string   `json:"scopes"`
+	CreatedBy   *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func NewServiceAccountResponse(s *ServiceAccount) *ServiceAccountResponse {
+	return &ServiceAccountResponse{
+		ID:          s.ID,
+		Name:        s.Name,
+		Description: s.Description,
+		Role:        s.Role,
+		Scopes:      s.Scopes,
+		CreatedBy:   s.CreatedBy,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}
+}
+
+type APIKeyResponse struct {
+	ID               uuid.UUID  `json:"id"`
+	ServiceAccountID uuid.UUID  `json:"service_account_id"`
+	Name             string     `json:"name"`
+	KeyID            string     `json:"key_id"`
+	AllowedIPs       []string   `json:"allowed_ips"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	RotatedFromID    *uuid.UUID `json:"rotated_from_id,omitempty"`
+	LastUsedAt       *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP       string     `json:"last_used_ip,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+func NewAPIKeyResponse(k *APIKey) *APIKeyResponse {
+	return &APIKeyResponse{
+		ID:               k.ID,
+		ServiceAccountID: k.ServiceAccountID,
+		Name:             k.Name,
+		KeyID:            k.KeyID,
+		AllowedIPs:       k.AllowedIPs,
+		ExpiresAt:        k.ExpiresAt,
+		RotatedFromID:    k.RotatedFromID,
+		LastUsedAt:       k.LastUsedAt,
+		LastUsedIP:       k.LastUsedIP,
+		CreatedAt:        k.CreatedAt,
+	}
+}
+
+// CreatedAPIKeyResponse berisi key utuh, hanya dikirim sekali
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// UserDocument adalah representasi user yang bisa diubah lewat PATCH
+// (JSON Merge Patch / JSON Patch). Nama field JSON juga dipakai sebagai
+// nama field pada pengecekan permission.
+type UserDocument struct {
+	Name        string `json:"name" binding:"required,min=3"`
+	Email       string `json:"email" binding:"required,email"`
+	Role        string `json:"role" binding:"required,oneof=admin user guest"`
+	Locale      string `json:"locale" binding:"required,oneof=en id"`
+	DisplayName string `json:"display_name" binding:"max=100"`
+	AvatarURL   string `json:"avatar_url" binding:"omitempty,url"`
+	Timezone    string `json:"timezone" binding:"required,timezone"`
+	Bio         string `json:"bio" binding:"max=500"`
+}
+
+func NewUserDocument(user *User) UserDocument {
+	return UserDocument{
+		Name:        user.Name,
+		Email:       user.Email,
+		Role:        user.Role,
+		Locale:      user.Locale,
+		DisplayName: user.DisplayName,
+		AvatarURL:   user.AvatarURL,
+		Timezone:    user.Timezone,
+		Bio:         user.Bio,
+	}
+}
+
+// ApplyTo menyalin isi dokumen ke user
+func (d UserDocument) ApplyTo(user *User) {
+	user.Name = d.Name
+	user.Email = d.Email
+	user.Role = d.Role
+	user.Locale = d.Locale
+	user.DisplayName = d.DisplayName
+	user.AvatarURL = d.AvatarURL
+	user.Timezone = d.Timezone
+	user.Bio = d.Bio
+}
+
+// ChangedFields mengembalikan nama field JSON yang berbeda antara d dan other
+func (d UserDocument) ChangedFields(other UserDocument) []string {
+	a, b := reflect.ValueOf(d), reflect.ValueOf(other)
+	var changed []string
+	for i := 0; i < a.NumField(); i++ {
+		if a.Field(i).Interface() != b.Field(i).Interface() {
+			changed = append(changed, jsonFieldName(a.Type().Field(i)))
+		}
+	}
+	return changed
+}
+
+// UserDocumentFields adalah semua nama field JSON pada UserDocument
+func UserDocumentFields() []string {
+	t := reflect.TypeOf(UserDocument{})
+	fields := make([]string, t.NumField())
+	for i := range fields {
+		fields[i] = jsonFieldName(t.Field(i))
+	}
+	return fields
+}
+
+func jsonFieldName(f reflect.StructField) string {
+	return strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+}
+
+// FieldSet adalah himpunan nama field JSON
+type FieldSet map[string]bool
+
+func NewFieldSet(fields ...string) FieldSet {
+	set := FieldSet{}
+	for _, f := range fields {
+		set[f] = true
+	}
+	return set
+}
+
+// With mengembalikan salinan set ditambah fields
+func (s FieldSet) With(fields ...string) FieldSet {
+	out := FieldSet{}
+	for f := range s {
+		out[f] = true
+	}
+	for _, f := range fields {
+		out[f] = true
+	}
+	return out
+}
+
+// Sorted mengembalikan isi set secara terurut
+func (s FieldSet) Sorted() []string {
+	out := make([]string, 0, len(s))
+	for f := range s {
+		out = append(out, f)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Field user yang boleh diubah pemilik akun dan admin. Role hanya bisa
+// diubah admin. avatar_url tidak ada di keduanya karena diturunkan dari
+// varian avatar yang di-upload (atau foto profil SSO).
+var (
+	OwnerWritableUserFields = NewFieldSet("name", "email", "locale", "display_name", "timezone", "bio")
+	AdminWritableUserFields = OwnerWritableUserFields.With("role")
+)
+
+// UserPatchFunc mengubah dokumen user, dipanggil dengan state user terbaru
+// di dalam transaksi
+type UserPatchFunc func(doc *UserDocument) error
+
+// FieldViolation menandai satu field yang ditolak beserta alasannya,
+// misalnya Rule "read_only" untuk field yang tidak boleh diubah caller
+type FieldViolation struct {
+	Field string
+	Rule  string
+}
+
+// FieldViolations adalah daftar field yang ditolak, dibungkus di Error
+// (lihat ErrFieldNotWritable) agar delivery layer bisa menampilkan detailnya
+type FieldViolations []FieldViolation
+
+func (v FieldViolations) Error() string {
+	parts := make([]string, len(v))
+	for i, f := range v {
+		parts[i] = f.Field + " (" + f.Rule + ")"
+	}
+	return "field violations: " + strings.Join(parts, ", ")
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,13 +10,52 @@ import (
 
 type User struct {
 	ID        uuid.UUID      `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
-	Email     string         `gorm:"uniqueIndex;not null" json:"email"`
+	Email     string         `gorm:"uniqueIndex;index:idx_users_email_lower,unique,expression:lower(email);not null" json:"email"`
 	Password  string         `gorm:"not null" json:"-"`
 	Name      string         `gorm:"not null" json:"name"`
 	Role      string         `gorm:"default:guest" json:"role"`
+	Locale    string         `gorm:"default:en" json:"locale"` // Bahasa pilihan user (en/id)
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Version naik setiap update, dipakai untuk ETag dan optimistic locking
+	Version int64 `gorm:"not null;default:1" json:"-"`
+
+	// Profil
+	DisplayName string `json:"display_name"`
+	AvatarURL   string `json:"avatar_url"`
+	Timezone    string `gorm:"default:UTC" json:"timezone"` // Nama zona IANA, misalnya Asia/Jakarta
+	Bio         string `gorm:"type:text" json:"bio"`
+
+	// Avatars berisi varian avatar hasil upload (lihat AvatarUsecase)
+	Avatars AvatarVariants `gorm:"type:jsonb" json:"-"`
+
+	// FailedLogins menghitung password salah berturut-turut; akun dikunci
+	// sampai LockedUntil setelah batasnya tercapai
+	FailedLogins int        `gorm:"not null;default:0" json:"-"`
+	LockedUntil  *time.Time `json:"-"`
+
+	// EmailVerifiedAt diisi setelah kepemilikan email terbukti (magic link,
+	// invitation atau identity provider) dan dikosongkan saat email diubah
+	EmailVerifiedAt *time.Time `json:"-"`
+}
+
+// Locked melaporkan apakah akun sedang dikunci karena terlalu banyak
+// password salah
+func (u *User) Locked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
+}
+
+// EmailVerified melaporkan apakah kepemilikan email user sudah terbukti
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// NormalizeEmail menyeragamkan alamat email sebelum disimpan atau dicari,
+// sehingga Alice@Example.com dan alice@example.com adalah akun yang sama
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
 }
 
 // BeforeCreate will set a UUID rather than numeric ID.
@@ -26,12 +66,12 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
-
 type RegisterRequest struct {
 	Name     string `json:"name" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
 	Role     string `json:"role"`
+	Locale   string `json:"locale" binding:"omitempty,oneof=en id"`
 }
 
 type LoginRequest struct {
@@ -40,23 +80,73 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	User  User   `json:"user"`
-	AccessToken string `json:"-"`
+	User         User   `json:"user"`
+	AccessToken  string `json:"-"`
 	RefreshToken string `json:"-"`
 }
 
+// UpdateRequest dipakai admin untuk mengubah user mana pun
 type UpdateRequest struct {
-	ID    uuid.UUID `json:"-"`                              // ID hanya diisi dari parameter
-	Name  *string   `json:"name,omitempty" binding:"omitempty,min=3"` // Optional tetapi minimal 3 karakter
-	Email *string   `json:"email,omitempty" binding:"omitempty,email"` // Optional tetapi harus format email valid
-	Role  *string   `json:"role,omitempty" binding:"omitempty,oneof=admin user guest"` // Optional dengan opsi role terbatas
+	ID   uuid.UUID `json:"-"`                                                         // ID hanya diisi dari parameter
+	Role *string   `json:"role,omitempty" binding:"omitempty,oneof=admin user guest"` // Optional dengan opsi role terbatas
+	UpdateProfileRequest
+}
+
+// UpdateProfileRequest berisi field yang boleh diubah user sendiri
+// melalui PATCH /api/users/me
+type UpdateProfileRequest struct {
+	Name        *string `json:"name,omitempty" binding:"omitempty,min=3"`           // Optional tetapi minimal 3 karakter
+	Email       *string `json:"email,omitempty" binding:"omitempty,email"`          // Optional tetapi harus format email valid
+	Locale      *string `json:"locale,omitempty" binding:"omitempty,oneof=en id"`   // Optional, bahasa yang didukung
+	DisplayName *string `json:"display_name,omitempty" binding:"omitempty,max=100"` // Optional, nama tampilan
+	Timezone    *string `json:"timezone,omitempty" binding:"omitempty,timezone"`    // Optional, nama zona IANA
+	Bio         *string `json:"bio,omitempty" binding:"omitempty,max=500"`          // Optional, maksimal 500 karakter
+}
+
+// DeleteAccountRequest mengonfirmasi penghapusan akun sendiri dengan password
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// UserFilter adalah filter daftar user untuk admin
+type UserFilter struct {
+	Role   string
+	Search string // dicocokkan dengan nama atau email
+	Limit  int
+	Offset int
 }
 
 type UserResponse struct {
-	ID        uuid.UUID `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Role      string    `json:"role"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          uuid.UUID         `json:"id"`
+	Name        string            `json:"name"`
+	Email       string            `json:"email"`
+	Role        string            `json:"role"`
+	Locale      string            `json:"locale"`
+	DisplayName string            `json:"display_name"`
+	AvatarURL   string            `json:"avatar_url"`
+	Avatars     map[string]string `json:"avatars,omitempty"` // Varian avatar upload: large, medium, small
+	Timezone    string            `json:"timezone"`
+	Bio         string            `json:"bio"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	Version     int64             `json:"version"` // Sama dengan nilai ETag
+}
+
+// NewUserResponse memetakan domain.User ke UserResponse
+func NewUserResponse(user *User) *UserResponse {
+	return &UserResponse{
+		ID:          user.ID,
+		Name:        user.Name,
+		Email:       user.Email,
+		Role:        user.Role,
+		Locale:      user.Locale,
+		DisplayName: user.DisplayName,
+		AvatarURL:   user.AvatarURL,
+		Avatars:     user.Avatars.URLs(),
+		Timezone:    user.Timezone,
+		Bio:         user.Bio,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+		Version:     user.Version,
+	}
 }
@@ -0,0 +1,30 @@
+package domain
+
+// VersionMatch adalah syarat versi resource dari header If-Match.
+// Nil berarti request tidak membawa syarat.
+type VersionMatch struct {
+	Any      bool // If-Match: *
+	Versions []int64
+}
+
+// Matches mengecek apakah version memenuhi syarat
+func (m *VersionMatch) Matches(version int64) bool {
+	if m == nil || m.Any {
+		return true
+	}
+	for _, v := range m.Versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckVersion mengembalikan ErrPreconditionFailed jika version tidak
+// memenuhi syarat m
+func (m *VersionMatch) CheckVersion(version int64) error {
+	if !m.Matches(version) {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
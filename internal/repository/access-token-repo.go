@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type accessTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewAccessTokenRepository(db *gorm.DB) AccessTokenRepository {
+	return &accessTokenRepository{db}
+}
+
+// accessTokenError menerjemahkan error gorm ke error domain
+func accessTokenError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ErrAccessTokenNotFound.Wrap(err)
+	}
+	return err
+}
+
+func (r *accessTokenRepository) Create(ctx context.Context, token *domain.PersonalAccessToken) error {
+	return r.db.WithContext(ctx).Omit("User").Create(token).Error
+}
+
+// FindByHash mencari token yang belum dicabut berdasarkan hash-nya
+func (r *accessTokenRepository) FindByHash(ctx context.Context, hash string) (*domain.PersonalAccessToken, error) {
+	var token domain.PersonalAccessToken
+	err := r.db.WithContext(ctx).
+		Where("token_hash = ? AND revoked_at IS NULL", hash).
+		First(&token).Error
+	if err != nil {
+		return nil, accessTokenError(err)
+	}
+	return &token, nil
+}
+
+// ListForUser mengembalikan token user yang belum dicabut, terbaru dulu
+func (r *accessTokenRepository) ListForUser(ctx context.Context, userID uuid.UUID) ([]domain.PersonalAccessToken, error) {
+	var tokens []domain.PersonalAccessToken
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("created_at DESC, id").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+// Revoke mencabut token milik user. Token yang tidak ada, milik user lain
+// atau sudah dicabut menghasilkan ErrAccessTokenNotFound.
+func (r *accessTokenRepository) Revoke(ctx context.Context, userID, id uuid.UUID, at time.Time) (*domain.PersonalAccessToken, error) {
+	var token domain.PersonalAccessToken
+	res := r.db.WithContext(ctx).Model(&token).
+		Clauses(clause.Returning{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", at)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, domain.ErrAccessTokenNotFound
+	}
+	return &token, nil
+}
+
+// TouchLastUsed mencatat waktu dan IP pemakaian terakhir. Update dilewati
+// jika pemakaian terakhir masih lebih baru dari notBefore, agar token yang
+// sering dipakai tidak menulis ke database di setiap request.
+func (r *accessTokenRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time, ip string, notBefore time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.PersonalAccessToken{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, notBefore).
+		UpdateColumns(map[string]any{"last_used_at": at, "last_used_ip": ip}).Error
+}
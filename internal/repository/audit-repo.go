@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/pkg/metrics"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
+)
+
+// auditChainLockID adalah key advisory lock (bersama nomor rantai) yang
+// menserialisasi penulisan ke satu rantai audit, sehingga PrevHash tidak
+// pernah bentrok
+const auditChainLockID = 727001
+
+// auditChains adalah jumlah rantai hash audit log. Lock rantai ditahan
+// sampai transaksi unit of work pemanggil selesai, jadi request hanya
+// menunggu request lain yang kebetulan menulis ke rantai yang sama.
+const auditChains = 16
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 200
+	auditVerifyBatch  = 500
+)
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db}
+}
+
+// InstallAuditTriggers memasang trigger yang menolak UPDATE, DELETE dan
+// TRUNCATE pada tabel audit_logs sehingga tabel benar-benar append-only,
+// serta sequence untuk Seq yang dimulai setelah entri yang sudah ada
+func InstallAuditTriggers(db *gorm.DB) error {
+	return db.Exec(`
+DO $$
+BEGIN
+	IF to_regclass('audit_logs_seq') IS NULL THEN
+		CREATE SEQUENCE audit_logs_seq;
+		PERFORM setval('audit_logs_seq', COALESCE((SELECT MAX(seq) FROM audit_logs), 0) + 1, false);
+	END IF;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_logs_no_modify ON audit_logs;
+CREATE TRIGGER audit_logs_no_modify BEFORE UPDATE OR DELETE ON audit_logs
+	FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only();
+
+DROP TRIGGER IF EXISTS audit_logs_no_truncate ON audit_logs;
+CREATE TRIGGER audit_logs_no_truncate BEFORE TRUNCATE ON audit_logs
+	FOR EACH STATEMENT EXECUTE FUNCTION audit_logs_append_only();
+`).Error
+}
+
+// auditChain memilih rantai entri dari ID transaksi Postgres. Semua entri
+// satu transaksi (termasuk savepoint unit of work bersarang) masuk ke rantai
+// yang sama sehingga lock rantai tidak pernah diambil dengan urutan berbeda,
+// dan nilainya dibuat server sehingga client tidak bisa memilih rantai.
+func auditChain(txID int64) int {
+	return int(txID % auditChains)
+}
+
+func (r *auditRepository) Append(ctx context.Context, entry *domain.AuditLog) error {
+	// Jika r.db sudah berupa transaksi, gorm memakai savepoint sehingga
+	// entri ikut commit/rollback bersama perubahan lain di unit of work
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var txID int64
+		if err := tx.Raw("SELECT txid_current()").Scan(&txID).Error; err != nil {
+			return err
+		}
+		entry.Chain = auditChain(txID)
+
+		start := time.Now()
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?, ?)", auditChainLockID, entry.Chain).Error; err != nil {
+			return err
+		}
+		metrics.AuditLockWait.Observe(time.Since(start).Seconds())
+
+		var last domain.AuditLog
+		if err := tx.Where("chain = ?", entry.Chain).Order("seq DESC").Limit(1).Find(&last).Error; err != nil {
+			return err
+		}
+		// Seq diambil setelah lock, sehingga di dalam satu rantai Seq
+		// selalu naik sesuai urutan penulisan
+		if err := tx.Raw("SELECT nextval('audit_logs_seq')").Scan(&entry.Seq).Error; err != nil {
+			return err
+		}
+
+		if entry.OccurredAt.IsZero() {
+			entry.OccurredAt = time.Now()
+		}
+		// Presisi timestamp Postgres adalah mikrodetik
+		entry.OccurredAt = entry.OccurredAt.UTC().Truncate(time.Microsecond)
+		entry.PrevHash = last.Hash
+		entry.Hash = entry.ComputeHash()
+
+		return tx.Create(entry).Error
+	})
+}
+
+func (r *auditRepository) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditLog, error) {
+	query := r.db.WithContext(ctx).Model(&domain.AuditLog{})
+
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.TargetID != nil {
+		query = query.Where("target_id = ?", *filter.TargetID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.From != nil {
+		query = query.Where("occurred_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("occurred_at < ?", *filter.To)
+	}
+	if filter.BeforeSeq > 0 {
+		query = query.Where("seq < ?", filter.BeforeSeq)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultAuditLimit
+	}
+	if limit > maxAuditLimit {
+		limit = maxAuditLimit
+	}
+
+	var entries []domain.AuditLog
+	err := query.Order("seq DESC").Limit(limit).Find(&entries).Error
+	return entries, err
+}
+
+// Verify memeriksa entri yang ditulis sejak verifikasi sebelumnya. Setiap
+// rantai punya checkpoint (Seq dan Hash entri valid terakhir) yang disimpan
+// per batch, sehingga request tidak membaca ulang seluruh tabel dan
+// verifikasi yang terpotong timeout dilanjutkan pada panggilan berikutnya.
+// Lock rantai ditahan sampai commit, jadi di dalam satu rantai entri
+// terlihat sesuai urutan Seq dan tidak ada entri yang muncul di bawah
+// checkpoint belakangan.
+func (r *auditRepository) Verify(ctx context.Context) (*domain.AuditVerification, error) {
+	result := &domain.AuditVerification{Valid: true}
+
+	var saved []domain.AuditCheckpoint
+	if err := r.db.WithContext(ctx).Clauses(dbresolver.Write).Find(&saved).Error; err != nil {
+		return nil, err
+	}
+	checkpoints := make(map[int]domain.AuditCheckpoint, auditChains)
+	for _, checkpoint := range saved {
+		checkpoints[checkpoint.Chain] = checkpoint
+	}
+
+	for chain := 0; chain < auditChains; chain++ {
+		checkpoint := checkpoints[chain]
+		checkpoint.Chain = chain
+		brokenAt, err := r.verifyChain(ctx, &checkpoint, result)
+		if err != nil {
+			return nil, err
+		}
+		checkpoints[chain] = checkpoint
+
+		// Laporkan entri rusak paling awal dari semua rantai
+		if brokenAt != nil && (result.BrokenAt == nil || *brokenAt < *result.BrokenAt) {
+			result.Valid = false
+			result.BrokenAt = brokenAt
+		}
+	}
+
+	var newest domain.AuditCheckpoint
+	for _, checkpoint := range checkpoints {
+		if checkpoint.Seq > newest.Seq {
+			newest = checkpoint
+		}
+	}
+	result.LastHash = newest.Hash
+	return result, nil
+}
+
+// verifyChain memeriksa entri satu rantai setelah checkpoint dan memajukan
+// checkpoint sampai entri valid terakhir. Hasilnya Seq entri pertama yang
+// rusak, atau nil jika semua entri valid.
+func (r *auditRepository) verifyChain(ctx context.Context, checkpoint *domain.AuditCheckpoint, result *domain.AuditVerification) (*int64, error) {
+	for {
+		var batch []domain.AuditLog
+		err := r.db.WithContext(ctx).
+			Where("chain = ? AND seq > ?", checkpoint.Chain, checkpoint.Seq).
+			Order("seq ASC").
+			Limit(auditVerifyBatch).
+			Find(&batch).Error
+		if err != nil {
+			return nil, err
+		}
+
+		valid := verifyAuditEntries(checkpoint.Hash, batch)
+		result.Checked += int64(valid)
+		if valid > 0 {
+			checkpoint.Seq = batch[valid-1].Seq
+			checkpoint.Hash = batch[valid-1].Hash
+			if err := r.saveCheckpoint(ctx, checkpoint); err != nil {
+				return nil, err
+			}
+		}
+
+		if valid < len(batch) {
+			seq := batch[valid].Seq
+			return &seq, nil
+		}
+		if len(batch) < auditVerifyBatch {
+			return nil, nil
+		}
+	}
+}
+
+// saveCheckpoint menyimpan checkpoint rantai. Checkpoint tidak pernah
+// mundur jika dua verifikasi berjalan bersamaan.
+func (r *auditRepository) saveCheckpoint(ctx context.Context, checkpoint *domain.AuditCheckpoint) error {
+	checkpoint.VerifiedAt = time.Now()
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chain"}},
+		DoUpdates: clause.AssignmentColumns([]string{"seq", "hash", "verified_at"}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: "audit_checkpoints.seq < excluded.seq"},
+		}},
+	}).Create(checkpoint).Error
+}
+
+// verifyAuditEntries memeriksa entri satu rantai yang terurut menurut Seq,
+// dimulai setelah entri dengan hash prevHash. Hasilnya jumlah entri valid
+// di awal entries; entri berikutnya (jika ada) adalah yang pertama rusak.
+func verifyAuditEntries(prevHash string, entries []domain.AuditLog) int {
+	for i := range entries {
+		entry := &entries[i]
+		// PrevHash harus menunjuk entri sebelumnya di rantai yang sama dan
+		// Hash harus cocok dengan isi entri. Seq bisa bercelah karena nilai
+		// sequence dari transaksi yang batal tidak dipakai.
+		if entry.PrevHash != prevHash || entry.ComputeHash() != entry.Hash {
+			return i
+		}
+		prevHash = entry.Hash
+	}
+	return len(entries)
+}
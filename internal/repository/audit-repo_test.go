@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/google/uuid"
+)
+
+// testAuditChain membuat n entri satu rantai seperti yang ditulis Append
+func testAuditChain(n int) []domain.AuditLog {
+	actor := uuid.New()
+	occurred := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	entries := make([]domain.AuditLog, n)
+	prevHash := ""
+	for i := range entries {
+		entry := &entries[i]
+		*entry = domain.AuditLog{
+			ID:         uuid.New(),
+			Seq:        int64(10 + 3*i), // Seq bercelah seperti nilai sequence sungguhan
+			Chain:      5,
+			OccurredAt: occurred.Add(time.Duration(i) * time.Second),
+			ActorID:    &actor,
+			ActorType:  domain.PrincipalUser,
+			Action:     domain.AuditUserUpdated,
+			IP:         "192.0.2.1",
+			Changes:    domain.AuditChanges{"name": {Before: "Alice", After: "Bob"}},
+			PrevHash:   prevHash,
+		}
+		entry.Hash = entry.ComputeHash()
+		prevHash = entry.Hash
+	}
+	return entries
+}
+
+func TestAuditHashCoversEveryField(t *testing.T) {
+	base := testAuditChain(1)[0]
+	other := uuid.New()
+
+	tests := []struct {
+		name   string
+		mutate func(*domain.AuditLog)
+	}{
+		{"seq", func(e *domain.AuditLog) { e.Seq++ }},
+		{"chain", func(e *domain.AuditLog) { e.Chain = 6 }},
+		{"occurred_at", func(e *domain.AuditLog) { e.OccurredAt = e.OccurredAt.Add(time.Microsecond) }},
+		{"actor", func(e *domain.AuditLog) { e.ActorID = &other }},
+		{"actor_type", func(e *domain.AuditLog) { e.ActorType = domain.PrincipalService }},
+		{"target", func(e *domain.AuditLog) { e.TargetID = &other }},
+		{"action", func(e *domain.AuditLog) { e.Action = domain.AuditRoleChanged }},
+		{"ip", func(e *domain.AuditLog) { e.IP = "198.51.100.1" }},
+		{"user_agent", func(e *domain.AuditLog) { e.UserAgent = "curl" }},
+		{"request_id", func(e *domain.AuditLog) { e.RequestID = "req-1" }},
+		{"changes", func(e *domain.AuditLog) { e.Changes = domain.AuditChanges{"name": {Before: "Alice", After: "Eve"}} }},
+		{"metadata", func(e *domain.AuditLog) { e.Metadata = domain.AuditMetadata{"method": "password"} }},
+		{"prev_hash", func(e *domain.AuditLog) { e.PrevHash = "00" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := base
+			tt.mutate(&entry)
+			if entry.ComputeHash() == base.Hash {
+				t.Errorf("changing %s does not change the hash", tt.name)
+			}
+		})
+	}
+
+	// Hash stabil dan map kosong sama dengan NULL di database
+	entry := base
+	entry.Metadata = domain.AuditMetadata{}
+	if entry.ComputeHash() != base.Hash {
+		t.Error("empty metadata changes the hash")
+	}
+}
+
+func TestVerifyAuditEntriesDetectsTampering(t *testing.T) {
+	tests := []struct {
+		name     string
+		prevHash func(entries []domain.AuditLog) string
+		tamper   func(entries []domain.AuditLog) []domain.AuditLog
+		want     int
+	}{
+		{"intact", nil, nil, 4},
+		{"modified entry", nil, func(entries []domain.AuditLog) []domain.AuditLog {
+			entries[1].Action = domain.AuditRoleChanged
+			return entries
+		}, 1},
+		{"modified and rehashed entry", nil, func(entries []domain.AuditLog) []domain.AuditLog {
+			entries[1].Action = domain.AuditRoleChanged
+			entries[1].Hash = entries[1].ComputeHash()
+			return entries
+		}, 2},
+		{"deleted entry", nil, func(entries []domain.AuditLog) []domain.AuditLog {
+			return append(entries[:2], entries[3:]...)
+		}, 2},
+		{"swapped entries", nil, func(entries []domain.AuditLog) []domain.AuditLog {
+			entries[1], entries[2] = entries[2], entries[1]
+			return entries
+		}, 1},
+		{"resume from checkpoint", func(entries []domain.AuditLog) string {
+			return entries[1].Hash
+		}, func(entries []domain.AuditLog) []domain.AuditLog {
+			return entries[2:]
+		}, 2},
+		{"checkpoint mismatch", func(entries []domain.AuditLog) string {
+			return entries[0].Hash
+		}, func(entries []domain.AuditLog) []domain.AuditLog {
+			return entries[2:]
+		}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := testAuditChain(4)
+			prevHash := ""
+			if tt.prevHash != nil {
+				prevHash = tt.prevHash(entries)
+			}
+			if tt.tamper != nil {
+				entries = tt.tamper(entries)
+			}
+			if got := verifyAuditEntries(prevHash, entries); got != tt.want {
+				t.Errorf("valid entries = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditChainFromTransactionID(t *testing.T) {
+	seen := map[int]bool{}
+	for txID := int64(1000); txID < 1000+auditChains; txID++ {
+		chain := auditChain(txID)
+		if chain < 0 || chain >= auditChains {
+			t.Fatalf("chain %d out of range", chain)
+		}
+		seen[chain] = true
+	}
+	if len(seen) != auditChains {
+		t.Errorf("consecutive transactions use %d of %d chains", len(seen), auditChains)
+	}
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+const pgUniqueViolation = "23505"
+
+// userError menerjemahkan error gorm/postgres ke error domain
+func userError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ErrUserNotFound.Wrap(err)
+	}
+	if isUniqueViolation(err) {
+		return domain.ErrEmailTaken.Wrap(err)
+	}
+	return err
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
+)
+
+// idempotencyBeginAttempts membatasi percobaan ulang Begin ketika record
+// lama dihapus (expired/stale) atau dilepas request lain di tengah jalan
+const idempotencyBeginAttempts = 3
+
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) IdempotencyRepository {
+	return &idempotencyRepository{db}
+}
+
+// Begin mencoba mengklaim key dengan record berstatus in_progress. Jika
+// berhasil, hasilnya nil dan caller wajib memanggil Complete atau Release.
+// Jika key sudah dipakai request yang sama, record yang ada dikembalikan
+// untuk di-replay. Record yang expired, atau in_progress yang dibuat
+// sebelum staleBefore (proses sebelumnya mati), diambil alih.
+func (r *idempotencyRepository) Begin(ctx context.Context, record *domain.IdempotencyRecord, staleBefore time.Time) (*domain.IdempotencyRecord, error) {
+	db := r.db.WithContext(ctx)
+
+	for attempt := 0; attempt < idempotencyBeginAttempts; attempt++ {
+		res := db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		if res.RowsAffected == 1 {
+			return nil, nil
+		}
+
+		// Baca dari primary: replica bisa belum melihat record yang
+		// membuat INSERT di atas konflik
+		var existing domain.IdempotencyRecord
+		err := db.Clauses(dbresolver.Write).Where("scope = ? AND key = ?", record.Scope, record.Key).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Dilepas request lain di antara INSERT dan SELECT
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		abandoned := existing.State == domain.IdempotencyInProgress && existing.CreatedAt.Before(staleBefore)
+		if existing.ExpiresAt.Before(record.CreatedAt) || abandoned {
+			// Hapus hanya jika record belum berubah sejak dibaca
+			err := db.Where("scope = ? AND key = ? AND created_at = ?", existing.Scope, existing.Key, existing.CreatedAt).
+				Delete(&domain.IdempotencyRecord{}).Error
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		switch {
+		case existing.Fingerprint != record.Fingerprint:
+			return nil, domain.ErrIdempotencyReused
+		case existing.State == domain.IdempotencyInProgress:
+			return nil, domain.ErrIdempotencyInFlight
+		default:
+			return &existing, nil
+		}
+	}
+	return nil, domain.ErrIdempotencyInFlight
+}
+
+// Complete menyimpan response untuk record yang masih in_progress
+func (r *idempotencyRepository) Complete(ctx context.Context, record *domain.IdempotencyRecord) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.IdempotencyRecord{}).
+		Where("scope = ? AND key = ? AND state = ?", record.Scope, record.Key, domain.IdempotencyInProgress).
+		Updates(map[string]any{
+			"state":       domain.IdempotencyCompleted,
+			"status_code": record.StatusCode,
+			"headers":     record.Headers,
+			"body":        record.Body,
+		}).Error
+}
+
+// Release menghapus record in_progress sehingga key bisa dipakai ulang,
+// misalnya setelah request gagal dengan error server
+func (r *idempotencyRepository) Release(ctx context.Context, scope, key string) error {
+	return r.db.WithContext(ctx).
+		Where("scope = ? AND key = ? AND state = ?", scope, key, domain.IdempotencyInProgress).
+		Delete(&domain.IdempotencyRecord{}).Error
+}
+
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Where("expires_at < ?", now).Delete(&domain.IdempotencyRecord{})
+	return res.RowsAffected, res.Error
+}
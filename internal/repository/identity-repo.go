@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type identityRepository struct {
+	db *gorm.DB
+}
+
+// NewIdentityRepository membuat repository untuk identity provider OIDC
+// yang terhubung ke user
+func NewIdentityRepository(db *gorm.DB) IdentityRepository {
+	return &identityRepository{db}
+}
+
+// identityError menerjemahkan error gorm/postgres ke error domain
+func identityError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ErrIdentityNotFound.Wrap(err)
+	}
+	if isUniqueViolation(err) {
+		return domain.ErrIdentityLinked.Wrap(err)
+	}
+	return err
+}
+
+func (r *identityRepository) Create(ctx context.Context, identity *domain.FederatedIdentity) error {
+	return identityError(r.db.WithContext(ctx).Omit("User").Create(identity).Error)
+}
+
+// FindBySubject mencari identity berdasarkan provider dan claim sub
+func (r *identityRepository) FindBySubject(ctx context.Context, provider, subject string) (*domain.FederatedIdentity, error) {
+	var identity domain.FederatedIdentity
+	err := r.db.WithContext(ctx).
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity).Error
+	if err != nil {
+		return nil, identityError(err)
+	}
+	return &identity, nil
+}
+
+// ListForUser mengembalikan identity user, terlama dulu
+func (r *identityRepository) ListForUser(ctx context.Context, userID uuid.UUID) ([]domain.FederatedIdentity, error) {
+	var identities []domain.FederatedIdentity
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at, id").
+		Find(&identities).Error
+	return identities, err
+}
+
+// Delete memutus identity milik user. Identity yang tidak ada atau milik
+// user lain menghasilkan ErrIdentityNotFound.
+func (r *identityRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	res := r.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", id, userID).
+		Delete(&domain.FederatedIdentity{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrIdentityNotFound
+	}
+	return nil
+}
+
+func (r *identityRepository) TouchLastLogin(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.FederatedIdentity{}).
+		Where("id = ?", id).
+		Update("last_login_at", at).Error
+}
@@ -1,14 +1,131 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"github.com/Hilmarch27/gin-api/internal/domain"
 	"github.com/google/uuid"
 )
 
 type UserRepository interface {
-	Create(user *domain.User) error
-	FindByEmail(email string) (*domain.User, error)
-	FindById(id uuid.UUID) (*domain.User, error)
-	Update(user *domain.User) error
-	Delete(id uuid.UUID) error
-}
\ No newline at end of file
+	Create(ctx context.Context, user *domain.User) error
+	FindByEmail(ctx context.Context, email string) (*domain.User, error)
+	FindById(ctx context.Context, id uuid.UUID) (*domain.User, error)
+	List(ctx context.Context, filter domain.UserFilter) ([]domain.User, int64, error)
+	Update(ctx context.Context, user *domain.User) error
+	Delete(ctx context.Context, id uuid.UUID, version int64) error
+	RecordLoginFailure(ctx context.Context, id uuid.UUID, maxFailures int, lockedUntil time.Time) (bool, error)
+	ResetLoginFailures(ctx context.Context, id uuid.UUID) error
+}
+
+type AuditRepository interface {
+	Append(ctx context.Context, entry *domain.AuditLog) error
+	List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditLog, error)
+	Verify(ctx context.Context) (*domain.AuditVerification, error)
+}
+
+type IdempotencyRepository interface {
+	Begin(ctx context.Context, record *domain.IdempotencyRecord, staleBefore time.Time) (*domain.IdempotencyRecord, error)
+	Complete(ctx context.Context, record *domain.IdempotencyRecord) error
+	Release(ctx context.Context, scope, key string) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type OrganizationRepository interface {
+	Create(ctx context.Context, org *domain.Organization) error
+	FindByID(ctx context.Context, id uuid.UUID) (*domain.Organization, error)
+	FindBySlug(ctx context.Context, slug string) (*domain.Organization, error)
+	ListForUser(ctx context.Context, userID uuid.UUID) ([]domain.Membership, error)
+	FindMembership(ctx context.Context, orgID, userID uuid.UUID) (*domain.Membership, error)
+	ListMembers(ctx context.Context, orgID uuid.UUID) ([]domain.Membership, error)
+	AddMember(ctx context.Context, membership *domain.Membership) error
+	UpdateMember(ctx context.Context, membership *domain.Membership) error
+	RemoveMember(ctx context.Context, orgID, userID uuid.UUID) error
+	LockOwners(ctx context.Context, orgID uuid.UUID) ([]uuid.UUID, error)
+}
+
+type InvitationRepository interface {
+	Create(ctx context.Context, inv *domain.Invitation) error
+	FindByID(ctx context.Context, id uuid.UUID) (*domain.Invitation, error)
+	FindOpen(ctx context.Context, orgID uuid.UUID, email string) (*domain.Invitation, error)
+	List(ctx context.Context, filter domain.InvitationFilter, now time.Time) ([]domain.Invitation, error)
+	Update(ctx context.Context, inv *domain.Invitation) error
+}
+
+type AccessTokenRepository interface {
+	Create(ctx context.Context, token *domain.PersonalAccessToken) error
+	FindByHash(ctx context.Context, hash string) (*domain.PersonalAccessToken, error)
+	ListForUser(ctx context.Context, userID uuid.UUID) ([]domain.PersonalAccessToken, error)
+	Revoke(ctx context.Context, userID, id uuid.UUID, at time.Time) (*domain.PersonalAccessToken, error)
+	TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time, ip string, notBefore time.Time) error
+}
+
+type ServiceAccountRepository interface {
+	Create(ctx context.Context, account *domain.ServiceAccount) error
+	FindByID(ctx context.Context, id uuid.UUID) (*domain.ServiceAccount, error)
+	List(ctx context.Context) ([]domain.ServiceAccount, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *domain.APIKey) error
+	FindByKeyID(ctx context.Context, keyID string) (*domain.APIKey, error)
+	FindByID(ctx context.Context, serviceAccountID, id uuid.UUID) (*domain.APIKey, error)
+	ListForServiceAccount(ctx context.Context, serviceAccountID uuid.UUID) ([]domain.APIKey, error)
+	Revoke(ctx context.Context, serviceAccountID, id uuid.UUID, at time.Time) (*domain.APIKey, error)
+	RevokeAll(ctx context.Context, serviceAccountID uuid.UUID, at time.Time) error
+	Expire(ctx context.Context, id uuid.UUID, at time.Time) error
+	TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time, ip string, notBefore time.Time) error
+}
+
+type OAuthClientRepository interface {
+	Create(ctx context.Context, client *domain.OAuthClient) error
+	FindByID(ctx context.Context, id uuid.UUID) (*domain.OAuthClient, error)
+	List(ctx context.Context) ([]domain.OAuthClient, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type OAuthGrantRepository interface {
+	CreateCode(ctx context.Context, code *domain.OAuthAuthorizationCode) error
+	FindCodeByHash(ctx context.Context, hash string) (*domain.OAuthAuthorizationCode, error)
+	UseCode(ctx context.Context, id uuid.UUID, at time.Time) error
+	CreateRefreshToken(ctx context.Context, token *domain.OAuthRefreshToken) error
+	FindRefreshTokenByHash(ctx context.Context, hash string) (*domain.OAuthRefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, id uuid.UUID, at time.Time) error
+	RevokeFamily(ctx context.Context, familyID uuid.UUID, at time.Time) error
+	RevokeRefreshTokens(ctx context.Context, userID, clientID uuid.UUID, at time.Time) error
+	RevokeClientRefreshTokens(ctx context.Context, clientID uuid.UUID, at time.Time) error
+	FindConsent(ctx context.Context, userID, clientID uuid.UUID) (*domain.OAuthConsent, error)
+	SaveConsent(ctx context.Context, consent *domain.OAuthConsent) error
+	ListConsents(ctx context.Context, userID uuid.UUID) ([]domain.OAuthConsent, error)
+	DeleteConsent(ctx context.Context, userID, clientID uuid.UUID) error
+}
+
+type IdentityRepository interface {
+	Create(ctx context.Context, identity *domain.FederatedIdentity) error
+	FindBySubject(ctx context.Context, provider, subject string) (*domain.FederatedIdentity, error)
+	ListForUser(ctx context.Context, userID uuid.UUID) ([]domain.FederatedIdentity, error)
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+	TouchLastLogin(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+type PasskeyRepository interface {
+	Create(ctx context.Context, passkey *domain.Passkey) error
+	FindByCredentialID(ctx context.Context, credentialID []byte) (*domain.Passkey, error)
+	ListForUser(ctx context.Context, userID uuid.UUID) ([]domain.Passkey, error)
+	Rename(ctx context.Context, userID, id uuid.UUID, name string) (*domain.Passkey, error)
+	Delete(ctx context.Context, userID, id uuid.UUID) (*domain.Passkey, error)
+	RecordUse(ctx context.Context, id uuid.UUID, signCount uint32, backupState bool, at time.Time) error
+	CreateChallenge(ctx context.Context, challenge *domain.PasskeyChallenge) error
+	ConsumeChallenge(ctx context.Context, id uuid.UUID, typ string, now time.Time) (*domain.PasskeyChallenge, error)
+	DeleteExpiredChallenges(ctx context.Context, now time.Time) (int64, error)
+}
+
+type MagicLinkRepository interface {
+	Create(ctx context.Context, link *domain.MagicLink) error
+	LockEmail(ctx context.Context, email string) error
+	CountSince(ctx context.Context, email string, since time.Time) (int64, error)
+	Consume(ctx context.Context, hash string, now time.Time) (*domain.MagicLink, error)
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type invitationRepository struct {
+	db *gorm.DB
+}
+
+func NewInvitationRepository(db *gorm.DB) InvitationRepository {
+	return &invitationRepository{db}
+}
+
+// invitationError menerjemahkan error gorm/postgres ke error domain
+func invitationError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ErrInvitationNotFound.Wrap(err)
+	}
+	if isUniqueViolation(err) {
+		return domain.ErrInvitationPending.Wrap(err)
+	}
+	return err
+}
+
+func (r *invitationRepository) Create(ctx context.Context, inv *domain.Invitation) error {
+	return invitationError(r.db.WithContext(ctx).Omit("Organization", "Inviter").Create(inv).Error)
+}
+
+// FindByID membaca invitation beserta organization dan pengundangnya
+func (r *invitationRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Invitation, error) {
+	var inv domain.Invitation
+	err := r.db.WithContext(ctx).
+		InnerJoins("Organization").
+		Preload("Inviter").
+		Where("invitations.id = ?", id).
+		First(&inv).Error
+	if err != nil {
+		return nil, invitationError(err)
+	}
+	return &inv, nil
+}
+
+// FindOpen mencari invitation yang belum diterima atau dibatalkan (termasuk
+// yang sudah expired) untuk email di organization tersebut
+func (r *invitationRepository) FindOpen(ctx context.Context, orgID uuid.UUID, email string) (*domain.Invitation, error) {
+	var inv domain.Invitation
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND email = ? AND accepted_at IS NULL AND revoked_at IS NULL", orgID, email).
+		First(&inv).Error
+	if err != nil {
+		return nil, invitationError(err)
+	}
+	return &inv, nil
+}
+
+// List mengembalikan invitation organization, yang terbaru lebih dulu.
+// Status dihitung terhadap waktu now.
+func (r *invitationRepository) List(ctx context.Context, filter domain.InvitationFilter, now time.Time) ([]domain.Invitation, error) {
+	query := r.db.WithContext(ctx).Where("organization_id = ?", filter.OrganizationID)
+
+	switch filter.Status {
+	case domain.InvitationPending:
+		query = query.Where("accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", now)
+	case domain.InvitationExpired:
+		query = query.Where("accepted_at IS NULL AND revoked_at IS NULL AND expires_at <= ?", now)
+	case domain.InvitationAccepted:
+		query = query.Where("accepted_at IS NOT NULL")
+	case domain.InvitationRevoked:
+		query = query.Where("revoked_at IS NOT NULL")
+	}
+
+	var invitations []domain.Invitation
+	err := query.Order("created_at DESC, id").Find(&invitations).Error
+	return invitations, err
+}
+
+// Update menyimpan token, masa berlaku dan status invitation. Invitation
+// yang sudah diterima atau dibatalkan request lain menghasilkan
+// ErrInvitationClosed.
+func (r *invitationRepository) Update(ctx context.Context, inv *domain.Invitation) error {
+	res := r.db.WithContext(ctx).Model(inv).
+		Where("accepted_at IS NULL AND revoked_at IS NULL").
+		Select("token_hash", "expires_at", "sent_count", "last_sent_at", "accepted_at", "accepted_by", "revoked_at", "updated_at").
+		Updates(inv)
+	if res.Error != nil {
+		return invitationError(res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrInvitationClosed
+	}
+	return nil
+}
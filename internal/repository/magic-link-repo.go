@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// magicLinkLockID adalah key advisory lock (bersama hash email) yang
+// menserialisasi pengiriman magic link ke satu alamat email
+const magicLinkLockID = 727002
+
+type magicLinkRepository struct {
+	db *gorm.DB
+}
+
+func NewMagicLinkRepository(db *gorm.DB) MagicLinkRepository {
+	return &magicLinkRepository{db}
+}
+
+func (r *magicLinkRepository) Create(ctx context.Context, link *domain.MagicLink) error {
+	return r.db.WithContext(ctx).Omit("User").Create(link).Error
+}
+
+// LockEmail mengambil advisory lock untuk email sampai transaksi selesai,
+// sehingga CountSince dan Create dari request bersamaan tidak berselang-seling
+// dan melewati batas throttle. Harus dipanggil di dalam UnitOfWork.
+func (r *magicLinkRepository) LockEmail(ctx context.Context, email string) error {
+	return r.db.WithContext(ctx).Exec("SELECT pg_advisory_xact_lock(?, hashtext(lower(?)))", magicLinkLockID, email).Error
+}
+
+// CountSince menghitung link yang dikirim ke email sejak waktu since
+func (r *magicLinkRepository) CountSince(ctx context.Context, email string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.MagicLink{}).
+		Where("email = ? AND created_at >= ?", email, since).
+		Count(&count).Error
+	return count, err
+}
+
+// Consume menandai link dengan hash tersebut sudah dipakai. Update
+// bersyarat membuat link hanya bisa dipakai sekali walaupun diverifikasi
+// bersamaan; link yang tidak ada, sudah dipakai atau expired menghasilkan
+// ErrInvalidMagicLink.
+func (r *magicLinkRepository) Consume(ctx context.Context, hash string, now time.Time) (*domain.MagicLink, error) {
+	var link domain.MagicLink
+	res := r.db.WithContext(ctx).Model(&link).
+		Clauses(clause.Returning{}).
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", hash, now).
+		Update("used_at", now)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, domain.ErrInvalidMagicLink
+	}
+	return &link, nil
+}
+
+// DeleteBefore menghapus link yang dibuat sebelum waktu before
+func (r *magicLinkRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Where("created_at < ?", before).Delete(&domain.MagicLink{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type oauthClientRepository struct {
+	db *gorm.DB
+}
+
+func NewOAuthClientRepository(db *gorm.DB) OAuthClientRepository {
+	return &oauthClientRepository{db}
+}
+
+// oauthClientError menerjemahkan error gorm ke error domain
+func oauthClientError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ErrOAuthClientNotFound.Wrap(err)
+	}
+	return err
+}
+
+func (r *oauthClientRepository) Create(ctx context.Context, client *domain.OAuthClient) error {
+	return r.db.WithContext(ctx).Create(client).Error
+}
+
+func (r *oauthClientRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.OAuthClient, error) {
+	var client domain.OAuthClient
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&client).Error; err != nil {
+		return nil, oauthClientError(err)
+	}
+	return &client, nil
+}
+
+// List mengembalikan semua client, terbaru dulu
+func (r *oauthClientRepository) List(ctx context.Context) ([]domain.OAuthClient, error) {
+	var clients []domain.OAuthClient
+	err := r.db.WithContext(ctx).Order("created_at DESC, id").Find(&clients).Error
+	return clients, err
+}
+
+// Delete menghapus (soft delete) client
+func (r *oauthClientRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	res := r.db.WithContext(ctx).Where("id = ?", id).Delete(&domain.OAuthClient{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrOAuthClientNotFound
+	}
+	return nil
+}
+
+type oauthGrantRepository struct {
+	db *gorm.DB
+}
+
+// NewOAuthGrantRepository membuat repository untuk authorization code,
+// refresh token dan consent OAuth
+func NewOAuthGrantRepository(db *gorm.DB) OAuthGrantRepository {
+	return &oauthGrantRepository{db}
+}
+
+// grantError menerjemahkan record code atau refresh token yang tidak ada
+// menjadi ErrInvalidGrant
+func grantError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ErrInvalidGrant.Wrap(err)
+	}
+	return err
+}
+
+func (r *oauthGrantRepository) CreateCode(ctx context.Context, code *domain.OAuthAuthorizationCode) error {
+	return r.db.WithContext(ctx).Omit("User", "Client").Create(code).Error
+}
+
+// FindCodeByHash mencari code berdasarkan hash-nya, termasuk yang sudah
+// dipakai agar pemakaian ulang bisa dideteksi
+func (r *oauthGrantRepository) FindCodeByHash(ctx context.Context, hash string) (*domain.OAuthAuthorizationCode, error) {
+	var code domain.OAuthAuthorizationCode
+	if err := r.db.WithContext(ctx).Where("code_hash = ?", hash).First(&code).Error; err != nil {
+		return nil, grantError(err)
+	}
+	return &code, nil
+}
+
+// UseCode menandai code sudah dipakai. Code yang sudah dipakai request lain
+// menghasilkan ErrInvalidGrant.
+func (r *oauthGrantRepository) UseCode(ctx context.Context, id uuid.UUID, at time.Time) error {
+	res := r.db.WithContext(ctx).Model(&domain.OAuthAuthorizationCode{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrInvalidGrant
+	}
+	return nil
+}
+
+func (r *oauthGrantRepository) CreateRefreshToken(ctx context.Context, token *domain.OAuthRefreshToken) error {
+	return r.db.WithContext(ctx).Omit("User", "Client").Create(token).Error
+}
+
+// FindRefreshTokenByHash mencari refresh token berdasarkan hash-nya,
+// termasuk yang sudah dicabut agar pemakaian ulang bisa dideteksi
+func (r *oauthGrantRepository) FindRefreshTokenByHash(ctx context.Context, hash string) (*domain.OAuthRefreshToken, error) {
+	var token domain.OAuthRefreshToken
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, grantError(err)
+	}
+	return &token, nil
+}
+
+// RevokeRefreshToken mencabut satu refresh token. Token yang sudah dicabut
+// (misalnya dirotasi request lain) menghasilkan ErrInvalidGrant.
+func (r *oauthGrantRepository) RevokeRefreshToken(ctx context.Context, id uuid.UUID, at time.Time) error {
+	res := r.db.WithContext(ctx).Model(&domain.OAuthRefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrInvalidGrant
+	}
+	return nil
+}
+
+// RevokeFamily mencabut semua refresh token hasil rotasi dari satu
+// authorization code
+func (r *oauthGrantRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.OAuthRefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", at).Error
+}
+
+// RevokeRefreshTokens mencabut semua refresh token user untuk client
+func (r *oauthGrantRepository) RevokeRefreshTokens(ctx context.Context, userID, clientID uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.OAuthRefreshToken{}).
+		Where("user_id = ? AND client_id = ? AND revoked_at IS NULL", userID, clientID).
+		Update("revoked_at", at).Error
+}
+
+// RevokeClientRefreshTokens mencabut semua refresh token milik client
+func (r *oauthGrantRepository) RevokeClientRefreshTokens(ctx context.Context, clientID uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.OAuthRefreshToken{}).
+		Where("client_id = ? AND revoked_at IS NULL", clientID).
+		Update("revoked_at", at).Error
+}
+
+// FindConsent mencari consent user untuk client; nil jika belum ada
+func (r *oauthGrantRepository) FindConsent(ctx context.Context, userID, clientID uuid.UUID) (*domain.OAuthConsent, error) {
+	var consent domain.OAuthConsent
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND client_id = ?", userID, clientID).
+		First(&consent).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &consent, nil
+}
+
+// SaveConsent membuat atau mengganti scope consent user untuk client
+func (r *oauthGrantRepository) SaveConsent(ctx context.Context, consent *domain.OAuthConsent) error {
+	return r.db.WithContext(ctx).Omit("User", "Client").
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "client_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"scopes", "updated_at"}),
+		}).
+		Create(consent).Error
+}
+
+// ListConsents mengembalikan consent user beserta client-nya, terbaru
+// dulu. Consent untuk client yang sudah dihapus tidak ditampilkan.
+func (r *oauthGrantRepository) ListConsents(ctx context.Context, userID uuid.UUID) ([]domain.OAuthConsent, error) {
+	var consents []domain.OAuthConsent
+	err := r.db.WithContext(ctx).
+		InnerJoins("Client").
+		Where("oauth_consents.user_id = ?", userID).
+		Order("oauth_consents.updated_at DESC, oauth_consents.client_id").
+		Find(&consents).Error
+	return consents, err
+}
+
+// DeleteConsent menghapus consent user untuk client
+func (r *oauthGrantRepository) DeleteConsent(ctx context.Context, userID, clientID uuid.UUID) error {
+	res := r.db.WithContext(ctx).
+		Where("user_id = ? AND client_id = ?", userID, clientID).
+		Delete(&domain.OAuthConsent{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrOAuthGrantNotFound
+	}
+	return nil
+}
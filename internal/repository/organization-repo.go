@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type organizationRepository struct {
+	db *gorm.DB
+}
+
+func NewOrganizationRepository(db *gorm.DB) OrganizationRepository {
+	return &organizationRepository{db}
+}
+
+// organizationError menerjemahkan error gorm/postgres ke error domain
+func organizationError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ErrOrganizationNotFound.Wrap(err)
+	}
+	if isUniqueViolation(err) {
+		return domain.ErrSlugTaken.Wrap(err)
+	}
+	return err
+}
+
+// membershipError menerjemahkan error gorm/postgres ke error domain
+func membershipError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ErrMemberNotFound.Wrap(err)
+	}
+	if isUniqueViolation(err) {
+		return domain.ErrMemberExists.Wrap(err)
+	}
+	return err
+}
+
+func (r *organizationRepository) Create(ctx context.Context, org *domain.Organization) error {
+	return organizationError(r.db.WithContext(ctx).Create(org).Error)
+}
+
+func (r *organizationRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Organization, error) {
+	var org domain.Organization
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&org).Error
+	if err != nil {
+		return nil, organizationError(err)
+	}
+	return &org, nil
+}
+
+func (r *organizationRepository) FindBySlug(ctx context.Context, slug string) (*domain.Organization, error) {
+	var org domain.Organization
+	err := r.db.WithContext(ctx).Where("slug = ?", slug).First(&org).Error
+	if err != nil {
+		return nil, organizationError(err)
+	}
+	return &org, nil
+}
+
+// ListForUser mengembalikan membership user beserta organization-nya,
+// yang paling lama lebih dulu
+func (r *organizationRepository) ListForUser(ctx context.Context, userID uuid.UUID) ([]domain.Membership, error) {
+	var memberships []domain.Membership
+	err := r.db.WithContext(ctx).
+		InnerJoins("Organization").
+		Where("memberships.user_id = ?", userID).
+		Order("memberships.created_at, memberships.organization_id").
+		Find(&memberships).Error
+	return memberships, err
+}
+
+func (r *organizationRepository) FindMembership(ctx context.Context, orgID, userID uuid.UUID) (*domain.Membership, error) {
+	var membership domain.Membership
+	err := r.db.WithContext(ctx).
+		InnerJoins("Organization").
+		Where("memberships.organization_id = ? AND memberships.user_id = ?", orgID, userID).
+		First(&membership).Error
+	if err != nil {
+		return nil, membershipError(err)
+	}
+	return &membership, nil
+}
+
+// ListMembers mengembalikan anggota organization yang akunnya masih aktif
+func (r *organizationRepository) ListMembers(ctx context.Context, orgID uuid.UUID) ([]domain.Membership, error) {
+	var memberships []domain.Membership
+	err := r.db.WithContext(ctx).
+		InnerJoins("User").
+		Where("memberships.organization_id = ?", orgID).
+		Order("memberships.created_at, memberships.user_id").
+		Find(&memberships).Error
+	return memberships, err
+}
+
+// AddMember menambahkan membership untuk user yang masih aktif. User yang
+// tidak ada (atau sudah dihapus) menghasilkan ErrUserNotFound.
+func (r *organizationRepository) AddMember(ctx context.Context, membership *domain.Membership) error {
+	res := r.db.WithContext(ctx).Exec(`
+INSERT INTO memberships (organization_id, user_id, role, created_at, updated_at)
+SELECT ?, id, ?, NOW(), NOW() FROM users WHERE id = ? AND deleted_at IS NULL`,
+		membership.OrganizationID, membership.Role, membership.UserID)
+	if res.Error != nil {
+		return membershipError(res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *organizationRepository) UpdateMember(ctx context.Context, membership *domain.Membership) error {
+	res := r.db.WithContext(ctx).Model(&domain.Membership{}).
+		Where("organization_id = ? AND user_id = ?", membership.OrganizationID, membership.UserID).
+		Update("role", membership.Role)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrMemberNotFound
+	}
+	return nil
+}
+
+func (r *organizationRepository) RemoveMember(ctx context.Context, orgID, userID uuid.UUID) error {
+	res := r.db.WithContext(ctx).
+		Where("organization_id = ? AND user_id = ?", orgID, userID).
+		Delete(&domain.Membership{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrMemberNotFound
+	}
+	return nil
+}
+
+// LockOwners mengunci baris membership owner sampai transaksi selesai dan
+// mengembalikan ID user-nya. Dipakai agar dua request yang menurunkan atau
+// menghapus owner tidak bisa bersama-sama menghapus owner terakhir.
+func (r *organizationRepository) LockOwners(ctx context.Context, orgID uuid.UUID) ([]uuid.UUID, error) {
+	var owners []uuid.UUID
+	err := r.db.WithContext(ctx).Model(&domain.Membership{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("organization_id = ? AND role = ?", orgID, domain.OrgRoleOwner).
+		Pluck("user_id", &owners).Error
+	return owners, err
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type passkeyRepository struct {
+	db *gorm.DB
+}
+
+// NewPasskeyRepository membuat repository untuk credential WebAuthn user
+func NewPasskeyRepository(db *gorm.DB) PasskeyRepository {
+	return &passkeyRepository{db}
+}
+
+// passkeyError menerjemahkan error gorm/postgres ke error domain
+func passkeyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ErrPasskeyNotFound.Wrap(err)
+	}
+	if isUniqueViolation(err) {
+		return domain.ErrPasskeyExists.Wrap(err)
+	}
+	return err
+}
+
+func (r *passkeyRepository) Create(ctx context.Context, passkey *domain.Passkey) error {
+	return passkeyError(r.db.WithContext(ctx).Omit("User").Create(passkey).Error)
+}
+
+// FindByCredentialID mencari passkey berdasarkan credential ID WebAuthn
+func (r *passkeyRepository) FindByCredentialID(ctx context.Context, credentialID []byte) (*domain.Passkey, error) {
+	var passkey domain.Passkey
+	err := r.db.WithContext(ctx).
+		Where("credential_id = ?", credentialID).
+		First(&passkey).Error
+	if err != nil {
+		return nil, passkeyError(err)
+	}
+	return &passkey, nil
+}
+
+// ListForUser mengembalikan passkey user, terlama dulu
+func (r *passkeyRepository) ListForUser(ctx context.Context, userID uuid.UUID) ([]domain.Passkey, error) {
+	var passkeys []domain.Passkey
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at, id").
+		Find(&passkeys).Error
+	return passkeys, err
+}
+
+// Rename mengganti nama passkey milik user
+func (r *passkeyRepository) Rename(ctx context.Context, userID, id uuid.UUID, name string) (*domain.Passkey, error) {
+	var passkey domain.Passkey
+	res := r.db.WithContext(ctx).Model(&passkey).
+		Clauses(clause.Returning{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("name", name)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, domain.ErrPasskeyNotFound
+	}
+	return &passkey, nil
+}
+
+// Delete menghapus passkey milik user. Passkey yang tidak ada atau milik
+// user lain menghasilkan ErrPasskeyNotFound.
+func (r *passkeyRepository) Delete(ctx context.Context, userID, id uuid.UUID) (*domain.Passkey, error) {
+	var passkey domain.Passkey
+	res := r.db.WithContext(ctx).
+		Clauses(clause.Returning{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Delete(&passkey)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, domain.ErrPasskeyNotFound
+	}
+	return &passkey, nil
+}
+
+// RecordUse menyimpan sign count dan flag backup dari login terakhir
+func (r *passkeyRepository) RecordUse(ctx context.Context, id uuid.UUID, signCount uint32, backupState bool, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.Passkey{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"sign_count":   int64(signCount),
+			"backup_state": backupState,
+			"last_used_at": at,
+		}).Error
+}
+
+func (r *passkeyRepository) CreateChallenge(ctx context.Context, challenge *domain.PasskeyChallenge) error {
+	return r.db.WithContext(ctx).Create(challenge).Error
+}
+
+// ConsumeChallenge menghapus challenge dengan ID dan tipe tersebut lalu
+// mengembalikannya. DELETE bersyarat membuat challenge hanya bisa dipakai
+// sekali walaupun diverifikasi bersamaan; challenge yang tidak ada, sudah
+// dipakai atau expired menghasilkan ErrInvalidPasskeySession.
+func (r *passkeyRepository) ConsumeChallenge(ctx context.Context, id uuid.UUID, typ string, now time.Time) (*domain.PasskeyChallenge, error) {
+	var challenge domain.PasskeyChallenge
+	res := r.db.WithContext(ctx).
+		Clauses(clause.Returning{}).
+		Where("id = ? AND type = ? AND expires_at > ?", id, typ, now).
+		Delete(&challenge)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, domain.ErrInvalidPasskeySession
+	}
+	return &challenge, nil
+}
+
+// DeleteExpiredChallenges menghapus challenge yang tidak pernah dipakai
+func (r *passkeyRepository) DeleteExpiredChallenges(ctx context.Context, now time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Where("expires_at < ?", now).Delete(&domain.PasskeyChallenge{})
+	return res.RowsAffected, res.Error
+}
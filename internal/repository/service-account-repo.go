@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type serviceAccountRepository struct {
+	db *gorm.DB
+}
+
+func NewServiceAccountRepository(db *gorm.DB) ServiceAccountRepository {
+	return &serviceAccountRepository{db}
+}
+
+// serviceAccountError menerjemahkan error gorm ke error domain
+func serviceAccountError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ErrServiceAccountNotFound.Wrap(err)
+	}
+	return err
+}
+
+func (r *serviceAccountRepository) Create(ctx context.Context, account *domain.ServiceAccount) error {
+	return r.db.WithContext(ctx).Create(account).Error
+}
+
+func (r *serviceAccountRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.ServiceAccount, error) {
+	var account domain.ServiceAccount
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&account).Error; err != nil {
+		return nil, serviceAccountError(err)
+	}
+	return &account, nil
+}
+
+// List mengembalikan semua service account, terbaru dulu
+func (r *serviceAccountRepository) List(ctx context.Context) ([]domain.ServiceAccount, error) {
+	var accounts []domain.ServiceAccount
+	err := r.db.WithContext(ctx).Order("created_at DESC, id").Find(&accounts).Error
+	return accounts, err
+}
+
+// Delete menghapus (soft delete) service account
+func (r *serviceAccountRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	res := r.db.WithContext(ctx).Where("id = ?", id).Delete(&domain.ServiceAccount{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrServiceAccountNotFound
+	}
+	return nil
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db}
+}
+
+// apiKeyError menerjemahkan error gorm ke error domain
+func apiKeyError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ErrAPIKeyNotFound.Wrap(err)
+	}
+	return err
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	return r.db.WithContext(ctx).Omit("ServiceAccount").Create(key).Error
+}
+
+// FindByKeyID mencari key yang belum dicabut beserta service account-nya.
+// Key milik service account yang sudah dihapus tidak ditemukan.
+func (r *apiKeyRepository) FindByKeyID(ctx context.Context, keyID string) (*domain.APIKey, error) {
+	var key domain.APIKey
+	err := r.db.WithContext(ctx).
+		InnerJoins("ServiceAccount").
+		Where("api_keys.key_id = ? AND api_keys.revoked_at IS NULL", keyID).
+		First(&key).Error
+	if err != nil {
+		return nil, apiKeyError(err)
+	}
+	return &key, nil
+}
+
+// FindByID mencari key service account yang belum dicabut
+func (r *apiKeyRepository) FindByID(ctx context.Context, serviceAccountID, id uuid.UUID) (*domain.APIKey, error) {
+	var key domain.APIKey
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND service_account_id = ? AND revoked_at IS NULL", id, serviceAccountID).
+		First(&key).Error
+	if err != nil {
+		return nil, apiKeyError(err)
+	}
+	return &key, nil
+}
+
+// ListForServiceAccount mengembalikan key yang belum dicabut, terbaru dulu.
+// Key yang kedaluwarsa tetap ditampilkan agar bisa dicabut.
+func (r *apiKeyRepository) ListForServiceAccount(ctx context.Context, serviceAccountID uuid.UUID) ([]domain.APIKey, error) {
+	var keys []domain.APIKey
+	err := r.db.WithContext(ctx).
+		Where("service_account_id = ? AND revoked_at IS NULL", serviceAccountID).
+		Order("created_at DESC, id").
+		Find(&keys).Error
+	return keys, err
+}
+
+// Revoke mencabut key milik service account. Key yang tidak ada, milik
+// service account lain atau sudah dicabut menghasilkan ErrAPIKeyNotFound.
+func (r *apiKeyRepository) Revoke(ctx context.Context, serviceAccountID, id uuid.UUID, at time.Time) (*domain.APIKey, error) {
+	var key domain.APIKey
+	res := r.db.WithContext(ctx).Model(&key).
+		Clauses(clause.Returning{}).
+		Where("id = ? AND service_account_id = ? AND revoked_at IS NULL", id, serviceAccountID).
+		Update("revoked_at", at)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	return &key, nil
+}
+
+// RevokeAll mencabut semua key service account
+func (r *apiKeyRepository) RevokeAll(ctx context.Context, serviceAccountID uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.APIKey{}).
+		Where("service_account_id = ? AND revoked_at IS NULL", serviceAccountID).
+		Update("revoked_at", at).Error
+}
+
+// Expire memajukan waktu kedaluwarsa key yang belum dicabut ke at. Key
+// yang sudah kedaluwarsa lebih awal tidak diubah.
+func (r *apiKeyRepository) Expire(ctx context.Context, id uuid.UUID, at time.Time) error {
+	res := r.db.WithContext(ctx).Model(&domain.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("expires_at", gorm.Expr("LEAST(COALESCE(expires_at, ?), ?)", at, at))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// TouchLastUsed mencatat waktu dan IP pemakaian terakhir, dengan batasan
+// frekuensi seperti accessTokenRepository.TouchLastUsed
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time, ip string, notBefore time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, notBefore).
+		UpdateColumns(map[string]any{"last_used_at": at, "last_used_ip": ip}).Error
+}
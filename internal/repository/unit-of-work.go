@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// Repositories berisi repository yang terikat ke satu transaksi
+type Repositories interface {
+	Users() UserRepository
+	Audit() AuditRepository
+	Organizations() OrganizationRepository
+	Invitations() InvitationRepository
+	AccessTokens() AccessTokenRepository
+	ServiceAccounts() ServiceAccountRepository
+	APIKeys() APIKeyRepository
+	OAuthClients() OAuthClientRepository
+	OAuthGrants() OAuthGrantRepository
+	Identities() IdentityRepository
+	Passkeys() PasskeyRepository
+	MagicLinks() MagicLinkRepository
+}
+
+// UnitOfWork menjalankan beberapa operasi repository secara atomik
+type UnitOfWork interface {
+	// Do menjalankan fn di dalam transaksi. Jika ctx sudah membawa transaksi
+	// (pemanggilan bersarang), fn dijalankan di dalam savepoint. Transaksi
+	// terluar diulang otomatis jika gagal karena serialization failure.
+	Do(ctx context.Context, fn func(ctx context.Context, repos Repositories) error) error
+}
+
+const (
+	txMaxRetries   = 3
+	txRetryBackoff = 50 * time.Millisecond
+)
+
+type txKey struct{}
+
+type repositories struct {
+	db *gorm.DB
+}
+
+func (r *repositories) Users() UserRepository {
+	return NewUserRepository(r.db)
+}
+
+func (r *repositories) Audit() AuditRepository {
+	return NewAuditRepository(r.db)
+}
+
+func (r *repositories) Organizations() OrganizationRepository {
+	return NewOrganizationRepository(r.db)
+}
+
+func (r *repositories) Invitations() InvitationRepository {
+	return NewInvitationRepository(r.db)
+}
+
+func (r *repositories) AccessTokens() AccessTokenRepository {
+	return NewAccessTokenRepository(r.db)
+}
+
+func (r *repositories) ServiceAccounts() ServiceAccountRepository {
+	return NewServiceAccountRepository(r.db)
+}
+
+func (r *repositories) APIKeys() APIKeyRepository {
+	return NewAPIKeyRepository(r.db)
+}
+
+func (r *repositories) OAuthClients() OAuthClientRepository {
+	return NewOAuthClientRepository(r.db)
+}
+
+func (r *repositories) OAuthGrants() OAuthGrantRepository {
+	return NewOAuthGrantRepository(r.db)
+}
+
+func (r *repositories) Identities() IdentityRepository {
+	return NewIdentityRepository(r.db)
+}
+
+func (r *repositories) Passkeys() PasskeyRepository {
+	return NewPasskeyRepository(r.db)
+}
+
+func (r *repositories) MagicLinks() MagicLinkRepository {
+	return NewMagicLinkRepository(r.db)
+}
+
+type unitOfWork struct {
+	db *gorm.DB
+}
+
+func NewUnitOfWork(db *gorm.DB) UnitOfWork {
+	return &unitOfWork{db}
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos Repositories) error) error {
+	// Transaksi bersarang: gorm otomatis memakai SAVEPOINT
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx).Transaction(func(sp *gorm.DB) error {
+			return fn(context.WithValue(ctx, txKey{}, sp), &repositories{sp})
+		})
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(context.WithValue(ctx, txKey{}, tx), &repositories{tx})
+		})
+		if err == nil || !isRetryableTxError(err) || attempt >= txMaxRetries {
+			return err
+		}
+
+		// Tunggu sebentar sebelum mencoba ulang
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(txRetryBackoff * time.Duration(attempt+1)):
+		}
+	}
+}
+
+// isRetryableTxError mendeteksi serialization failure dan deadlock Postgres
+func isRetryableTxError(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
@@ -1,50 +1,174 @@
 package repository
 
 import (
+	"context"
+	"strings"
+	"time"
+
 	"github.com/Hilmarch27/gin-api/internal/domain"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultUserLimit = 20
+	maxUserLimit     = 100
 )
 
+// userRepository membatasi setiap query ke anggota tenant di context
+// (lihat domain.WithTenant). Tanpa tenant, query berlaku untuk semua user.
 type userRepository struct {
-    db *gorm.DB
+	db *gorm.DB
 }
 
 func NewUserRepository(db *gorm.DB) UserRepository {
-    return &userRepository{db}
+	return &userRepository{db}
+}
+
+// scoped mengembalikan query yang dibatasi ke anggota tenant aktif
+func (r *userRepository) scoped(ctx context.Context) *gorm.DB {
+	db := r.db.WithContext(ctx)
+	if tenant, ok := domain.TenantFrom(ctx); ok {
+		db = db.Where("users.id IN (SELECT user_id FROM memberships WHERE organization_id = ?)", tenant.ID)
+	}
+	return db
 }
 
-func (r *userRepository) Create(user *domain.User) error {
-    return r.db.Create(user).Error
+// Create tidak dibatasi tenant: user adalah identitas global dan baru
+// menjadi anggota tenant lewat membership
+func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
+	return userError(r.db.WithContext(ctx).Create(user).Error)
 }
 
-func (r *userRepository) FindByEmail(email string) (*domain.User, error) {
-    var user domain.User
-    err := r.db.Where("email = ?", email).First(&user).Error
-    if err != nil {
-        return nil, err
-    }
-    return &user, nil
+// FindByEmail tidak membedakan huruf besar dan kecil; email lama yang belum
+// dinormalisasi tetap ditemukan lewat index lower(email)
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
+	var user domain.User
+	err := r.scoped(ctx).Where("lower(email) = ?", domain.NormalizeEmail(email)).First(&user).Error
+	if err != nil {
+		return nil, userError(err)
+	}
+	return &user, nil
 }
 
-func (r *userRepository) FindById(id uuid.UUID) (*domain.User, error) {
+func (r *userRepository) FindById(ctx context.Context, id uuid.UUID) (*domain.User, error) {
 	var user domain.User
-	err := r.db.Where("id = ?", id).First(&user).Error
+	err := r.scoped(ctx).Where("id = ?", id).First(&user).Error
 	if err != nil {
-		return nil, err
+		return nil, userError(err)
 	}
 	return &user, nil
 }
 
-func (r *userRepository) Update(user *domain.User) error {
-	return r.db.Save(user).Error
+// List mengembalikan satu halaman user beserta total user yang cocok filter
+func (r *userRepository) List(ctx context.Context, filter domain.UserFilter) ([]domain.User, int64, error) {
+	query := r.scoped(ctx).Model(&domain.User{})
+
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.Search != "" {
+		pattern := "%" + escapeLike(filter.Search) + "%"
+		query = query.Where("name ILIKE ? OR email ILIKE ?", pattern, pattern)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultUserLimit
+	}
+	if limit > maxUserLimit {
+		limit = maxUserLimit
+	}
+
+	var users []domain.User
+	err := query.Order("created_at DESC, id").Limit(limit).Offset(filter.Offset).Find(&users).Error
+	return users, total, err
 }
 
-func (r *userRepository) Delete(id uuid.UUID) error {
+// Update menyimpan user hanya jika versinya di database masih sama dengan
+// user.Version (optimistic locking), lalu menaikkan versinya. Jika baris
+// sudah diubah request lain, ErrUserModified dikembalikan. Hitungan login
+// gagal tidak ikut disimpan, kolom itu hanya diubah RecordLoginFailure dan
+// ResetLoginFailures.
+func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
+	expected := user.Version
+	user.Version = expected + 1
+
+	result := r.scoped(ctx).Model(user).
+		Where("version = ?", expected).
+		Select("*").Omit("id", "created_at", "deleted_at", "failed_logins", "locked_until").
+		Updates(user)
+	if result.Error != nil {
+		user.Version = expected
+		return userError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		user.Version = expected
+		return domain.ErrUserModified
+	}
+	return nil
+}
+
+// Delete menghapus (soft delete) user dengan versi tertentu. Jika versinya
+// sudah berubah, ErrUserModified dikembalikan.
+func (r *userRepository) Delete(ctx context.Context, id uuid.UUID, version int64) error {
+	result := r.scoped(ctx).
+		Where("id = ? AND version = ?", id, version).
+		Delete(&domain.User{})
+	if result.Error != nil {
+		return userError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		// Bedakan user yang sudah tidak ada dengan versi yang berubah
+		if _, err := r.FindById(ctx, id); err != nil {
+			return err
+		}
+		return domain.ErrUserModified
+	}
+	return nil
+}
+
+// RecordLoginFailure menaikkan hitungan password salah berturut-turut. Saat
+// hitungan mencapai maxFailures, akun dikunci sampai lockedUntil dan
+// hitungan diulang dari nol; hasil true berarti akun baru saja dikunci.
+// Harus dipanggil di dalam transaksi agar kedua update atomik.
+func (r *userRepository) RecordLoginFailure(ctx context.Context, id uuid.UUID, maxFailures int, lockedUntil time.Time) (bool, error) {
 	var user domain.User
-	err := r.db.Where("id = ?", id).First(&user).Error
-	if err != nil {
-		return err
+	res := r.db.WithContext(ctx).Model(&user).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "failed_logins"}}}).
+		Where("id = ?", id).
+		UpdateColumn("failed_logins", gorm.Expr("failed_logins + 1"))
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return false, domain.ErrUserNotFound
+	}
+	if user.FailedLogins < maxFailures {
+		return false, nil
 	}
-	return r.db.Delete(&user).Error
-}
\ No newline at end of file
+
+	err := r.db.WithContext(ctx).Model(&domain.User{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]any{"failed_logins": 0, "locked_until": lockedUntil}).Error
+	return err == nil, err
+}
+
+// ResetLoginFailures menghapus hitungan password salah dan kunci akun
+// setelah login berhasil
+func (r *userRepository) ResetLoginFailures(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&domain.User{}).
+		Where("id = ? AND (failed_logins > 0 OR locked_until IS NOT NULL)", id).
+		UpdateColumns(map[string]any{"failed_logins": 0, "locked_until": nil}).Error
+}
+
+// escapeLike meng-escape karakter wildcard LIKE pada input user
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	// accessTokenBytes adalah panjang bagian acak token (256 bit)
+	accessTokenBytes = 32
+
+	// accessTokenPrefixLen adalah panjang awal token yang disimpan untuk
+	// ditampilkan di daftar token (AccessTokenPrefix + 8 karakter)
+	accessTokenPrefixLen = len(domain.AccessTokenPrefix) + 8
+
+	// accessTokenTouchInterval membatasi update last_used_at per token
+	accessTokenTouchInterval = time.Minute
+)
+
+type AccessTokenUsecase interface {
+	Create(ctx context.Context, user *domain.User, req *domain.CreateAccessTokenRequest) (*domain.CreatedAccessTokenResponse, error)
+	List(ctx context.Context, userID uuid.UUID) ([]*domain.AccessTokenResponse, error)
+	Revoke(ctx context.Context, userID, id uuid.UUID) error
+	Authenticate(ctx context.Context, token, ip string) (*domain.User, *domain.PersonalAccessToken, error)
+}
+
+type accessTokenUsecase struct {
+	tokenRepo repository.AccessTokenRepository
+	userRepo  repository.UserRepository
+	uow       repository.UnitOfWork
+}
+
+func NewAccessTokenUsecase(tr repository.AccessTokenRepository, ur repository.UserRepository, uow repository.UnitOfWork) AccessTokenUsecase {
+	return &accessTokenUsecase{
+		tokenRepo: tr,
+		userRepo:  ur,
+		uow:       uow,
+	}
+}
+
+// Create membuat personal access token. Token utuh hanya ada di response
+// ini; database hanya menyimpan hash-nya. Scope admin hanya untuk admin.
+func (u *accessTokenUsecase) Create(ctx context.Context, user *domain.User, req *domain.CreateAccessTokenRequest) (_ *domain.CreatedAccessTokenResponse, err error) {
+	ctx, span := tracer.Start(ctx, "accessTokenUsecase.Create")
+	defer func() { endSpan(span, err) }()
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, domain.ErrInvalidExpiry
+	}
+	if user.Role != "admin" && slices.ContainsFunc(req.Scopes, domain.AdminScope) {
+		return nil, domain.ErrScopeNotAllowed
+	}
+
+	secret := make([]byte, accessTokenBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	raw := domain.AccessTokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	// Scope disimpan unik dan terurut agar daftar token mudah dibaca
+	scopes := slices.Clone(req.Scopes)
+	slices.Sort(scopes)
+	scopes = slices.Compact(scopes)
+
+	token := &domain.PersonalAccessToken{
+		UserID:    user.ID,
+		Name:      req.Name,
+		Prefix:    raw[:accessTokenPrefixLen],
+		TokenHash: hashToken(raw),
+		Scopes:    scopes,
+		ExpiresAt: req.ExpiresAt,
+	}
+	err = u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		if err := repos.AccessTokens().Create(ctx, token); err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, domain.AuditAccessTokenCreated, &user.ID)
+		entry.Metadata = accessTokenMetadata(token)
+		return repos.Audit().Append(ctx, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.CreatedAccessTokenResponse{
+		AccessTokenResponse: *domain.NewAccessTokenResponse(token),
+		Token:               raw,
+	}, nil
+}
+
+func (u *accessTokenUsecase) List(ctx context.Context, userID uuid.UUID) (_ []*domain.AccessTokenResponse, err error) {
+	ctx, span := tracer.Start(ctx, "accessTokenUsecase.List")
+	defer func() { endSpan(span, err) }()
+
+	tokens, err := u.tokenRepo.ListForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*domain.AccessTokenResponse, len(tokens))
+	for i := range tokens {
+		responses[i] = domain.NewAccessTokenResponse(&tokens[i])
+	}
+	return responses, nil
+}
+
+func (u *accessTokenUsecase) Revoke(ctx context.Context, userID, id uuid.UUID) (err error) {
+	ctx, span := tracer.Start(ctx, "accessTokenUsecase.Revoke")
+	defer func() { endSpan(span, err) }()
+
+	return u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		token, err := repos.AccessTokens().Revoke(ctx, userID, id, time.Now())
+		if err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, domain.AuditAccessTokenRevoked, &userID)
+		entry.Metadata = accessTokenMetadata(token)
+		return repos.Audit().Append(ctx, entry)
+	})
+}
+
+// Authenticate memeriksa personal access token dan mengembalikan pemiliknya.
+// Token yang tidak dikenal, dicabut, kedaluwarsa atau milik akun yang sudah
+// dihapus menghasilkan ErrUnauthorized.
+func (u *accessTokenUsecase) Authenticate(ctx context.Context, raw, ip string) (_ *domain.User, _ *domain.PersonalAccessToken, err error) {
+	ctx, span := tracer.Start(ctx, "accessTokenUsecase.Authenticate")
+	defer func() { endSpan(span, err) }()
+
+	token, err := u.tokenRepo.FindByHash(ctx, hashToken(raw))
+	if errors.Is(err, domain.ErrAccessTokenNotFound) {
+		return nil, nil, domain.ErrUnauthorized.Wrap(err)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	if !token.Active(now) {
+		return nil, nil, domain.ErrUnauthorized
+	}
+
+	// Pemilik token dicari secara global, tenant ditentukan setelah autentikasi
+	user, err := u.userRepo.FindById(domain.WithoutTenant(ctx), token.UserID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil, nil, domain.ErrUnauthorized.Wrap(err)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := u.tokenRepo.TouchLastUsed(ctx, token.ID, now, ip, now.Add(-accessTokenTouchInterval)); err != nil {
+		return nil, nil, err
+	}
+	return user, token, nil
+}
+
+// hashToken menghasilkan hash SHA-256 (hex) dari token acak yang disimpan
+// di database sebagai pengganti token aslinya
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func accessTokenMetadata(token *domain.PersonalAccessToken) domain.AuditMetadata {
+	return domain.AuditMetadata{
+		"token_id": token.ID.String(),
+		"name":     token.Name,
+		"prefix":   token.Prefix,
+		"scopes":   strings.Join(token.Scopes, " "),
+	}
+}
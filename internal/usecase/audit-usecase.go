@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+type AuditUsecase interface {
+	List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditLog, error)
+	Verify(ctx context.Context) (*domain.AuditVerification, error)
+}
+
+type auditUsecase struct {
+	auditRepo repository.AuditRepository
+}
+
+func NewAuditUsecase(ar repository.AuditRepository) AuditUsecase {
+	return &auditUsecase{auditRepo: ar}
+}
+
+func (u *auditUsecase) List(ctx context.Context, filter domain.AuditFilter) (_ []domain.AuditLog, err error) {
+	ctx, span := tracer.Start(ctx, "auditUsecase.List")
+	defer func() { endSpan(span, err) }()
+
+	return u.auditRepo.List(ctx, filter)
+}
+
+func (u *auditUsecase) Verify(ctx context.Context) (_ *domain.AuditVerification, err error) {
+	ctx, span := tracer.Start(ctx, "auditUsecase.Verify")
+	defer func() { endSpan(span, err) }()
+
+	return u.auditRepo.Verify(ctx)
+}
+
+// newAuditEntry membuat entri audit yang berisi actor, IP, user agent dan
+// request ID dari context request
+func newAuditEntry(ctx context.Context, action string, target *uuid.UUID) *domain.AuditLog {
+	meta := domain.RequestMetaFrom(ctx)
+	return &domain.AuditLog{
+		Action:    action,
+		ActorID:   meta.ActorID,
+		ActorType: meta.ActorType,
+		TargetID:  target,
+		IP:        meta.IP,
+		UserAgent: meta.UserAgent,
+		RequestID: meta.RequestID,
+	}
+}
+
+// userChanges menghasilkan diff field user yang boleh tampil di audit log
+// (password tidak pernah dicatat)
+func userChanges(before, after *domain.User) domain.AuditChanges {
+	changes := domain.AuditChanges{}
+	add := func(field, b, a string) {
+		if b != a {
+			changes[field] = domain.FieldChange{Before: b, After: a}
+		}
+	}
+	add("name", before.Name, after.Name)
+	add("email", before.Email, after.Email)
+	add("role", before.Role, after.Role)
+	add("locale", before.Locale, after.Locale)
+	add("display_name", before.DisplayName, after.DisplayName)
+	add("avatar_url", before.AvatarURL, after.AvatarURL)
+	add("timezone", before.Timezone, after.Timezone)
+	add("bio", before.Bio, after.Bio)
+	return changes
+}
@@ -1,189 +1,655 @@
 package usecase
 
 import (
+	"context"
 	"errors"
+	"log/slog"
+	"strconv"
 	"time"
 
 	"github.com/Hilmarch27/gin-api/internal/domain"
 	"github.com/Hilmarch27/gin-api/internal/repository"
+	"github.com/Hilmarch27/gin-api/pkg/i18n"
+	"github.com/Hilmarch27/gin-api/pkg/logger"
+	"github.com/Hilmarch27/gin-api/pkg/mailer"
+	"github.com/Hilmarch27/gin-api/pkg/metrics"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthUsecase interface {
-    Register(req *domain.RegisterRequest) error
-    Login(req *domain.LoginRequest) (string, string, error)
-    RefreshToken(refreshToken string) (string, string, error)
-    GetUserByID(id uuid.UUID) (*domain.UserResponse, error)
-    UpdateUser(req *domain.UpdateRequest) error
-    DeleteUser(id uuid.UUID) error
+	Register(ctx context.Context, req *domain.RegisterRequest) error
+	Login(ctx context.Context, req *domain.LoginRequest) (string, string, error)
+	RefreshToken(ctx context.Context, refreshToken string) (string, string, error)
+	SwitchOrganization(ctx context.Context, userID, orgID uuid.UUID) (string, string, error)
+	IssueTokens(ctx context.Context, user *domain.User, membership *domain.Membership) (string, string, error)
+	CompleteLogin(ctx context.Context, user *domain.User, method string) (string, string, error)
+	GetUserByID(ctx context.Context, id uuid.UUID) (*domain.UserResponse, error)
+	ListUsers(ctx context.Context, filter domain.UserFilter) ([]*domain.UserResponse, int64, error)
+	UpdateUser(ctx context.Context, req *domain.UpdateRequest, match *domain.VersionMatch) (*domain.UserResponse, error)
+	PatchUser(ctx context.Context, id uuid.UUID, match *domain.VersionMatch, writable domain.FieldSet, patch domain.UserPatchFunc) (*domain.UserResponse, error)
+	DeleteUser(ctx context.Context, id uuid.UUID, match *domain.VersionMatch) error
+	DeleteAccount(ctx context.Context, id uuid.UUID, password string, match *domain.VersionMatch) error
 }
 
 type authUsecase struct {
-    userRepo    repository.UserRepository
-    jwtSecret   []byte
-    tokenExpiry time.Duration
-}
-
-func NewAuthUsecase(ur repository.UserRepository, secret string, expiry time.Duration) AuthUsecase {
-    return &authUsecase{
-        userRepo:    ur,
-        jwtSecret:   []byte(secret),
-        tokenExpiry: expiry,
-    }
-}
-
-
-
-func (u *authUsecase) Register(req *domain.RegisterRequest) error {
-    // Validasi input
-    if req.Name == "" || req.Email == "" || req.Password == "" {
-        return errors.New("all fields are required")
-    }
-
-    // Hash password
-    hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
-    if err != nil {
-        return err
-    }
-
-    // Buat objek user
-    user := &domain.User{
-        Name:     req.Name,
-        Email:    req.Email,
-        Role:     req.Role,
-        Password: string(hashedPassword),
-    }
-
-    // Simpan ke repository
-    return u.userRepo.Create(user)
-}
-
-func (u *authUsecase) generateTokens(userID uuid.UUID, userRole string) (string, string, error) {
-    // Generate Access Token
-    accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-        "userId": userID,
-        "role":   userRole,
-        "exp":    time.Now().Add(u.tokenExpiry).Unix(),
-    })
-    accessTokenString, err := accessToken.SignedString(u.jwtSecret)
-    if err != nil {
-        return "", "", err
-    }
-
-    // Generate Refresh Token
-    refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-        "userId": userID,
-        "exp":    time.Now().Add(7 * 24 * time.Hour).Unix(), // Refresh token valid for 1 week
-    })
-    refreshTokenString, err := refreshToken.SignedString(u.jwtSecret)
-    if err != nil {
-        return "", "", err
-    }
-
-    return accessTokenString, refreshTokenString, nil
+	userRepo    repository.UserRepository
+	orgRepo     repository.OrganizationRepository
+	uow         repository.UnitOfWork
+	mailer      mailer.Mailer
+	jwtSecret   []byte
+	tokenExpiry time.Duration
+	maxFailures int
+	lockout     time.Duration
 }
 
-func (u *authUsecase) Login(req *domain.LoginRequest) (string, string, error) {
-    user, err := u.userRepo.FindByEmail(req.Email)
-    if err != nil {
-        return "", "", errors.New("invalid credentials")
-    }
-
-    err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
-    if err != nil {
-        return "", "", errors.New("invalid credentials")
-    }
-
-    return u.generateTokens(user.ID, user.Role)
-}
-
-func (u *authUsecase) RefreshToken(refreshToken string) (string, string, error) {
-    // Parse the refresh token
-    token, err := jwt.Parse(refreshToken, func(t *jwt.Token) (interface{}, error) {
-        if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-            return nil, errors.New("invalid signing method")
-        }
-        return u.jwtSecret, nil
-    })
-    if err != nil || !token.Valid {
-        return "", "", errors.New("invalid refresh token")
-    }
-
-    // Extract claims from the refresh token
-    claims, ok := token.Claims.(jwt.MapClaims)
-    if !ok {
-        return "", "", errors.New("invalid refresh token claims")
-    }
-
-    userIDStr, ok := claims["userId"].(string)
-    if !ok {
-        return "", "", errors.New("userId not found in refresh token")
-    }
-
-    // Parse the string userID into uuid.UUID
-    userID, err := uuid.Parse(userIDStr)
-    if err != nil {
-        return "", "", errors.New("invalid userId format")
-    }
-
-    // Find the user by ID
-    user, err := u.userRepo.FindById(userID)
-    if err != nil {
-        return "", "", errors.New("user not found")
-    }
-
-    // Generate new access token and refresh token
-    accessToken, newRefreshToken, err := u.generateTokens(user.ID, user.Role)
-    if err != nil {
-        return "", "", err
-    }
-
-    return accessToken, newRefreshToken, nil
-}
-
-func (u *authUsecase) GetUserByID(id uuid.UUID) (*domain.UserResponse, error) {
-    user, err := u.userRepo.FindById(id)
-    if err != nil {
-        return nil, err
-    }
-
-    // Mapping dari domain.User ke domain.UserResponse
-    userResponse := &domain.UserResponse{
-        ID:        user.ID,
-        Name:      user.Name,
-        Email:     user.Email,
-        Role:      user.Role,
-        CreatedAt: user.CreatedAt,
-        UpdatedAt: user.UpdatedAt,
-    }
-
-    return userResponse, nil
-}
-
-func (u *authUsecase) UpdateUser(req *domain.UpdateRequest) error {
-	// Ambil user berdasarkan ID
-	user, err := u.userRepo.FindById(req.ID)
-	if err != nil {
-		return err // Return error jika user tidak ditemukan
-	}
-
-	// Update field hanya jika dikirimkan (tidak nil)
-	if req.Name != nil {
-		user.Name = *req.Name
+// NewAuthUsecase membuat usecase autentikasi. Setelah maxFailures password
+// salah berturut-turut, login ke akun tersebut (dengan cara apa pun)
+// ditolak selama lockout; maxFailures 0 mematikan penguncian.
+func NewAuthUsecase(ur repository.UserRepository, or repository.OrganizationRepository, uow repository.UnitOfWork, m mailer.Mailer, secret string, expiry time.Duration, maxFailures int, lockout time.Duration) AuthUsecase {
+	return &authUsecase{
+		userRepo:    ur,
+		orgRepo:     or,
+		uow:         uow,
+		mailer:      m,
+		jwtSecret:   []byte(secret),
+		tokenExpiry: expiry,
+		maxFailures: maxFailures,
+		lockout:     lockout,
 	}
-	if req.Email != nil {
-		user.Email = *req.Email
+}
+
+func (u *authUsecase) Register(ctx context.Context, req *domain.RegisterRequest) (err error) {
+	ctx, span := tracer.Start(ctx, "authUsecase.Register")
+	defer func() { endSpan(span, err) }()
+
+	// Validasi input
+	if req.Name == "" || req.Email == "" || req.Password == "" {
+		return domain.ErrInvalidInput
+	}
+
+	// Hash password
+	_, hashSpan := tracer.Start(ctx, "bcrypt.GenerateFromPassword")
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashSpan.End()
+	if err != nil {
+		return err
 	}
-	if req.Role != nil {
-		user.Role = *req.Role
+
+	// Locale default mengikuti bahasa request jika tidak dikirim
+	locale := req.Locale
+	if locale == "" {
+		locale = i18n.FromContext(ctx)
+	}
+
+	// Buat objek user
+	user := &domain.User{
+		Name:     req.Name,
+		Email:    domain.NormalizeEmail(req.Email),
+		Role:     req.Role,
+		Locale:   locale,
+		Password: string(hashedPassword),
+	}
+
+	// Simpan user dan catat audit dalam satu transaksi
+	err = u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		if err := repos.Users().Create(ctx, user); err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, domain.AuditUserRegistered, &user.ID)
+		entry.Changes = userChanges(&domain.User{}, user)
+		return repos.Audit().Append(ctx, entry)
+	})
+	if err != nil {
+		return err
 	}
 
-	// Simpan perubahan ke database
-	return u.userRepo.Update(user)
+	// Email dikirim di background agar SMTP yang lambat tidak menahan request
+	go u.sendWelcomeEmail(logger.FromContext(ctx), user)
+
+	return nil
 }
 
-func (u *authUsecase) DeleteUser(id uuid.UUID) error {
-    return u.userRepo.Delete(id)
-}
\ No newline at end of file
+func (u *authUsecase) sendWelcomeEmail(log *slog.Logger, user *domain.User) {
+	log = log.With("user_id", user.ID.String())
+
+	email, err := i18n.RenderEmail(user.Locale, "welcome", user)
+	if err != nil {
+		log.Error("render welcome email", "error", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	err = u.mailer.Send(ctx, &mailer.Message{To: user.Email, Subject: email.Subject, Text: email.Text})
+	if err != nil {
+		log.Error("send welcome email", "error", err)
+	}
+}
+
+// generateTokens membuat access dan refresh token. Jika membership tidak
+// nil, organization-nya menjadi tenant aktif (claim org dan org_role).
+func (u *authUsecase) generateTokens(user *domain.User, membership *domain.Membership) (string, string, error) {
+	// Generate Access Token
+	accessClaims := jwt.MapClaims{
+		"userId": user.ID,
+		"role":   user.Role,
+		"locale": user.Locale,
+		"exp":    time.Now().Add(u.tokenExpiry).Unix(),
+	}
+	// Generate Refresh Token
+	refreshClaims := jwt.MapClaims{
+		"userId": user.ID,
+		"exp":    time.Now().Add(7 * 24 * time.Hour).Unix(), // Refresh token valid for 1 week
+	}
+	if membership != nil {
+		accessClaims["org"] = membership.OrganizationID
+		accessClaims["org_role"] = membership.Role
+		refreshClaims["org"] = membership.OrganizationID
+	}
+
+	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
+	accessTokenString, err := accessToken.SignedString(u.jwtSecret)
+	if err != nil {
+		return "", "", err
+	}
+
+	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
+	refreshTokenString, err := refreshToken.SignedString(u.jwtSecret)
+	if err != nil {
+		return "", "", err
+	}
+
+	return accessTokenString, refreshTokenString, nil
+}
+
+func (u *authUsecase) Login(ctx context.Context, req *domain.LoginRequest) (accessToken, refreshToken string, err error) {
+	ctx, span := tracer.Start(ctx, "authUsecase.Login")
+	defer func() { endSpan(span, err) }()
+
+	user, err := u.userRepo.FindByEmail(ctx, req.Email)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		metrics.AuthLogins.WithLabelValues(metrics.ResultFailure).Inc()
+		return "", "", u.loginFailed(ctx, nil, req.Email)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	// Akun yang terkunci ditolak tanpa memeriksa password
+	now := time.Now()
+	if user.Locked(now) {
+		metrics.AuthLogins.WithLabelValues(metrics.ResultFailure).Inc()
+		return "", "", domain.ErrAccountLocked
+	}
+
+	_, compareSpan := tracer.Start(ctx, "bcrypt.CompareHashAndPassword")
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
+	compareSpan.End()
+	if err != nil {
+		metrics.AuthLogins.WithLabelValues(metrics.ResultFailure).Inc()
+		return "", "", u.passwordFailed(ctx, user, req.Email, now)
+	}
+
+	return u.completeLogin(ctx, user, domain.LoginMethodPassword)
+}
+
+// CompleteLogin menyelesaikan login user yang sudah diverifikasi dengan
+// cara selain password (misalnya SSO): sama seperti Login, akun yang
+// terkunci ditolak, login dicatat ke audit log dan token diterbitkan untuk
+// tenant aktif
+func (u *authUsecase) CompleteLogin(ctx context.Context, user *domain.User, method string) (accessToken, refreshToken string, err error) {
+	ctx, span := tracer.Start(ctx, "authUsecase.CompleteLogin")
+	defer func() { endSpan(span, err) }()
+
+	return u.completeLogin(ctx, user, method)
+}
+
+func (u *authUsecase) completeLogin(ctx context.Context, user *domain.User, method string) (string, string, error) {
+	err := u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		// Baca ulang agar kunci yang dipasang setelah user diverifikasi ikut
+		// diperiksa; user adalah identitas global, bukan milik tenant
+		current, err := repos.Users().FindById(domain.WithoutTenant(ctx), user.ID)
+		if err != nil {
+			return err
+		}
+		if current.Locked(time.Now()) {
+			return domain.ErrAccountLocked
+		}
+
+		// Login berhasil menghapus hitungan password salah
+		if current.FailedLogins > 0 || current.LockedUntil != nil {
+			if err := repos.Users().ResetLoginFailures(ctx, user.ID); err != nil {
+				return err
+			}
+		}
+
+		// Actor login adalah user itu sendiri
+		entry := newAuditEntry(ctx, domain.AuditLogin, &user.ID)
+		entry.ActorID = &user.ID
+		entry.ActorType = domain.PrincipalUser
+		entry.Metadata = domain.AuditMetadata{"method": method}
+		return repos.Audit().Append(ctx, entry)
+	})
+	if errors.Is(err, domain.ErrAccountLocked) {
+		metrics.AuthLogins.WithLabelValues(metrics.ResultFailure).Inc()
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	membership, err := u.activeMembership(ctx, user.ID, nil)
+	if err != nil {
+		return "", "", err
+	}
+
+	metrics.AuthLogins.WithLabelValues(metrics.ResultSuccess).Inc()
+	return u.generateTokens(user, membership)
+}
+
+// activeMembership memilih tenant aktif untuk token baru: tenant request
+// (header, subdomain atau path), lalu organization sebelumnya (preferred),
+// lalu organization pertama user. Hasil nil berarti user belum punya
+// organization.
+func (u *authUsecase) activeMembership(ctx context.Context, userID uuid.UUID, preferred *uuid.UUID) (*domain.Membership, error) {
+	if tenant, ok := domain.TenantFrom(ctx); ok {
+		return u.orgRepo.FindMembership(ctx, tenant.ID, userID)
+	}
+
+	if preferred != nil {
+		membership, err := u.orgRepo.FindMembership(ctx, *preferred, userID)
+		if err == nil {
+			return membership, nil
+		}
+		// Sudah dikeluarkan dari organization tersebut, pilih yang lain
+		if !errors.Is(err, domain.ErrMemberNotFound) {
+			return nil, err
+		}
+	}
+
+	memberships, err := u.orgRepo.ListForUser(ctx, userID)
+	if err != nil || len(memberships) == 0 {
+		return nil, err
+	}
+	return &memberships[0], nil
+}
+
+// loginFailed mencatat login gagal ke audit log lalu mengembalikan
+// ErrInvalidCredentials (atau error audit jika pencatatan gagal)
+func (u *authUsecase) loginFailed(ctx context.Context, userID *uuid.UUID, email string) error {
+	err := u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		entry := newAuditEntry(ctx, domain.AuditLoginFailed, userID)
+		entry.Metadata = domain.AuditMetadata{"email": email}
+		return repos.Audit().Append(ctx, entry)
+	})
+	if err != nil {
+		return err
+	}
+	return domain.ErrInvalidCredentials
+}
+
+// passwordFailed mencatat password yang salah ke audit log dan menaikkan
+// hitungan login gagal user. Jika batasnya tercapai akun dikunci dan
+// ErrAccountLocked dikembalikan, selain itu ErrInvalidCredentials.
+func (u *authUsecase) passwordFailed(ctx context.Context, user *domain.User, email string, now time.Time) error {
+	var locked bool
+	err := u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		if u.maxFailures > 0 {
+			var err error
+			locked, err = repos.Users().RecordLoginFailure(ctx, user.ID, u.maxFailures, now.Add(u.lockout))
+			if err != nil {
+				return err
+			}
+		}
+
+		entry := newAuditEntry(ctx, domain.AuditLoginFailed, &user.ID)
+		entry.Metadata = domain.AuditMetadata{"email": email}
+		if err := repos.Audit().Append(ctx, entry); err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+
+		entry = newAuditEntry(ctx, domain.AuditAccountLocked, &user.ID)
+		entry.Metadata = domain.AuditMetadata{
+			"failed_logins": strconv.Itoa(u.maxFailures),
+			"locked_until":  now.Add(u.lockout).UTC().Format(time.RFC3339),
+		}
+		return repos.Audit().Append(ctx, entry)
+	})
+	if err != nil {
+		return err
+	}
+
+	if locked {
+		metrics.AuthLockouts.Inc()
+		return domain.ErrAccountLocked
+	}
+	return domain.ErrInvalidCredentials
+}
+
+func (u *authUsecase) RefreshToken(ctx context.Context, refreshToken string) (accessToken, newRefreshToken string, err error) {
+	ctx, span := tracer.Start(ctx, "authUsecase.RefreshToken")
+	defer func() { endSpan(span, err) }()
+
+	defer func() {
+		result := metrics.ResultSuccess
+		if err != nil {
+			result = metrics.ResultFailure
+		}
+		metrics.AuthRefreshes.WithLabelValues(result).Inc()
+	}()
+
+	// Parse the refresh token
+	token, err := jwt.Parse(refreshToken, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("invalid signing method")
+		}
+		return u.jwtSecret, nil
+	})
+	if err != nil || !token.Valid {
+		return "", "", domain.ErrInvalidRefreshToken.Wrap(err)
+	}
+
+	// Extract claims from the refresh token
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return "", "", domain.ErrInvalidRefreshToken
+	}
+
+	userIDStr, ok := claims["userId"].(string)
+	if !ok {
+		return "", "", domain.ErrInvalidRefreshToken
+	}
+
+	// Parse the string userID into uuid.UUID
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return "", "", domain.ErrInvalidRefreshToken.Wrap(err)
+	}
+
+	// Find the user by ID
+	user, err := u.userRepo.FindById(ctx, userID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return "", "", domain.ErrInvalidRefreshToken.Wrap(err)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	err = u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		entry := newAuditEntry(ctx, domain.AuditTokenRefreshed, &user.ID)
+		entry.ActorID = &user.ID
+		entry.ActorType = domain.PrincipalUser
+		return repos.Audit().Append(ctx, entry)
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	// Organization aktif sebelumnya tetap dipakai selama masih anggota
+	var preferred *uuid.UUID
+	if orgStr, ok := claims["org"].(string); ok {
+		if orgID, err := uuid.Parse(orgStr); err == nil {
+			preferred = &orgID
+		}
+	}
+	membership, err := u.activeMembership(ctx, user.ID, preferred)
+	if errors.Is(err, domain.ErrMemberNotFound) {
+		return "", "", domain.ErrInvalidRefreshToken.Wrap(err)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	// Generate new access token and refresh token
+	return u.generateTokens(user, membership)
+}
+
+// SwitchOrganization menerbitkan token baru dengan orgID sebagai tenant aktif
+func (u *authUsecase) SwitchOrganization(ctx context.Context, userID, orgID uuid.UUID) (accessToken, refreshToken string, err error) {
+	ctx, span := tracer.Start(ctx, "authUsecase.SwitchOrganization")
+	defer func() { endSpan(span, err) }()
+
+	membership, err := u.orgRepo.FindMembership(ctx, orgID, userID)
+	if errors.Is(err, domain.ErrMemberNotFound) {
+		return "", "", domain.ErrNotMember.Wrap(err)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	user, err := u.userRepo.FindById(domain.WithTenant(ctx, domain.Tenant{ID: orgID}), userID)
+	if err != nil {
+		return "", "", err
+	}
+	return u.generateTokens(user, membership)
+}
+
+// IssueTokens menerbitkan token untuk user yang sudah diverifikasi di luar
+// Login, misalnya setelah menerima invitation
+func (u *authUsecase) IssueTokens(ctx context.Context, user *domain.User, membership *domain.Membership) (accessToken, refreshToken string, err error) {
+	_, span := tracer.Start(ctx, "authUsecase.IssueTokens")
+	defer func() { endSpan(span, err) }()
+
+	return u.generateTokens(user, membership)
+}
+
+func (u *authUsecase) GetUserByID(ctx context.Context, id uuid.UUID) (_ *domain.UserResponse, err error) {
+	ctx, span := tracer.Start(ctx, "authUsecase.GetUserByID")
+	defer func() { endSpan(span, err) }()
+
+	user, err := u.userRepo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.NewUserResponse(user), nil
+}
+
+func (u *authUsecase) ListUsers(ctx context.Context, filter domain.UserFilter) (_ []*domain.UserResponse, _ int64, err error) {
+	ctx, span := tracer.Start(ctx, "authUsecase.ListUsers")
+	defer func() { endSpan(span, err) }()
+
+	users, total, err := u.userRepo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]*domain.UserResponse, len(users))
+	for i := range users {
+		responses[i] = domain.NewUserResponse(&users[i])
+	}
+	return responses, total, nil
+}
+
+// UpdateUser mengubah user jika versinya memenuhi match (header If-Match).
+// Update yang bentrok dengan request lain menghasilkan ErrUserModified.
+func (u *authUsecase) UpdateUser(ctx context.Context, req *domain.UpdateRequest, match *domain.VersionMatch) (_ *domain.UserResponse, err error) {
+	ctx, span := tracer.Start(ctx, "authUsecase.UpdateUser")
+	defer func() { endSpan(span, err) }()
+
+	var user *domain.User
+
+	// Baca dan tulis dalam satu transaksi
+	err = u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		// Ambil user berdasarkan ID
+		var err error
+		user, err = repos.Users().FindById(ctx, req.ID)
+		if err != nil {
+			return err // Return error jika user tidak ditemukan
+		}
+		if err := match.CheckVersion(user.Version); err != nil {
+			return err
+		}
+		before := *user
+
+		// Update field hanya jika dikirimkan (tidak nil)
+		if req.Name != nil {
+			user.Name = *req.Name
+		}
+		if req.Email != nil {
+			user.Email = *req.Email
+		}
+		if req.Role != nil {
+			user.Role = *req.Role
+		}
+		if req.Locale != nil {
+			user.Locale = *req.Locale
+		}
+		if req.DisplayName != nil {
+			user.DisplayName = *req.DisplayName
+		}
+		if req.Timezone != nil {
+			user.Timezone = *req.Timezone
+		}
+		if req.Bio != nil {
+			user.Bio = *req.Bio
+		}
+
+		return saveUser(ctx, repos, &before, user)
+	})
+	if err != nil {
+		return nil, preconditionError(err, match)
+	}
+	return domain.NewUserResponse(user), nil
+}
+
+// PatchUser menerapkan patch (JSON Merge Patch / JSON Patch) ke user.
+// Patch hanya boleh mengubah field di writable.
+func (u *authUsecase) PatchUser(ctx context.Context, id uuid.UUID, match *domain.VersionMatch, writable domain.FieldSet, patch domain.UserPatchFunc) (_ *domain.UserResponse, err error) {
+	ctx, span := tracer.Start(ctx, "authUsecase.PatchUser")
+	defer func() { endSpan(span, err) }()
+
+	var user *domain.User
+	err = u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		var err error
+		user, err = repos.Users().FindById(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := match.CheckVersion(user.Version); err != nil {
+			return err
+		}
+		before := *user
+
+		doc := domain.NewUserDocument(user)
+		original := doc
+		if err := patch(&doc); err != nil {
+			return err
+		}
+
+		var violations domain.FieldViolations
+		for _, field := range original.ChangedFields(doc) {
+			if !writable[field] {
+				violations = append(violations, domain.FieldViolation{Field: field, Rule: "read_only"})
+			}
+		}
+		if len(violations) > 0 {
+			return domain.ErrFieldNotWritable.Wrap(violations)
+		}
+
+		doc.ApplyTo(user)
+		return saveUser(ctx, repos, &before, user)
+	})
+	if err != nil {
+		return nil, preconditionError(err, match)
+	}
+	return domain.NewUserResponse(user), nil
+}
+
+// saveUser menyimpan user lalu mencatat diff-nya ke audit log. Perubahan
+// role punya aksi audit tersendiri. Tanpa perubahan, user tidak disimpan
+// sehingga versinya (ETag) tetap.
+func saveUser(ctx context.Context, repos repository.Repositories, before, user *domain.User) error {
+	// Email baru belum terbukti milik user
+	user.Email = domain.NormalizeEmail(user.Email)
+	if user.Email != domain.NormalizeEmail(before.Email) {
+		user.EmailVerifiedAt = nil
+	}
+
+	changes := userChanges(before, user)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	if err := repos.Users().Update(ctx, user); err != nil {
+		return err
+	}
+
+	action := domain.AuditUserUpdated
+	if _, ok := changes["role"]; ok {
+		action = domain.AuditRoleChanged
+	}
+	entry := newAuditEntry(ctx, action, &user.ID)
+	entry.Changes = changes
+	return repos.Audit().Append(ctx, entry)
+}
+
+func (u *authUsecase) DeleteUser(ctx context.Context, id uuid.UUID, match *domain.VersionMatch) (err error) {
+	ctx, span := tracer.Start(ctx, "authUsecase.DeleteUser")
+	defer func() { endSpan(span, err) }()
+
+	err = u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		user, err := repos.Users().FindById(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := match.CheckVersion(user.Version); err != nil {
+			return err
+		}
+		return deleteUser(ctx, repos, user)
+	})
+	return preconditionError(err, match)
+}
+
+// DeleteAccount menghapus akun milik user sendiri setelah password dikonfirmasi
+func (u *authUsecase) DeleteAccount(ctx context.Context, id uuid.UUID, password string, match *domain.VersionMatch) (err error) {
+	ctx, span := tracer.Start(ctx, "authUsecase.DeleteAccount")
+	defer func() { endSpan(span, err) }()
+
+	err = u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		user, err := repos.Users().FindById(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := match.CheckVersion(user.Version); err != nil {
+			return err
+		}
+
+		_, compareSpan := tracer.Start(ctx, "bcrypt.CompareHashAndPassword")
+		err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+		compareSpan.End()
+		if err != nil {
+			return domain.ErrInvalidPassword
+		}
+
+		return deleteUser(ctx, repos, user)
+	})
+	return preconditionError(err, match)
+}
+
+// preconditionError mengubah ErrUserModified menjadi ErrPreconditionFailed
+// jika request membawa If-Match: versi yang diperiksa sudah diubah request
+// lain sebelum tersimpan, jadi syarat client tidak lagi terpenuhi
+func preconditionError(err error, match *domain.VersionMatch) error {
+	if match != nil && errors.Is(err, domain.ErrUserModified) {
+		return domain.ErrPreconditionFailed.Wrap(err)
+	}
+	return err
+}
+
+// deleteUser menghapus user dan mencatatnya ke audit log
+func deleteUser(ctx context.Context, repos repository.Repositories, user *domain.User) error {
+	if err := repos.Users().Delete(ctx, user.ID, user.Version); err != nil {
+		return err
+	}
+
+	entry := newAuditEntry(ctx, domain.AuditUserDeleted, &user.ID)
+	entry.Changes = userChanges(user, &domain.User{})
+	return repos.Audit().Append(ctx, entry)
+}
@@ -0,0 +1,224 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/repository"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPatchUserRejectsAvatarURL(t *testing.T) {
+	user := testUser()
+	user.AvatarURL = "https://cdn.test/avatars/large.png"
+	user.Avatars = domain.AvatarVariants{domain.AvatarLarge: {Key: "avatars/large.png", URL: user.AvatarURL, Size: 512}}
+	repos := newMemRepos(user)
+	u := &authUsecase{uow: &memUnitOfWork{repos}}
+
+	patch := func(doc *domain.UserDocument) error {
+		doc.AvatarURL = "https://attacker.test/pixel.gif"
+		return nil
+	}
+	for name, writable := range map[string]domain.FieldSet{
+		"owner": domain.OwnerWritableUserFields,
+		"admin": domain.AdminWritableUserFields,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := u.PatchUser(context.Background(), user.ID, nil, writable, patch)
+			if !errors.Is(err, domain.ErrFieldNotWritable) {
+				t.Fatalf("err = %v, want %v", err, domain.ErrFieldNotWritable)
+			}
+			if got := repos.users.user.AvatarURL; got != user.AvatarURL {
+				t.Errorf("avatar_url changed to %q", got)
+			}
+		})
+	}
+}
+
+// noOrgs adalah OrganizationRepository untuk user tanpa organization
+type noOrgs struct {
+	repository.OrganizationRepository
+}
+
+func (noOrgs) ListForUser(context.Context, uuid.UUID) ([]domain.Membership, error) {
+	return nil, nil
+}
+
+const testPassword = "correct horse"
+
+// newLoginTest membuat authUsecase dengan satu user berpassword
+// testPassword yang dikunci setelah 3 password salah
+func newLoginTest(t *testing.T) (*authUsecase, *memRepos) {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := testUser()
+	user.Password = string(hash)
+	repos := newMemRepos(user)
+	return &authUsecase{
+		userRepo:    repos.users,
+		orgRepo:     noOrgs{},
+		uow:         &memUnitOfWork{repos},
+		jwtSecret:   []byte("secret"),
+		tokenExpiry: time.Hour,
+		maxFailures: 3,
+		lockout:     15 * time.Minute,
+	}, repos
+}
+
+func login(u *authUsecase, password string) error {
+	_, _, err := u.Login(context.Background(), &domain.LoginRequest{Email: "alice@example.com", Password: password})
+	return err
+}
+
+func TestLoginLocksAccountAtThreshold(t *testing.T) {
+	u, repos := newLoginTest(t)
+
+	for i, want := range []error{domain.ErrInvalidCredentials, domain.ErrInvalidCredentials, domain.ErrAccountLocked} {
+		if err := login(u, "wrong"); !errors.Is(err, want) {
+			t.Fatalf("attempt %d: err = %v, want %v", i+1, err, want)
+		}
+	}
+	if !repos.users.user.Locked(time.Now()) {
+		t.Fatal("account not locked after reaching the threshold")
+	}
+
+	// Password yang benar pun ditolak selama akun terkunci
+	if err := login(u, testPassword); !errors.Is(err, domain.ErrAccountLocked) {
+		t.Fatalf("correct password while locked: err = %v, want %v", err, domain.ErrAccountLocked)
+	}
+
+	var locked int
+	for _, entry := range repos.audit.entries {
+		if entry.Action == domain.AuditAccountLocked {
+			locked++
+		}
+	}
+	if locked != 1 {
+		t.Errorf("got %d %s audit entries, want 1", locked, domain.AuditAccountLocked)
+	}
+}
+
+func TestLoginSucceedsAfterLockExpires(t *testing.T) {
+	u, repos := newLoginTest(t)
+	expired := time.Now().Add(-time.Second)
+	repos.users.user.LockedUntil = &expired
+
+	if err := login(u, testPassword); err != nil {
+		t.Fatal(err)
+	}
+	if repos.users.user.LockedUntil != nil {
+		t.Error("expired lock not cleared after successful login")
+	}
+}
+
+func TestLoginResetsFailuresOnSuccess(t *testing.T) {
+	u, repos := newLoginTest(t)
+
+	for i := 0; i < 2; i++ {
+		if err := login(u, "wrong"); !errors.Is(err, domain.ErrInvalidCredentials) {
+			t.Fatalf("err = %v, want %v", err, domain.ErrInvalidCredentials)
+		}
+	}
+	if err := login(u, testPassword); err != nil {
+		t.Fatal(err)
+	}
+	if n := repos.users.user.FailedLogins; n != 0 {
+		t.Fatalf("failed logins = %d after success, want 0", n)
+	}
+
+	// Hitungan dimulai dari nol lagi, dua password salah belum mengunci
+	for i := 0; i < 2; i++ {
+		if err := login(u, "wrong"); !errors.Is(err, domain.ErrInvalidCredentials) {
+			t.Fatalf("err = %v, want %v", err, domain.ErrInvalidCredentials)
+		}
+	}
+}
+
+func TestCompleteLoginHonorsLockout(t *testing.T) {
+	methods := []string{domain.LoginMethodMagicLink, domain.LoginMethodPasskey, domain.LoginMethodSSO + "google"}
+
+	for _, method := range methods {
+		t.Run(method, func(t *testing.T) {
+			u, repos := newLoginTest(t)
+			user := *repos.users.user
+
+			// Dikunci setelah user diverifikasi, misalnya saat link dibuka
+			lockedUntil := time.Now().Add(time.Minute)
+			repos.users.user.LockedUntil = &lockedUntil
+			if _, _, err := u.CompleteLogin(context.Background(), &user, method); !errors.Is(err, domain.ErrAccountLocked) {
+				t.Fatalf("locked: err = %v, want %v", err, domain.ErrAccountLocked)
+			}
+
+			repos.users.user.LockedUntil = nil
+			repos.users.user.FailedLogins = 2
+			if _, _, err := u.CompleteLogin(context.Background(), &user, method); err != nil {
+				t.Fatal(err)
+			}
+			if n := repos.users.user.FailedLogins; n != 0 {
+				t.Errorf("failed logins = %d after %s login, want 0", n, method)
+			}
+		})
+	}
+}
+
+func TestUserWriteConflictFailsPrecondition(t *testing.T) {
+	name := "Bob"
+	writes := map[string]func(u *authUsecase, id uuid.UUID, match *domain.VersionMatch) error{
+		"update": func(u *authUsecase, id uuid.UUID, match *domain.VersionMatch) error {
+			req := &domain.UpdateRequest{ID: id, UpdateProfileRequest: domain.UpdateProfileRequest{Name: &name}}
+			_, err := u.UpdateUser(context.Background(), req, match)
+			return err
+		},
+		"patch": func(u *authUsecase, id uuid.UUID, match *domain.VersionMatch) error {
+			patch := func(doc *domain.UserDocument) error {
+				doc.Name = name
+				return nil
+			}
+			_, err := u.PatchUser(context.Background(), id, match, domain.AdminWritableUserFields, patch)
+			return err
+		},
+		"delete": func(u *authUsecase, id uuid.UUID, match *domain.VersionMatch) error {
+			return u.DeleteUser(context.Background(), id, match)
+		},
+	}
+
+	for name, write := range writes {
+		t.Run(name, func(t *testing.T) {
+			tests := []struct {
+				name  string
+				match func(version int64) *domain.VersionMatch
+				want  domain.ErrorKind
+			}{
+				// Versi cocok saat dibaca, tetapi request lain menyimpan lebih dulu
+				{"if-match", func(v int64) *domain.VersionMatch {
+					return &domain.VersionMatch{Versions: []int64{v}}
+				}, domain.KindPreconditionFailed},
+				{"stale if-match", func(v int64) *domain.VersionMatch {
+					return &domain.VersionMatch{Versions: []int64{v - 1}}
+				}, domain.KindPreconditionFailed},
+				{"no if-match", func(int64) *domain.VersionMatch { return nil }, domain.KindConflict},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					user := testUser()
+					user.Version = 3
+					repos := newMemRepos(user)
+					repos.users.updateErr = domain.ErrUserModified
+					u := &authUsecase{uow: &memUnitOfWork{repos}}
+
+					err := write(u, user.ID, tt.match(user.Version))
+					if kind := domain.KindOf(err); kind != tt.want {
+						t.Errorf("err = %v (kind %d), want kind %d", err, kind, tt.want)
+					}
+				})
+			}
+		})
+	}
+}
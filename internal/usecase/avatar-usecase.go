@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/repository"
+	"github.com/Hilmarch27/gin-api/pkg/imaging"
+	"github.com/Hilmarch27/gin-api/pkg/logger"
+	"github.com/Hilmarch27/gin-api/pkg/storage"
+	"github.com/google/uuid"
+)
+
+// avatarMaxPixels membatasi dimensi gambar sebelum decode penuh (40 MP)
+const avatarMaxPixels = 40_000_000
+
+// avatarSizes adalah varian yang dibuat dari setiap upload
+var avatarSizes = []struct {
+	name string
+	size int
+}{
+	{domain.AvatarLarge, 512},
+	{domain.AvatarMedium, 256},
+	{domain.AvatarSmall, 64},
+}
+
+type AvatarUsecase interface {
+	Upload(ctx context.Context, userID uuid.UUID, data []byte) (*domain.UserResponse, error)
+	Remove(ctx context.Context, userID uuid.UUID) error
+}
+
+type avatarUsecase struct {
+	uow     repository.UnitOfWork
+	storage storage.Storage
+}
+
+func NewAvatarUsecase(uow repository.UnitOfWork, s storage.Storage) AvatarUsecase {
+	return &avatarUsecase{
+		uow:     uow,
+		storage: s,
+	}
+}
+
+// Upload mendekode gambar, membuat varian persegi (tanpa metadata EXIF),
+// menyimpannya ke storage lalu mengganti avatar user
+func (u *avatarUsecase) Upload(ctx context.Context, userID uuid.UUID, data []byte) (_ *domain.UserResponse, err error) {
+	ctx, span := tracer.Start(ctx, "avatarUsecase.Upload")
+	defer func() { endSpan(span, err) }()
+
+	_, decodeSpan := tracer.Start(ctx, "imaging.Decode")
+	img, err := imaging.Decode(data, avatarMaxPixels)
+	decodeSpan.End()
+	switch {
+	case errors.Is(err, imaging.ErrUnsupportedFormat):
+		return nil, domain.ErrUnsupportedImage
+	case errors.Is(err, imaging.ErrTooManyPixels):
+		return nil, domain.ErrFileTooLarge.Wrap(err)
+	case err != nil:
+		return nil, domain.ErrInvalidImage.Wrap(err)
+	}
+
+	// Setiap upload memakai prefix baru agar URL lama tetap valid di cache
+	// sampai response baru sampai ke client
+	prefix := fmt.Sprintf("avatars/%s/%s", userID, uuid.NewString())
+	variants := domain.AvatarVariants{}
+	for _, s := range avatarSizes {
+		resized := imaging.Square(img, s.size)
+		encoded, contentType, ext, err := imaging.Encode(resized)
+		if err != nil {
+			u.removeObjects(ctx, variants)
+			return nil, err
+		}
+
+		key := fmt.Sprintf("%s/%s.%s", prefix, s.name, ext)
+		if err := u.storage.Put(ctx, key, bytes.NewReader(encoded), int64(len(encoded)), contentType); err != nil {
+			u.removeObjects(ctx, variants)
+			return nil, fmt.Errorf("store avatar %s: %w", s.name, err)
+		}
+		variants[s.name] = domain.AvatarVariant{Key: key, URL: u.storage.URL(key), Size: resized.Bounds().Dx()}
+	}
+
+	var (
+		user *domain.User
+		old  domain.AvatarVariants
+	)
+	err = u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		var err error
+		user, err = repos.Users().FindById(ctx, userID)
+		if err != nil {
+			return err
+		}
+		before := *user
+		old = user.Avatars
+
+		user.Avatars = variants
+		user.AvatarURL = variants[domain.AvatarLarge].URL
+		return saveUser(ctx, repos, &before, user)
+	})
+	if err != nil {
+		// Object baru tidak direferensikan siapa pun
+		u.removeObjects(ctx, variants)
+		return nil, err
+	}
+
+	u.removeObjects(ctx, old)
+	return domain.NewUserResponse(user), nil
+}
+
+// Remove menghapus avatar upload milik user
+func (u *avatarUsecase) Remove(ctx context.Context, userID uuid.UUID) (err error) {
+	ctx, span := tracer.Start(ctx, "avatarUsecase.Remove")
+	defer func() { endSpan(span, err) }()
+
+	var old domain.AvatarVariants
+	err = u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		user, err := repos.Users().FindById(ctx, userID)
+		if err != nil {
+			return err
+		}
+		before := *user
+		old = user.Avatars
+
+		user.Avatars = nil
+		user.AvatarURL = ""
+		return saveUser(ctx, repos, &before, user)
+	})
+	if err != nil {
+		return err
+	}
+
+	u.removeObjects(ctx, old)
+	return nil
+}
+
+// removeObjects menghapus object avatar secara best-effort; kegagalan hanya
+// dicatat karena data user sudah konsisten
+func (u *avatarUsecase) removeObjects(ctx context.Context, variants domain.AvatarVariants) {
+	for _, v := range variants {
+		if err := u.storage.Delete(context.WithoutCancel(ctx), v.Key); err != nil {
+			logger.FromContext(ctx).Warn("delete avatar object", "key", v.Key, "error", err)
+		}
+	}
+}
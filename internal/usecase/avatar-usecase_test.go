@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+)
+
+// memStorage adalah storage.Storage di memori
+type memStorage struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newMemStorage() *memStorage {
+	return &memStorage{objects: map[string][]byte{}}
+}
+
+func (s *memStorage) Put(_ context.Context, key string, r io.Reader, _ int64, _ string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = data
+	return nil
+}
+
+func (s *memStorage) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *memStorage) URL(key string) string {
+	return "https://cdn.test/" + key
+}
+
+func (s *memStorage) keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]string, 0, len(s.objects))
+	for k := range s.objects {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func newAvatarTest(t *testing.T) (*avatarUsecase, *memStorage, *memRepos) {
+	t.Helper()
+	repos := newMemRepos(testUser())
+	store := newMemStorage()
+	return &avatarUsecase{uow: &memUnitOfWork{repos}, storage: store}, store, repos
+}
+
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 0x80, 0xff})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestAvatarUploadStoresVariants(t *testing.T) {
+	u, store, repos := newAvatarTest(t)
+	userID := repos.users.user.ID
+
+	resp, err := u.Upload(context.Background(), userID, testPNG(t, 1000, 800))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saved := repos.users.user
+	if len(saved.Avatars) != len(avatarSizes) {
+		t.Fatalf("got %d variants, want %d", len(saved.Avatars), len(avatarSizes))
+	}
+	for _, s := range avatarSizes {
+		v, ok := saved.Avatars[s.name]
+		if !ok {
+			t.Fatalf("missing variant %s", s.name)
+		}
+		if v.Size != s.size {
+			t.Errorf("variant %s size = %d, want %d", s.name, v.Size, s.size)
+		}
+		if _, ok := store.objects[v.Key]; !ok {
+			t.Errorf("variant %s not stored at %s", s.name, v.Key)
+		}
+	}
+	if want := saved.Avatars[domain.AvatarLarge].URL; saved.AvatarURL != want || resp.AvatarURL != want {
+		t.Errorf("avatar_url = %q (response %q), want %q", saved.AvatarURL, resp.AvatarURL, want)
+	}
+	if len(repos.audit.entries) != 1 || repos.audit.entries[0].Action != domain.AuditUserUpdated {
+		t.Errorf("expected one %s audit entry, got %v", domain.AuditUserUpdated, repos.audit.entries)
+	}
+}
+
+func TestAvatarUploadReplacesOldObjects(t *testing.T) {
+	u, store, repos := newAvatarTest(t)
+	userID := repos.users.user.ID
+
+	if _, err := u.Upload(context.Background(), userID, testPNG(t, 100, 100)); err != nil {
+		t.Fatal(err)
+	}
+	first := repos.users.user.Avatars
+
+	if _, err := u.Upload(context.Background(), userID, testPNG(t, 200, 100)); err != nil {
+		t.Fatal(err)
+	}
+	for name, v := range first {
+		if _, ok := store.objects[v.Key]; ok {
+			t.Errorf("old variant %s still stored at %s", name, v.Key)
+		}
+	}
+	if got := len(store.keys()); got != len(avatarSizes) {
+		t.Errorf("storage holds %d objects, want %d: %v", got, len(avatarSizes), store.keys())
+	}
+}
+
+func TestAvatarUploadCleansUpOnSaveError(t *testing.T) {
+	u, store, repos := newAvatarTest(t)
+	repos.users.updateErr = errors.New("database down")
+
+	if _, err := u.Upload(context.Background(), repos.users.user.ID, testPNG(t, 100, 100)); err == nil {
+		t.Fatal("expected error")
+	}
+	if keys := store.keys(); len(keys) != 0 {
+		t.Errorf("objects left in storage: %v", keys)
+	}
+}
+
+func TestAvatarUploadRejectsNonImage(t *testing.T) {
+	u, store, repos := newAvatarTest(t)
+
+	_, err := u.Upload(context.Background(), repos.users.user.ID, []byte("not an image"))
+	if !errors.Is(err, domain.ErrUnsupportedImage) {
+		t.Fatalf("err = %v, want %v", err, domain.ErrUnsupportedImage)
+	}
+	if keys := store.keys(); len(keys) != 0 {
+		t.Errorf("objects stored for rejected upload: %v", keys)
+	}
+}
+
+func TestAvatarRemove(t *testing.T) {
+	u, store, repos := newAvatarTest(t)
+	userID := repos.users.user.ID
+
+	if _, err := u.Upload(context.Background(), userID, testPNG(t, 100, 100)); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Remove(context.Background(), userID); err != nil {
+		t.Fatal(err)
+	}
+
+	saved := repos.users.user
+	if saved.AvatarURL != "" || len(saved.Avatars) != 0 {
+		t.Errorf("avatar not cleared: url=%q variants=%v", saved.AvatarURL, saved.Avatars)
+	}
+	if keys := store.keys(); len(keys) != 0 {
+		t.Errorf("objects left in storage: %v", keys)
+	}
+}
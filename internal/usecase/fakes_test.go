@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+// Fake repository di memori untuk test usecase. Method yang tidak
+// di-override memanggil interface nil dan panic, sehingga test yang
+// memakai method baru langsung terlihat.
+
+// memUsers menyimpan satu user; method repository lain tidak dipakai test
+type memUsers struct {
+	repository.UserRepository
+	user      *domain.User
+	updateErr error // dikembalikan Update dan Delete
+}
+
+func (r *memUsers) FindById(_ context.Context, id uuid.UUID) (*domain.User, error) {
+	if r.user == nil || r.user.ID != id {
+		return nil, domain.ErrUserNotFound
+	}
+	user := *r.user
+	return &user, nil
+}
+
+func (r *memUsers) FindByEmail(_ context.Context, email string) (*domain.User, error) {
+	if r.user == nil || domain.NormalizeEmail(r.user.Email) != domain.NormalizeEmail(email) {
+		return nil, domain.ErrUserNotFound
+	}
+	user := *r.user
+	return &user, nil
+}
+
+func (r *memUsers) Create(_ context.Context, user *domain.User) error {
+	if r.user != nil {
+		return domain.ErrEmailTaken
+	}
+	user.ID = uuid.New()
+	saved := *user
+	r.user = &saved
+	return nil
+}
+
+func (r *memUsers) Delete(_ context.Context, id uuid.UUID, version int64) error {
+	if r.updateErr != nil {
+		return r.updateErr
+	}
+	if r.user == nil || r.user.ID != id {
+		return domain.ErrUserNotFound
+	}
+	if r.user.Version != version {
+		return domain.ErrUserModified
+	}
+	r.user = nil
+	return nil
+}
+
+// RecordLoginFailure dan ResetLoginFailures meniru query di
+// userRepository
+func (r *memUsers) RecordLoginFailure(_ context.Context, id uuid.UUID, maxFailures int, lockedUntil time.Time) (bool, error) {
+	if r.user == nil || r.user.ID != id {
+		return false, domain.ErrUserNotFound
+	}
+	r.user.FailedLogins++
+	if r.user.FailedLogins < maxFailures {
+		return false, nil
+	}
+	r.user.FailedLogins = 0
+	r.user.LockedUntil = &lockedUntil
+	return true, nil
+}
+
+func (r *memUsers) ResetLoginFailures(_ context.Context, id uuid.UUID) error {
+	if r.user != nil && r.user.ID == id {
+		r.user.FailedLogins = 0
+		r.user.LockedUntil = nil
+	}
+	return nil
+}
+
+func (r *memUsers) Update(_ context.Context, user *domain.User) error {
+	if r.updateErr != nil {
+		return r.updateErr
+	}
+	saved := *user
+	saved.Version++
+	r.user = &saved
+	return nil
+}
+
+type memAudit struct {
+	repository.AuditRepository
+	entries []*domain.AuditLog
+}
+
+func (r *memAudit) Append(_ context.Context, entry *domain.AuditLog) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+type memRepos struct {
+	repository.Repositories
+	users      *memUsers
+	audit      *memAudit
+	identities repository.IdentityRepository
+	apiKeys    repository.APIKeyRepository
+	grants     repository.OAuthGrantRepository
+}
+
+func (r *memRepos) Users() repository.UserRepository             { return r.users }
+func (r *memRepos) Audit() repository.AuditRepository            { return r.audit }
+func (r *memRepos) Identities() repository.IdentityRepository    { return r.identities }
+func (r *memRepos) APIKeys() repository.APIKeyRepository         { return r.apiKeys }
+func (r *memRepos) OAuthGrants() repository.OAuthGrantRepository { return r.grants }
+
+// memUnitOfWork menjalankan fn langsung tanpa transaksi
+type memUnitOfWork struct {
+	repos *memRepos
+}
+
+func (u *memUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos repository.Repositories) error) error {
+	return fn(ctx, u.repos)
+}
+
+// newMemRepos membuat repository di memori dengan satu user
+func newMemRepos(user *domain.User) *memRepos {
+	return &memRepos{users: &memUsers{user: user}, audit: &memAudit{}}
+}
+
+// testUser membuat user biasa yang valid untuk test
+func testUser() *domain.User {
+	return &domain.User{ID: uuid.New(), Name: "Alice", Email: "alice@example.com", Role: "user", Locale: "en", Timezone: "UTC"}
+}
@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/repository"
+)
+
+// idempotencyStaleAfter adalah umur maksimum record in_progress sebelum
+// dianggap ditinggalkan (misalnya server mati di tengah request)
+const idempotencyStaleAfter = 5 * time.Minute
+
+type IdempotencyUsecase interface {
+	Begin(ctx context.Context, record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error)
+	Complete(ctx context.Context, record *domain.IdempotencyRecord) error
+	Release(ctx context.Context, scope, key string) error
+	RunCleanup(ctx context.Context, interval time.Duration, log *slog.Logger)
+}
+
+type idempotencyUsecase struct {
+	repo repository.IdempotencyRepository
+	ttl  time.Duration
+}
+
+func NewIdempotencyUsecase(repo repository.IdempotencyRepository, ttl time.Duration) IdempotencyUsecase {
+	return &idempotencyUsecase{repo: repo, ttl: ttl}
+}
+
+// Begin mengklaim key untuk request baru. Hasil nil berarti request boleh
+// diproses; record completed berarti response-nya harus di-replay.
+func (u *idempotencyUsecase) Begin(ctx context.Context, record *domain.IdempotencyRecord) (_ *domain.IdempotencyRecord, err error) {
+	ctx, span := tracer.Start(ctx, "idempotencyUsecase.Begin")
+	defer func() { endSpan(span, err) }()
+
+	// Presisi timestamp Postgres adalah mikrodetik
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	record.State = domain.IdempotencyInProgress
+	record.CreatedAt = now
+	record.ExpiresAt = now.Add(u.ttl)
+
+	return u.repo.Begin(ctx, record, now.Add(-idempotencyStaleAfter))
+}
+
+func (u *idempotencyUsecase) Complete(ctx context.Context, record *domain.IdempotencyRecord) (err error) {
+	ctx, span := tracer.Start(ctx, "idempotencyUsecase.Complete")
+	defer func() { endSpan(span, err) }()
+
+	return u.repo.Complete(ctx, record)
+}
+
+func (u *idempotencyUsecase) Release(ctx context.Context, scope, key string) (err error) {
+	ctx, span := tracer.Start(ctx, "idempotencyUsecase.Release")
+	defer func() { endSpan(span, err) }()
+
+	return u.repo.Release(ctx, scope, key)
+}
+
+// RunCleanup menghapus record yang sudah expired setiap interval sampai
+// ctx dibatalkan
+func (u *idempotencyUsecase) RunCleanup(ctx context.Context, interval time.Duration, log *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := u.repo.DeleteExpired(ctx, time.Now())
+			if err != nil {
+				log.Error("failed to delete expired idempotency records", "error", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Info("deleted expired idempotency records", "count", deleted)
+			}
+		}
+	}
+}
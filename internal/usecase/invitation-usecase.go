@@ -0,0 +1,489 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/repository"
+	"github.com/Hilmarch27/gin-api/pkg/i18n"
+	"github.com/Hilmarch27/gin-api/pkg/logger"
+	"github.com/Hilmarch27/gin-api/pkg/mailer"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// invitationTokenType membedakan token invitation dari access/refresh token
+// yang ditandatangani dengan secret yang sama
+const invitationTokenType = "invitation"
+
+type InvitationUsecase interface {
+	Create(ctx context.Context, actor domain.Tenant, inviterID uuid.UUID, req *domain.CreateInvitationRequest) (*domain.InvitationResponse, error)
+	List(ctx context.Context, filter domain.InvitationFilter) ([]*domain.InvitationResponse, error)
+	Resend(ctx context.Context, actor domain.Tenant, id uuid.UUID) (*domain.InvitationResponse, error)
+	Revoke(ctx context.Context, actor domain.Tenant, id uuid.UUID) error
+	Preview(ctx context.Context, token string) (*domain.InvitationPreview, error)
+	Accept(ctx context.Context, req *domain.AcceptInvitationRequest, userID *uuid.UUID) (*domain.User, *domain.Membership, error)
+}
+
+type invitationUsecase struct {
+	invitationRepo repository.InvitationRepository
+	userRepo       repository.UserRepository
+	uow            repository.UnitOfWork
+	mailer         mailer.Mailer
+	jwtSecret      []byte
+	ttl            time.Duration
+	acceptURL      string
+}
+
+// NewInvitationUsecase membuat usecase invitation. Link di email adalah
+// acceptURL dengan query token, dan invitation berlaku selama ttl sejak
+// terakhir dikirim.
+func NewInvitationUsecase(ir repository.InvitationRepository, ur repository.UserRepository, uow repository.UnitOfWork, m mailer.Mailer, secret string, ttl time.Duration, acceptURL string) InvitationUsecase {
+	return &invitationUsecase{
+		invitationRepo: ir,
+		userRepo:       ur,
+		uow:            uow,
+		mailer:         m,
+		jwtSecret:      []byte(secret),
+		ttl:            ttl,
+		acceptURL:      acceptURL,
+	}
+}
+
+// Create mengundang email ke organization actor. Hanya owner yang boleh
+// mengundang owner, dan invitation expired untuk email yang sama otomatis
+// dibatalkan.
+func (u *invitationUsecase) Create(ctx context.Context, actor domain.Tenant, inviterID uuid.UUID, req *domain.CreateInvitationRequest) (_ *domain.InvitationResponse, err error) {
+	ctx, span := tracer.Start(ctx, "invitationUsecase.Create")
+	defer func() { endSpan(span, err) }()
+
+	if req.Role == domain.OrgRoleOwner && actor.Role != domain.OrgRoleOwner {
+		return nil, domain.ErrOwnerRequired
+	}
+
+	now := time.Now()
+	email := strings.TrimSpace(req.Email)
+
+	var (
+		inv   *domain.Invitation
+		token string
+	)
+	err = u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		// Email yang sudah menjadi anggota tidak perlu diundang
+		_, err := repos.Users().FindByEmail(domain.WithTenant(ctx, domain.Tenant{ID: actor.ID}), email)
+		if err == nil {
+			return domain.ErrMemberExists
+		}
+		if !errors.Is(err, domain.ErrUserNotFound) {
+			return err
+		}
+
+		open, err := repos.Invitations().FindOpen(ctx, actor.ID, email)
+		switch {
+		case err == nil && open.Status(now) == domain.InvitationPending:
+			return domain.ErrInvitationPending
+		case err == nil:
+			open.RevokedAt = &now
+			if err := repos.Invitations().Update(ctx, open); err != nil {
+				return err
+			}
+		case !errors.Is(err, domain.ErrInvitationNotFound):
+			return err
+		}
+
+		inv = &domain.Invitation{
+			ID:             uuid.New(),
+			OrganizationID: actor.ID,
+			Email:          email,
+			Role:           req.Role,
+			InviterID:      inviterID,
+		}
+		if token, err = u.issueToken(inv, now); err != nil {
+			return err
+		}
+		if err := repos.Invitations().Create(ctx, inv); err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, domain.AuditInvitationCreated, nil)
+		entry.Metadata = invitationMetadata(inv)
+		if err := repos.Audit().Append(ctx, entry); err != nil {
+			return err
+		}
+
+		// Baca ulang bersama organization dan pengundang untuk email
+		inv, err = repos.Invitations().FindByID(ctx, inv.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go u.sendInvitation(logger.FromContext(ctx), i18n.FromContext(ctx), inv, token)
+
+	return domain.NewInvitationResponse(inv, now), nil
+}
+
+func (u *invitationUsecase) List(ctx context.Context, filter domain.InvitationFilter) (_ []*domain.InvitationResponse, err error) {
+	ctx, span := tracer.Start(ctx, "invitationUsecase.List")
+	defer func() { endSpan(span, err) }()
+
+	now := time.Now()
+	invitations, err := u.invitationRepo.List(ctx, filter, now)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*domain.InvitationResponse, len(invitations))
+	for i := range invitations {
+		responses[i] = domain.NewInvitationResponse(&invitations[i], now)
+	}
+	return responses, nil
+}
+
+// Resend menerbitkan token baru (token lama tidak berlaku lagi),
+// memperpanjang masa berlaku dan mengirim ulang email invitation
+func (u *invitationUsecase) Resend(ctx context.Context, actor domain.Tenant, id uuid.UUID) (_ *domain.InvitationResponse, err error) {
+	ctx, span := tracer.Start(ctx, "invitationUsecase.Resend")
+	defer func() { endSpan(span, err) }()
+
+	now := time.Now()
+	var (
+		inv   *domain.Invitation
+		token string
+	)
+	err = u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		var err error
+		inv, err = findInvitation(ctx, repos, actor, id)
+		if err != nil {
+			return err
+		}
+		if status := inv.Status(now); status == domain.InvitationAccepted || status == domain.InvitationRevoked {
+			return domain.ErrInvitationClosed
+		}
+
+		if token, err = u.issueToken(inv, now); err != nil {
+			return err
+		}
+		if err := repos.Invitations().Update(ctx, inv); err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, domain.AuditInvitationResent, nil)
+		entry.Metadata = invitationMetadata(inv)
+		return repos.Audit().Append(ctx, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go u.sendInvitation(logger.FromContext(ctx), i18n.FromContext(ctx), inv, token)
+
+	return domain.NewInvitationResponse(inv, now), nil
+}
+
+// Revoke membatalkan invitation yang belum diterima
+func (u *invitationUsecase) Revoke(ctx context.Context, actor domain.Tenant, id uuid.UUID) (err error) {
+	ctx, span := tracer.Start(ctx, "invitationUsecase.Revoke")
+	defer func() { endSpan(span, err) }()
+
+	return u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		inv, err := findInvitation(ctx, repos, actor, id)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		inv.RevokedAt = &now
+		if err := repos.Invitations().Update(ctx, inv); err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, domain.AuditInvitationRevoked, nil)
+		entry.Metadata = invitationMetadata(inv)
+		return repos.Audit().Append(ctx, entry)
+	})
+}
+
+// Preview menampilkan isi invitation ke pemegang token
+func (u *invitationUsecase) Preview(ctx context.Context, token string) (_ *domain.InvitationPreview, err error) {
+	ctx, span := tracer.Start(ctx, "invitationUsecase.Preview")
+	defer func() { endSpan(span, err) }()
+
+	inv, err := u.verifyToken(ctx, u.invitationRepo, token, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = u.userRepo.FindByEmail(domain.WithoutTenant(ctx), inv.Email)
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return nil, err
+	}
+
+	return &domain.InvitationPreview{
+		Organization:  *domain.NewOrganizationResponse(inv.Organization, ""),
+		Email:         inv.Email,
+		Role:          inv.Role,
+		InviterName:   inviterName(inv),
+		ExpiresAt:     inv.ExpiresAt,
+		AccountExists: err == nil,
+	}, nil
+}
+
+// Accept menerima invitation dan menambahkan user sebagai anggota. userID
+// adalah user yang sedang login (email-nya harus sama dengan invitation).
+// Tanpa login, password akun dengan email invitation diperiksa, atau akun
+// baru dibuat dari name dan password jika email belum terdaftar.
+func (u *invitationUsecase) Accept(ctx context.Context, req *domain.AcceptInvitationRequest, userID *uuid.UUID) (_ *domain.User, _ *domain.Membership, err error) {
+	ctx, span := tracer.Start(ctx, "invitationUsecase.Accept")
+	defer func() { endSpan(span, err) }()
+
+	// Akun dicari secara global, bukan dalam tenant aktif request
+	ctx = domain.WithoutTenant(ctx)
+
+	var (
+		user       *domain.User
+		membership *domain.Membership
+	)
+	err = u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		now := time.Now()
+		inv, err := u.verifyToken(ctx, repos.Invitations(), req.Token, now)
+		if err != nil {
+			return err
+		}
+
+		if user, err = u.invitee(ctx, repos, inv, req, userID); err != nil {
+			return err
+		}
+
+		membership = &domain.Membership{OrganizationID: inv.OrganizationID, UserID: user.ID, Role: inv.Role}
+		if err := repos.Organizations().AddMember(ctx, membership); err != nil {
+			return err
+		}
+		membership.Organization = inv.Organization
+
+		inv.AcceptedAt = &now
+		inv.AcceptedBy = &user.ID
+		if err := repos.Invitations().Update(ctx, inv); err != nil {
+			return err
+		}
+
+		// Actor penerimaan adalah user itu sendiri, termasuk tanpa login
+		entry := newAuditEntry(ctx, domain.AuditInvitationAccepted, &user.ID)
+		entry.ActorID = &user.ID
+		entry.ActorType = domain.PrincipalUser
+		entry.Metadata = invitationMetadata(inv)
+		return repos.Audit().Append(ctx, entry)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, membership, nil
+}
+
+// invitee menentukan user yang menerima invitation: user yang sedang login,
+// akun lama dengan email invitation (password diperiksa), atau akun baru
+func (u *invitationUsecase) invitee(ctx context.Context, repos repository.Repositories, inv *domain.Invitation, req *domain.AcceptInvitationRequest, userID *uuid.UUID) (*domain.User, error) {
+	if userID != nil {
+		user, err := repos.Users().FindById(ctx, *userID)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(user.Email, inv.Email) {
+			return nil, domain.ErrInvitationEmail
+		}
+		return user, nil
+	}
+
+	user, err := repos.Users().FindByEmail(ctx, inv.Email)
+	if err == nil {
+		_, compareSpan := tracer.Start(ctx, "bcrypt.CompareHashAndPassword")
+		err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
+		compareSpan.End()
+		if err != nil {
+			return nil, domain.ErrInvalidCredentials
+		}
+		return user, nil
+	}
+	if !errors.Is(err, domain.ErrUserNotFound) {
+		return nil, err
+	}
+
+	// Email belum terdaftar: sign-up dengan email dari invitation
+	if req.Name == "" || req.Password == "" {
+		return nil, domain.ErrSignupDetails
+	}
+
+	_, hashSpan := tracer.Start(ctx, "bcrypt.GenerateFromPassword")
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashSpan.End()
+	if err != nil {
+		return nil, err
+	}
+
+	// Token invitation dikirim ke email tersebut, sehingga kepemilikannya
+	// sudah terbukti
+	now := time.Now()
+	user = &domain.User{
+		Name:            req.Name,
+		Email:           domain.NormalizeEmail(inv.Email),
+		Locale:          i18n.FromContext(ctx),
+		Password:        string(hashedPassword),
+		EmailVerifiedAt: &now,
+	}
+	if err := repos.Users().Create(ctx, user); err != nil {
+		return nil, err
+	}
+
+	entry := newAuditEntry(ctx, domain.AuditUserRegistered, &user.ID)
+	entry.Changes = userChanges(&domain.User{}, user)
+	entry.Metadata = domain.AuditMetadata{"invitation_id": inv.ID.String()}
+	if err := repos.Audit().Append(ctx, entry); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// issueToken membuat token invitation yang ditandatangani, lalu menyimpan
+// hash dan masa berlakunya di inv
+func (u *invitationUsecase) issueToken(inv *domain.Invitation, now time.Time) (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	inv.ExpiresAt = now.Add(u.ttl)
+	claims := jwt.MapClaims{
+		"typ": invitationTokenType,
+		"inv": inv.ID,
+		"jti": base64.RawURLEncoding.EncodeToString(nonce),
+		"exp": inv.ExpiresAt.Unix(),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(u.jwtSecret)
+	if err != nil {
+		return "", err
+	}
+
+	inv.TokenHash = hashToken(token)
+	inv.SentCount++
+	inv.LastSentAt = &now
+	return token, nil
+}
+
+// verifyToken memeriksa tanda tangan token lalu mencocokkannya dengan
+// invitation di database. Masa berlaku dan status dibaca dari database agar
+// resend dan revoke langsung berlaku.
+func (u *invitationUsecase) verifyToken(ctx context.Context, repo repository.InvitationRepository, token string, now time.Time) (*domain.Invitation, error) {
+	parser := jwt.NewParser(jwt.WithoutClaimsValidation())
+	parsed, err := parser.Parse(token, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("invalid signing method")
+		}
+		return u.jwtSecret, nil
+	})
+	if err != nil {
+		return nil, domain.ErrInvalidInvitation.Wrap(err)
+	}
+
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != invitationTokenType {
+		return nil, domain.ErrInvalidInvitation
+	}
+	idStr, _ := claims["inv"].(string)
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return nil, domain.ErrInvalidInvitation.Wrap(err)
+	}
+
+	inv, err := repo.FindByID(ctx, id)
+	if errors.Is(err, domain.ErrInvitationNotFound) {
+		return nil, domain.ErrInvalidInvitation.Wrap(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Token lama (sebelum resend) punya hash berbeda
+	if subtle.ConstantTimeCompare([]byte(inv.TokenHash), []byte(hashToken(token))) != 1 {
+		return nil, domain.ErrInvalidInvitation
+	}
+
+	switch inv.Status(now) {
+	case domain.InvitationAccepted, domain.InvitationRevoked:
+		return nil, domain.ErrInvitationClosed
+	case domain.InvitationExpired:
+		return nil, domain.ErrInvitationExpired
+	}
+	return inv, nil
+}
+
+func (u *invitationUsecase) sendInvitation(log *slog.Logger, locale string, inv *domain.Invitation, token string) {
+	log = log.With("invitation_id", inv.ID.String())
+
+	link := u.acceptURL + "?token=" + url.QueryEscape(token)
+	if strings.Contains(u.acceptURL, "?") {
+		link = u.acceptURL + "&token=" + url.QueryEscape(token)
+	}
+
+	email, err := i18n.RenderEmail(locale, "invitation", map[string]any{
+		"OrganizationName": inv.Organization.Name,
+		"InviterName":      inviterName(inv),
+		"Role":             inv.Role,
+		"URL":              link,
+		"ExpiresAt":        inv.ExpiresAt.UTC(),
+	})
+	if err != nil {
+		log.Error("render invitation email", "error", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	err = u.mailer.Send(ctx, &mailer.Message{To: inv.Email, Subject: email.Subject, Text: email.Text})
+	if err != nil {
+		log.Error("send invitation email", "error", err)
+	}
+}
+
+// findInvitation membaca invitation milik organization actor. Invitation
+// organization lain dianggap tidak ada, dan invitation owner hanya boleh
+// dikelola owner.
+func findInvitation(ctx context.Context, repos repository.Repositories, actor domain.Tenant, id uuid.UUID) (*domain.Invitation, error) {
+	inv, err := repos.Invitations().FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if inv.OrganizationID != actor.ID {
+		return nil, domain.ErrInvitationNotFound
+	}
+	if inv.Role == domain.OrgRoleOwner && actor.Role != domain.OrgRoleOwner {
+		return nil, domain.ErrOwnerRequired
+	}
+	return inv, nil
+}
+
+// inviterName mengembalikan nama pengundang, atau nama organization jika
+// akun pengundang sudah dihapus
+func inviterName(inv *domain.Invitation) string {
+	if inv.Inviter != nil {
+		return inv.Inviter.Name
+	}
+	return inv.Organization.Name
+}
+
+func invitationMetadata(inv *domain.Invitation) domain.AuditMetadata {
+	meta := organizationMetadata(inv.OrganizationID, inv.Role)
+	meta["invitation_id"] = inv.ID.String()
+	meta["email"] = inv.Email
+	return meta
+}
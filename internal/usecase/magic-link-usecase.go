@@ -0,0 +1,305 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/repository"
+	"github.com/Hilmarch27/gin-api/pkg/i18n"
+	"github.com/Hilmarch27/gin-api/pkg/logger"
+	"github.com/Hilmarch27/gin-api/pkg/mailer"
+	"github.com/Hilmarch27/gin-api/pkg/metrics"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+const (
+	// magicLinkTokenType membedakan token magic link dari access/refresh
+	// token yang ditandatangani dengan secret yang sama
+	magicLinkTokenType = "magic_link"
+
+	// magicLinkSendTimeout membatasi pengiriman di background, termasuk
+	// query database dan pengiriman email
+	magicLinkSendTimeout = time.Minute
+)
+
+type MagicLinkUsecase interface {
+	Send(ctx context.Context, req *domain.MagicLinkRequest) error
+	Verify(ctx context.Context, req *domain.VerifyMagicLinkRequest) (*domain.User, error)
+	RunCleanup(ctx context.Context, interval time.Duration, log *slog.Logger)
+}
+
+type magicLinkUsecase struct {
+	magicLinkRepo repository.MagicLinkRepository
+	userRepo      repository.UserRepository
+	uow           repository.UnitOfWork
+	mailer        mailer.Mailer
+	jwtSecret     []byte
+	ttl           time.Duration
+	linkURL       string
+	maxSends      int
+	window        time.Duration
+}
+
+// NewMagicLinkUsecase membuat usecase login lewat magic link. Link di email
+// adalah linkURL dengan query token dan berlaku selama ttl. Setiap alamat
+// email paling banyak dikirimi maxSends link dalam satu window.
+func NewMagicLinkUsecase(mr repository.MagicLinkRepository, ur repository.UserRepository, uow repository.UnitOfWork, m mailer.Mailer, secret string, ttl time.Duration, linkURL string, maxSends int, window time.Duration) MagicLinkUsecase {
+	return &magicLinkUsecase{
+		magicLinkRepo: mr,
+		userRepo:      ur,
+		uow:           uow,
+		mailer:        m,
+		jwtSecret:     []byte(secret),
+		ttl:           ttl,
+		linkURL:       linkURL,
+		maxSends:      maxSends,
+		window:        window,
+	}
+}
+
+// Send menjadwalkan pengiriman magic link ke email lalu langsung kembali.
+// Pencarian user, throttle dan pengiriman berjalan di background, sehingga
+// response (isi maupun waktunya) tidak mengungkap apakah akun dengan email
+// tersebut ada. Kegagalan hanya dicatat di log.
+func (u *magicLinkUsecase) Send(ctx context.Context, req *domain.MagicLinkRequest) error {
+	email := domain.NormalizeEmail(req.Email)
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		ctx, cancel := context.WithTimeout(ctx, magicLinkSendTimeout)
+		defer cancel()
+		if err := u.send(ctx, email); err != nil {
+			logger.FromContext(ctx).Error("send magic link", "error", err)
+		}
+	}()
+	return nil
+}
+
+// send membuat dan mengirim magic link jika email terdaftar dan belum
+// melewati batas throttle
+func (u *magicLinkUsecase) send(ctx context.Context, email string) (err error) {
+	ctx, span := tracer.Start(ctx, "magicLinkUsecase.Send")
+	defer func() { endSpan(span, err) }()
+
+	user, err := u.userRepo.FindByEmail(ctx, email)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var (
+		link      *domain.MagicLink
+		token     string
+		throttled bool
+	)
+	err = u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		// Request bersamaan untuk email yang sama menunggu di sini agar
+		// hitungan dan insert di bawah tidak berselang-seling
+		if err := repos.MagicLinks().LockEmail(ctx, user.Email); err != nil {
+			return err
+		}
+		sent, err := repos.MagicLinks().CountSince(ctx, user.Email, now.Add(-u.window))
+		if err != nil {
+			return err
+		}
+		if sent >= int64(u.maxSends) {
+			throttled = true
+			return nil
+		}
+
+		link = &domain.MagicLink{ID: uuid.New(), UserID: user.ID, Email: user.Email}
+		if token, err = u.issueToken(link, now); err != nil {
+			return err
+		}
+		if err := repos.MagicLinks().Create(ctx, link); err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, domain.AuditMagicLinkSent, &user.ID)
+		entry.Metadata = domain.AuditMetadata{
+			"magic_link_id": link.ID.String(),
+			"email":         user.Email,
+		}
+		return repos.Audit().Append(ctx, entry)
+	})
+	if err != nil {
+		return err
+	}
+
+	if throttled {
+		logger.FromContext(ctx).Warn("magic link throttled", "user_id", user.ID.String())
+		return nil
+	}
+
+	u.sendMagicLink(ctx, user, link, token)
+	return nil
+}
+
+// Verify menukar token magic link dengan user pemiliknya. Link langsung
+// ditandai terpakai, sehingga token yang sama tidak bisa dipakai lagi.
+// Token diterbitkan handler lewat AuthUsecase.CompleteLogin.
+func (u *magicLinkUsecase) Verify(ctx context.Context, req *domain.VerifyMagicLinkRequest) (_ *domain.User, err error) {
+	ctx, span := tracer.Start(ctx, "magicLinkUsecase.Verify")
+	defer func() { endSpan(span, err) }()
+
+	userID, err := u.parseToken(req.Token)
+	if err != nil {
+		return nil, u.loginFailed(ctx, err)
+	}
+
+	var user *domain.User
+	err = u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		link, err := repos.MagicLinks().Consume(ctx, hashToken(req.Token), time.Now())
+		if err != nil {
+			return err
+		}
+		if link.UserID != userID {
+			return domain.ErrInvalidMagicLink
+		}
+
+		user, err = repos.Users().FindById(ctx, link.UserID)
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return domain.ErrInvalidMagicLink.Wrap(err)
+		}
+		if err != nil {
+			return err
+		}
+
+		// Link yang dibuka membuktikan user menerima email di alamat itu
+		if user.EmailVerified() || domain.NormalizeEmail(link.Email) != domain.NormalizeEmail(user.Email) {
+			return nil
+		}
+		verifiedAt := time.Now()
+		user.EmailVerifiedAt = &verifiedAt
+		return repos.Users().Update(ctx, user)
+	})
+	if errors.Is(err, domain.ErrInvalidMagicLink) {
+		return nil, u.loginFailed(ctx, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// RunCleanup menghapus link yang sudah tidak dipakai untuk login maupun
+// throttle setiap interval sampai ctx dibatalkan
+func (u *magicLinkUsecase) RunCleanup(ctx context.Context, interval time.Duration, log *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	retention := max(u.ttl, u.window)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := u.magicLinkRepo.DeleteBefore(ctx, time.Now().Add(-retention))
+			if err != nil {
+				log.Error("failed to delete old magic links", "error", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Info("deleted old magic links", "count", deleted)
+			}
+		}
+	}
+}
+
+// issueToken membuat token magic link yang ditandatangani, lalu menyimpan
+// hash dan masa berlakunya di link
+func (u *magicLinkUsecase) issueToken(link *domain.MagicLink, now time.Time) (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	link.ExpiresAt = now.Add(u.ttl)
+	claims := jwt.MapClaims{
+		"typ": magicLinkTokenType,
+		"sub": link.UserID,
+		"jti": base64.RawURLEncoding.EncodeToString(nonce),
+		"exp": link.ExpiresAt.Unix(),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(u.jwtSecret)
+	if err != nil {
+		return "", err
+	}
+
+	link.TokenHash = hashToken(token)
+	return token, nil
+}
+
+// parseToken memeriksa tanda tangan, tipe dan masa berlaku token lalu
+// mengembalikan ID user di dalamnya
+func (u *magicLinkUsecase) parseToken(token string) (uuid.UUID, error) {
+	parsed, err := jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("invalid signing method")
+		}
+		return u.jwtSecret, nil
+	})
+	if err != nil {
+		return uuid.Nil, domain.ErrInvalidMagicLink.Wrap(err)
+	}
+
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != magicLinkTokenType {
+		return uuid.Nil, domain.ErrInvalidMagicLink
+	}
+	sub, _ := claims["sub"].(string)
+	userID, err := uuid.Parse(sub)
+	if err != nil {
+		return uuid.Nil, domain.ErrInvalidMagicLink.Wrap(err)
+	}
+	return userID, nil
+}
+
+// loginFailed mencatat login magic link yang gagal ke audit log lalu
+// mengembalikan cause (ErrInvalidMagicLink)
+func (u *magicLinkUsecase) loginFailed(ctx context.Context, cause error) error {
+	metrics.AuthLogins.WithLabelValues(metrics.ResultFailure).Inc()
+	err := u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		entry := newAuditEntry(ctx, domain.AuditLoginFailed, nil)
+		entry.Metadata = domain.AuditMetadata{"method": domain.LoginMethodMagicLink}
+		return repos.Audit().Append(ctx, entry)
+	})
+	if err != nil {
+		return err
+	}
+	return cause
+}
+
+func (u *magicLinkUsecase) sendMagicLink(ctx context.Context, user *domain.User, link *domain.MagicLink, token string) {
+	log := logger.FromContext(ctx).With("magic_link_id", link.ID.String())
+
+	target := u.linkURL + "?token=" + url.QueryEscape(token)
+	if strings.Contains(u.linkURL, "?") {
+		target = u.linkURL + "&token=" + url.QueryEscape(token)
+	}
+
+	email, err := i18n.RenderEmail(user.Locale, "magic-link", map[string]any{
+		"Name":      user.Name,
+		"URL":       target,
+		"ExpiresAt": link.ExpiresAt.UTC(),
+	})
+	if err != nil {
+		log.Error("render magic link email", "error", err)
+		return
+	}
+
+	err = u.mailer.Send(ctx, &mailer.Message{To: user.Email, Subject: email.Subject, Text: email.Text})
+	if err != nil {
+		log.Error("send magic link email", "error", err)
+	}
+}
@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/repository"
+)
+
+// blockingUsers menahan FindByEmail sampai release ditutup
+type blockingUsers struct {
+	repository.UserRepository
+	called  chan string
+	release chan struct{}
+}
+
+func (r *blockingUsers) FindByEmail(_ context.Context, email string) (*domain.User, error) {
+	r.called <- email
+	<-r.release
+	return nil, domain.ErrUserNotFound
+}
+
+func TestMagicLinkSendDoesNotWaitForLookup(t *testing.T) {
+	users := &blockingUsers{called: make(chan string, 1), release: make(chan struct{})}
+	defer close(users.release)
+	u := &magicLinkUsecase{userRepo: users}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- u.Send(ctx, &domain.MagicLinkRequest{Email: " Nobody@Example.com "}) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Send waited for the user lookup")
+	}
+
+	// Request selesai; pencarian di background tetap berjalan
+	cancel()
+	select {
+	case email := <-users.called:
+		if email != "nobody@example.com" {
+			t.Errorf("looked up %q", email)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("user lookup never ran")
+	}
+}
@@ -0,0 +1,976 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/repository"
+	"github.com/Hilmarch27/gin-api/pkg/oidc"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+const (
+	// oauthSecretBytes adalah panjang client secret, authorization code dan
+	// refresh token acak (256 bit)
+	oauthSecretBytes = 32
+
+	// authorizationCodeTTL adalah masa berlaku authorization code
+	authorizationCodeTTL = 5 * time.Minute
+
+	// consentRequestTTL adalah masa berlaku parameter request consent screen
+	consentRequestTTL = 10 * time.Minute
+
+	// Header typ token yang ditandatangani provider. Access token memakai
+	// at+jwt (RFC 9068) agar tidak bisa dipakai sebagai ID token.
+	accessTokenType    = "at+jwt"
+	idTokenType        = "JWT"
+	consentRequestType = "oauth-consent+jwt"
+)
+
+var (
+	// codeChallengePattern adalah hasil S256 (SHA-256, base64url tanpa padding)
+	codeChallengePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{43}$`)
+
+	// codeVerifierPattern mengikuti RFC 7636 bagian 4.1
+	codeVerifierPattern = regexp.MustCompile(`^[A-Za-z0-9._~-]{43,128}$`)
+)
+
+type OAuthUsecase interface {
+	Discovery() *domain.OpenIDConfiguration
+	JWKS() oidc.JWKS
+
+	CreateClient(ctx context.Context, creatorID uuid.UUID, req *domain.CreateOAuthClientRequest) (*domain.CreatedOAuthClientResponse, error)
+	ListClients(ctx context.Context) ([]*domain.OAuthClientResponse, error)
+	GetClient(ctx context.Context, id uuid.UUID) (*domain.OAuthClientResponse, error)
+	DeleteClient(ctx context.Context, id uuid.UUID) error
+
+	Authorize(ctx context.Context, req *domain.AuthorizeRequest, userID *uuid.UUID) (string, error)
+	ConsentDetails(ctx context.Context, userID uuid.UUID, request string) (*domain.ConsentDetails, error)
+	Consent(ctx context.Context, userID uuid.UUID, req *domain.ConsentRequest) (string, error)
+	Token(ctx context.Context, req *domain.TokenRequest, creds domain.ClientCredentials) (*domain.TokenResponse, error)
+	Revoke(ctx context.Context, req *domain.RevokeTokenRequest, creds domain.ClientCredentials) error
+	UserInfo(ctx context.Context, accessToken string) (*domain.UserInfo, error)
+
+	ListGrants(ctx context.Context, userID uuid.UUID) ([]*domain.OAuthGrantResponse, error)
+	RevokeGrant(ctx context.Context, userID, clientID uuid.UUID) error
+}
+
+type oauthUsecase struct {
+	clientRepo repository.OAuthClientRepository
+	grantRepo  repository.OAuthGrantRepository
+	userRepo   repository.UserRepository
+	uow        repository.UnitOfWork
+	key        *oidc.SigningKey
+	cfg        oidc.Config
+}
+
+// NewOAuthUsecase membuat usecase OAuth 2.1 / OpenID Connect provider.
+// Login user tetap memakai alur login biasa (AuthUsecase.Login);
+// authorize hanya membaca sesi login yang sudah ada.
+func NewOAuthUsecase(cr repository.OAuthClientRepository, gr repository.OAuthGrantRepository, ur repository.UserRepository, uow repository.UnitOfWork, key *oidc.SigningKey, cfg oidc.Config) OAuthUsecase {
+	return &oauthUsecase{
+		clientRepo: cr,
+		grantRepo:  gr,
+		userRepo:   ur,
+		uow:        uow,
+		key:        key,
+		cfg:        cfg,
+	}
+}
+
+// consentRequestClaims adalah parameter authorization request yang dibawa
+// ke consent screen, ditandatangani agar tidak bisa diubah frontend
+type consentRequestClaims struct {
+	ClientID      string `json:"client_id"`
+	RedirectURI   string `json:"redirect_uri"`
+	Scope         string `json:"scope"`
+	State         string `json:"state,omitempty"`
+	CodeChallenge string `json:"code_challenge"`
+	Nonce         string `json:"nonce,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// accessTokenClaims adalah claim access token (RFC 9068)
+type accessTokenClaims struct {
+	ClientID string `json:"client_id"`
+	Scope    string `json:"scope,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// tokenGrant adalah hasil grant yang siap diterbitkan menjadi token
+type tokenGrant struct {
+	client *domain.OAuthClient
+	user   *domain.User // nil untuk client_credentials
+	scopes []string
+	nonce  string
+
+	// refreshScopes adalah scope refresh token baru; nil berarti tidak
+	// menerbitkan refresh token
+	refreshScopes []string
+	familyID      uuid.UUID
+}
+
+func (u *oauthUsecase) Discovery() *domain.OpenIDConfiguration {
+	scopes := slices.Clone(domain.OIDCUserScopes)
+	return &domain.OpenIDConfiguration{
+		Issuer:                            u.cfg.Issuer,
+		AuthorizationEndpoint:             u.cfg.EndpointURL + "/authorize",
+		TokenEndpoint:                     u.cfg.EndpointURL + "/token",
+		UserinfoEndpoint:                  u.cfg.EndpointURL + "/userinfo",
+		RevocationEndpoint:                u.cfg.EndpointURL + "/revoke",
+		JWKSURI:                           u.cfg.Issuer + "/.well-known/jwks.json",
+		ScopesSupported:                   scopes,
+		ResponseTypesSupported:            []string{"code"},
+		ResponseModesSupported:            []string{"query"},
+		GrantTypesSupported:               []string{domain.GrantAuthorizationCode, domain.GrantRefreshToken, domain.GrantClientCredentials},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{"RS256"},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+		PromptValuesSupported:             []string{"none", "login", "consent"},
+		ClaimsSupported:                   []string{"sub", "iss", "aud", "exp", "iat", "nonce", "name", "nickname", "picture", "zoneinfo", "locale", "updated_at", "email"},
+
+		AuthorizationResponseIssParameterSupported: true,
+	}
+}
+
+func (u *oauthUsecase) JWKS() oidc.JWKS {
+	return u.key.JWKS()
+}
+
+// CreateClient mendaftarkan client. Client secret hanya ada di response ini.
+func (u *oauthUsecase) CreateClient(ctx context.Context, creatorID uuid.UUID, req *domain.CreateOAuthClientRequest) (_ *domain.CreatedOAuthClientResponse, err error) {
+	ctx, span := tracer.Start(ctx, "oauthUsecase.CreateClient")
+	defer func() { endSpan(span, err) }()
+
+	grants := sortedSet(req.GrantTypes)
+	var violations domain.FieldViolations
+	if slices.Contains(grants, domain.GrantAuthorizationCode) && len(req.RedirectURIs) == 0 {
+		violations = append(violations, domain.FieldViolation{Field: "redirect_uris", Rule: "required"})
+	}
+	if slices.ContainsFunc(req.RedirectURIs, func(s string) bool { return strings.Contains(s, "#") }) {
+		violations = append(violations, domain.FieldViolation{Field: "redirect_uris", Rule: "invalid"})
+	}
+	if slices.Contains(grants, domain.GrantRefreshToken) && !slices.Contains(grants, domain.GrantAuthorizationCode) {
+		violations = append(violations, domain.FieldViolation{Field: "grant_types", Rule: "invalid"})
+	}
+	if req.Public && slices.Contains(grants, domain.GrantClientCredentials) {
+		violations = append(violations, domain.FieldViolation{Field: "grant_types", Rule: "invalid"})
+	}
+	if len(violations) > 0 {
+		return nil, domain.ErrInvalidInput.Wrap(violations)
+	}
+
+	redirectURIs := make([]string, 0, len(req.RedirectURIs))
+	for _, uri := range req.RedirectURIs {
+		if !slices.Contains(redirectURIs, uri) {
+			redirectURIs = append(redirectURIs, uri)
+		}
+	}
+
+	client := &domain.OAuthClient{
+		Name:         req.Name,
+		Public:       req.Public,
+		RedirectURIs: redirectURIs,
+		GrantTypes:   grants,
+		Scopes:       sortedSet(req.Scopes),
+		SkipConsent:  req.SkipConsent,
+		CreatedBy:    &creatorID,
+	}
+	var secret string
+	if !client.Public {
+		if secret, err = randomToken(); err != nil {
+			return nil, err
+		}
+		client.SecretHash = hashToken(secret)
+	}
+
+	err = u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		if err := repos.OAuthClients().Create(ctx, client); err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, domain.AuditOAuthClientCreated, &client.ID)
+		entry.Metadata = domain.AuditMetadata{
+			"name":        client.Name,
+			"grant_types": strings.Join(client.GrantTypes, " "),
+			"scopes":      strings.Join(client.Scopes, " "),
+		}
+		return repos.Audit().Append(ctx, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &domain.CreatedOAuthClientResponse{
+		OAuthClientResponse: *domain.NewOAuthClientResponse(client),
+		ClientSecret:        secret,
+	}, nil
+}
+
+func (u *oauthUsecase) ListClients(ctx context.Context) (_ []*domain.OAuthClientResponse, err error) {
+	ctx, span := tracer.Start(ctx, "oauthUsecase.ListClients")
+	defer func() { endSpan(span, err) }()
+
+	clients, err := u.clientRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*domain.OAuthClientResponse, len(clients))
+	for i := range clients {
+		responses[i] = domain.NewOAuthClientResponse(&clients[i])
+	}
+	return responses, nil
+}
+
+func (u *oauthUsecase) GetClient(ctx context.Context, id uuid.UUID) (_ *domain.OAuthClientResponse, err error) {
+	ctx, span := tracer.Start(ctx, "oauthUsecase.GetClient")
+	defer func() { endSpan(span, err) }()
+
+	client, err := u.clientRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewOAuthClientResponse(client), nil
+}
+
+// DeleteClient menghapus client dan mencabut semua refresh token-nya.
+// Access token yang sudah terbit tetap berlaku sampai kedaluwarsa.
+func (u *oauthUsecase) DeleteClient(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := tracer.Start(ctx, "oauthUsecase.DeleteClient")
+	defer func() { endSpan(span, err) }()
+
+	return u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		client, err := repos.OAuthClients().FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := repos.OAuthClients().Delete(ctx, id); err != nil {
+			return err
+		}
+		if err := repos.OAuthGrants().RevokeClientRefreshTokens(ctx, id, time.Now()); err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, domain.AuditOAuthClientDeleted, &id)
+		entry.Metadata = domain.AuditMetadata{"name": client.Name}
+		return repos.Audit().Append(ctx, entry)
+	})
+}
+
+// Authorize memproses authorization request dan mengembalikan URL tujuan
+// redirect: halaman login jika userID nil (belum ada sesi login), consent
+// screen jika scope belum disetujui, atau redirect_uri client berisi code.
+// Client dan redirect_uri yang tidak valid dikembalikan sebagai error
+// (tidak di-redirect); error lain dikirim ke client lewat redirect_uri.
+func (u *oauthUsecase) Authorize(ctx context.Context, req *domain.AuthorizeRequest, userID *uuid.UUID) (_ string, err error) {
+	ctx, span := tracer.Start(ctx, "oauthUsecase.Authorize")
+	defer func() { endSpan(span, err) }()
+
+	client, err := u.findClient(ctx, req.ClientID, domain.ErrUnknownOAuthClient)
+	if err != nil {
+		return "", err
+	}
+	redirectURI := req.RedirectURI
+	if redirectURI == "" && len(client.RedirectURIs) == 1 {
+		redirectURI = client.RedirectURIs[0]
+	}
+	if !client.AllowsRedirectURI(redirectURI) {
+		return "", domain.ErrInvalidRedirectURI
+	}
+
+	// Mulai dari sini error dikirim ke client lewat redirect_uri
+	fail := func(err error) (string, error) {
+		return u.errorRedirect(redirectURI, req.State, err), nil
+	}
+	if req.ResponseType != "code" {
+		return fail(domain.ErrUnsupportedResponseType)
+	}
+	if !client.AllowsGrant(domain.GrantAuthorizationCode) {
+		return fail(domain.ErrUnauthorizedClient)
+	}
+	if req.CodeChallengeMethod != "S256" || !codeChallengePattern.MatchString(req.CodeChallenge) {
+		return fail(domain.ErrPKCERequired)
+	}
+	scopes := parseScopes(req.Scope)
+	if len(scopes) == 0 || !client.AllowsScopes(scopes) {
+		return fail(domain.ErrInvalidScope)
+	}
+
+	// Belum login (atau client meminta login ulang): arahkan ke halaman
+	// login frontend, yang memanggil POST /auth/login lalu kembali ke sini
+	if userID == nil || req.Prompt == "login" {
+		if req.Prompt == "none" {
+			return fail(domain.ErrLoginRequired)
+		}
+		retry := *req
+		retry.Prompt = ""
+		retry.RedirectURI = redirectURI
+		return appendQuery(u.cfg.LoginURL, url.Values{"return_to": {u.authorizeURL(&retry)}}), nil
+	}
+
+	claims := &consentRequestClaims{
+		ClientID:      client.ID.String(),
+		RedirectURI:   redirectURI,
+		Scope:         strings.Join(scopes, " "),
+		State:         req.State,
+		CodeChallenge: req.CodeChallenge,
+		Nonce:         req.Nonce,
+	}
+
+	if !client.SkipConsent || req.Prompt == "consent" {
+		consent, err := u.grantRepo.FindConsent(ctx, *userID, client.ID)
+		if err != nil {
+			return "", err
+		}
+		if req.Prompt == "consent" || consent == nil || !consent.Covers(scopes) {
+			if req.Prompt == "none" {
+				return fail(domain.ErrConsentRequired)
+			}
+
+			now := time.Now()
+			claims.RegisteredClaims = jwt.RegisteredClaims{
+				Issuer:    u.cfg.Issuer,
+				Subject:   userID.String(),
+				IssuedAt:  jwt.NewNumericDate(now),
+				ExpiresAt: jwt.NewNumericDate(now.Add(consentRequestTTL)),
+			}
+			request, err := u.key.Sign(consentRequestType, claims)
+			if err != nil {
+				return "", err
+			}
+			return appendQuery(u.cfg.ConsentURL, url.Values{"request": {request}}), nil
+		}
+	}
+
+	var redirect string
+	err = u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		var err error
+		redirect, err = u.issueCode(ctx, repos, *userID, client.ID, claims)
+		return err
+	})
+	return redirect, err
+}
+
+// ConsentDetails mengembalikan data untuk consent screen dari parameter
+// request yang dibuat Authorize
+func (u *oauthUsecase) ConsentDetails(ctx context.Context, userID uuid.UUID, request string) (_ *domain.ConsentDetails, err error) {
+	ctx, span := tracer.Start(ctx, "oauthUsecase.ConsentDetails")
+	defer func() { endSpan(span, err) }()
+
+	claims, client, err := u.consentRequest(ctx, userID, request)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &domain.ConsentDetails{
+		ClientID:      client.ID,
+		ClientName:    client.Name,
+		RedirectURI:   claims.RedirectURI,
+		Scopes:        strings.Fields(claims.Scope),
+		GrantedScopes: []string{},
+	}
+	consent, err := u.grantRepo.FindConsent(ctx, userID, client.ID)
+	if err != nil {
+		return nil, err
+	}
+	if consent != nil {
+		details.GrantedScopes = consent.Scopes
+	}
+	return details, nil
+}
+
+// Consent menyimpan keputusan user di consent screen lalu mengembalikan URL
+// redirect_uri client berisi code, atau error access_denied jika ditolak
+func (u *oauthUsecase) Consent(ctx context.Context, userID uuid.UUID, req *domain.ConsentRequest) (_ string, err error) {
+	ctx, span := tracer.Start(ctx, "oauthUsecase.Consent")
+	defer func() { endSpan(span, err) }()
+
+	claims, client, err := u.consentRequest(ctx, userID, req.Request)
+	if err != nil {
+		return "", err
+	}
+	if !req.Approve {
+		return u.errorRedirect(claims.RedirectURI, claims.State, domain.ErrAccessDenied), nil
+	}
+
+	var redirect string
+	err = u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		// Scope baru ditambahkan ke consent yang sudah ada
+		scopes := strings.Fields(claims.Scope)
+		consent, err := repos.OAuthGrants().FindConsent(ctx, userID, client.ID)
+		if err != nil {
+			return err
+		}
+		if consent != nil {
+			scopes = sortedSet(append(scopes, consent.Scopes...))
+		}
+		err = repos.OAuthGrants().SaveConsent(ctx, &domain.OAuthConsent{
+			UserID:   userID,
+			ClientID: client.ID,
+			Scopes:   scopes,
+		})
+		if err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, domain.AuditOAuthConsentGranted, &userID)
+		entry.Metadata = domain.AuditMetadata{
+			"client_id": client.ID.String(),
+			"scopes":    claims.Scope,
+		}
+		if err := repos.Audit().Append(ctx, entry); err != nil {
+			return err
+		}
+
+		redirect, err = u.issueCode(ctx, repos, userID, client.ID, claims)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return redirect, nil
+}
+
+// consentRequest memverifikasi parameter request consent screen milik user
+func (u *oauthUsecase) consentRequest(ctx context.Context, userID uuid.UUID, request string) (*consentRequestClaims, *domain.OAuthClient, error) {
+	var claims consentRequestClaims
+	if err := u.key.Parse(request, consentRequestType, &claims); err != nil {
+		return nil, nil, domain.ErrInvalidAuthorizationRequest.Wrap(err)
+	}
+	if claims.Subject != userID.String() {
+		return nil, nil, domain.ErrInvalidAuthorizationRequest
+	}
+	client, err := u.findClient(ctx, claims.ClientID, domain.ErrInvalidAuthorizationRequest)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &claims, client, nil
+}
+
+// issueCode menyimpan authorization code baru dan mengembalikan URL
+// redirect_uri client berisi code, state dan iss (RFC 9207)
+func (u *oauthUsecase) issueCode(ctx context.Context, repos repository.Repositories, userID, clientID uuid.UUID, claims *consentRequestClaims) (string, error) {
+	raw, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	code := &domain.OAuthAuthorizationCode{
+		CodeHash:      hashToken(raw),
+		ClientID:      clientID,
+		UserID:        userID,
+		RedirectURI:   claims.RedirectURI,
+		Scopes:        strings.Fields(claims.Scope),
+		CodeChallenge: claims.CodeChallenge,
+		Nonce:         claims.Nonce,
+		ExpiresAt:     time.Now().Add(authorizationCodeTTL),
+	}
+	if err := repos.OAuthGrants().CreateCode(ctx, code); err != nil {
+		return "", err
+	}
+
+	params := url.Values{"code": {raw}, "iss": {u.cfg.Issuer}}
+	if claims.State != "" {
+		params.Set("state", claims.State)
+	}
+	return appendQuery(claims.RedirectURI, params), nil
+}
+
+// Token menukar grant dengan access token (endpoint token OAuth)
+func (u *oauthUsecase) Token(ctx context.Context, req *domain.TokenRequest, creds domain.ClientCredentials) (_ *domain.TokenResponse, err error) {
+	ctx, span := tracer.Start(ctx, "oauthUsecase.Token")
+	defer func() { endSpan(span, err) }()
+
+	switch req.GrantType {
+	case domain.GrantAuthorizationCode, domain.GrantRefreshToken, domain.GrantClientCredentials:
+	default:
+		return nil, domain.ErrUnsupportedGrantType
+	}
+
+	client, err := u.authenticateClient(ctx, creds)
+	if err != nil {
+		return nil, err
+	}
+	if !client.AllowsGrant(req.GrantType) {
+		return nil, domain.ErrUnauthorizedClient
+	}
+
+	switch req.GrantType {
+	case domain.GrantAuthorizationCode:
+		return u.exchangeCode(ctx, client, req)
+	case domain.GrantRefreshToken:
+		return u.refresh(ctx, client, req)
+	default:
+		return u.clientCredentials(ctx, client, req)
+	}
+}
+
+// exchangeCode menukar authorization code. Code yang dipakai dua kali
+// mencabut semua refresh token yang pernah diterbitkan darinya.
+func (u *oauthUsecase) exchangeCode(ctx context.Context, client *domain.OAuthClient, req *domain.TokenRequest) (*domain.TokenResponse, error) {
+	if req.Code == "" || req.CodeVerifier == "" {
+		return nil, domain.ErrInvalidInput
+	}
+
+	code, err := u.grantRepo.FindCodeByHash(ctx, hashToken(req.Code))
+	if err != nil {
+		return nil, err
+	}
+	if code.ClientID != client.ID {
+		return nil, domain.ErrInvalidGrant
+	}
+	if code.UsedAt != nil {
+		return nil, u.grantReused(ctx, domain.AuditOAuthCodeReused, code.UserID, client.ID, code.ID)
+	}
+	if !time.Now().Before(code.ExpiresAt) {
+		return nil, domain.ErrInvalidGrant
+	}
+	if req.RedirectURI != "" && req.RedirectURI != code.RedirectURI {
+		return nil, domain.ErrInvalidGrant
+	}
+	if !verifyPKCE(req.CodeVerifier, code.CodeChallenge) {
+		return nil, domain.ErrInvalidGrant
+	}
+
+	user, err := u.findUser(ctx, code.UserID, domain.ErrInvalidGrant)
+	if err != nil {
+		return nil, err
+	}
+
+	grant := &tokenGrant{client: client, user: user, scopes: code.Scopes, nonce: code.Nonce, familyID: code.ID}
+	if client.AllowsGrant(domain.GrantRefreshToken) && slices.Contains(code.Scopes, domain.ScopeOfflineAccess) {
+		grant.refreshScopes = code.Scopes
+	}
+
+	var resp *domain.TokenResponse
+	err = u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		if err := repos.OAuthGrants().UseCode(ctx, code.ID, time.Now()); err != nil {
+			return err
+		}
+		var err error
+		resp, err = u.issueTokens(ctx, repos.OAuthGrants(), grant)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// refresh merotasi refresh token. Token lama yang dipakai lagi dianggap
+// bocor sehingga seluruh family-nya dicabut. Scope yang lebih sempit hanya
+// berlaku untuk access token baru.
+func (u *oauthUsecase) refresh(ctx context.Context, client *domain.OAuthClient, req *domain.TokenRequest) (*domain.TokenResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, domain.ErrInvalidInput
+	}
+
+	token, err := u.grantRepo.FindRefreshTokenByHash(ctx, hashToken(req.RefreshToken))
+	if err != nil {
+		return nil, err
+	}
+	if token.ClientID != client.ID {
+		return nil, domain.ErrInvalidGrant
+	}
+	if token.RevokedAt != nil {
+		return nil, u.grantReused(ctx, domain.AuditOAuthRefreshTokenReused, token.UserID, client.ID, token.FamilyID)
+	}
+	if !time.Now().Before(token.ExpiresAt) {
+		return nil, domain.ErrInvalidGrant
+	}
+
+	scopes := token.Scopes
+	if req.Scope != "" {
+		scopes = parseScopes(req.Scope)
+		if !token.Covers(scopes) {
+			return nil, domain.ErrInvalidScope
+		}
+	}
+
+	user, err := u.findUser(ctx, token.UserID, domain.ErrInvalidGrant)
+	if err != nil {
+		return nil, err
+	}
+
+	grant := &tokenGrant{client: client, user: user, scopes: scopes, refreshScopes: token.Scopes, familyID: token.FamilyID}
+
+	var resp *domain.TokenResponse
+	err = u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		if err := repos.OAuthGrants().RevokeRefreshToken(ctx, token.ID, time.Now()); err != nil {
+			return err
+		}
+		var err error
+		resp, err = u.issueTokens(ctx, repos.OAuthGrants(), grant)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// clientCredentials menerbitkan access token atas nama client sendiri.
+// Scope OpenID Connect tidak bisa diminta karena tidak ada user.
+func (u *oauthUsecase) clientCredentials(ctx context.Context, client *domain.OAuthClient, req *domain.TokenRequest) (*domain.TokenResponse, error) {
+	if client.Public {
+		return nil, domain.ErrUnauthorizedClient
+	}
+
+	scopes := parseScopes(req.Scope)
+	if req.Scope == "" {
+		scopes = slices.DeleteFunc(slices.Clone(client.Scopes), func(s string) bool {
+			return slices.Contains(domain.OIDCUserScopes, s)
+		})
+	}
+	for _, s := range scopes {
+		if slices.Contains(domain.OIDCUserScopes, s) {
+			return nil, domain.ErrInvalidScope
+		}
+	}
+	if !client.AllowsScopes(scopes) {
+		return nil, domain.ErrInvalidScope
+	}
+
+	return u.issueTokens(ctx, nil, &tokenGrant{client: client, scopes: scopes})
+}
+
+// grantReused mencabut family refresh token dari grant yang dipakai ulang
+// dan mencatatnya ke audit log, lalu mengembalikan ErrInvalidGrant
+func (u *oauthUsecase) grantReused(ctx context.Context, action string, userID, clientID, familyID uuid.UUID) error {
+	err := u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		if err := repos.OAuthGrants().RevokeFamily(ctx, familyID, time.Now()); err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, action, &userID)
+		entry.Metadata = domain.AuditMetadata{
+			"client_id": clientID.String(),
+			"family_id": familyID.String(),
+		}
+		return repos.Audit().Append(ctx, entry)
+	})
+	if err != nil {
+		return err
+	}
+	return domain.ErrInvalidGrant
+}
+
+// issueTokens menandatangani access token, ID token (scope openid) dan
+// menyimpan refresh token baru jika grant mengizinkannya. grants boleh nil
+// jika grant tidak menerbitkan refresh token.
+func (u *oauthUsecase) issueTokens(ctx context.Context, grants repository.OAuthGrantRepository, grant *tokenGrant) (*domain.TokenResponse, error) {
+	now := time.Now()
+	expiresAt := now.Add(u.cfg.AccessTokenTTL)
+
+	subject := grant.client.ID.String()
+	if grant.user != nil {
+		subject = grant.user.ID.String()
+	}
+	accessToken, err := u.key.Sign(accessTokenType, &accessTokenClaims{
+		ClientID: grant.client.ID.String(),
+		Scope:    strings.Join(grant.scopes, " "),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    u.cfg.Issuer,
+			Subject:   subject,
+			Audience:  jwt.ClaimStrings{u.cfg.Issuer},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			ID:        uuid.NewString(),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &domain.TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(u.cfg.AccessTokenTTL.Seconds()),
+		Scope:       strings.Join(grant.scopes, " "),
+	}
+
+	if grant.user != nil && slices.Contains(grant.scopes, domain.ScopeOpenID) {
+		claims, err := idTokenClaims(grant.user, grant.scopes)
+		if err != nil {
+			return nil, err
+		}
+		claims["iss"] = u.cfg.Issuer
+		claims["aud"] = grant.client.ID.String()
+		claims["iat"] = now.Unix()
+		claims["exp"] = expiresAt.Unix()
+		claims["at_hash"] = oidc.HashHalf(accessToken)
+		if grant.nonce != "" {
+			claims["nonce"] = grant.nonce
+		}
+		if resp.IDToken, err = u.key.Sign(idTokenType, claims); err != nil {
+			return nil, err
+		}
+	}
+
+	if grant.user != nil && grant.refreshScopes != nil {
+		raw, err := randomToken()
+		if err != nil {
+			return nil, err
+		}
+		err = grants.CreateRefreshToken(ctx, &domain.OAuthRefreshToken{
+			FamilyID:  grant.familyID,
+			ClientID:  grant.client.ID,
+			UserID:    grant.user.ID,
+			TokenHash: hashToken(raw),
+			Scopes:    grant.refreshScopes,
+			ExpiresAt: now.Add(u.cfg.RefreshTokenTTL),
+		})
+		if err != nil {
+			return nil, err
+		}
+		resp.RefreshToken = raw
+	}
+
+	return resp, nil
+}
+
+// idTokenClaims mengubah UserInfo menjadi claim ID token
+func idTokenClaims(user *domain.User, scopes []string) (jwt.MapClaims, error) {
+	data, err := json.Marshal(domain.NewUserInfo(user, scopes))
+	if err != nil {
+		return nil, err
+	}
+	claims := jwt.MapClaims{}
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// Revoke mencabut refresh token beserta family-nya (RFC 7009). Token yang
+// tidak dikenal, milik client lain, atau berupa access token (JWT yang
+// berlaku sampai kedaluwarsa) diabaikan tanpa error.
+func (u *oauthUsecase) Revoke(ctx context.Context, req *domain.RevokeTokenRequest, creds domain.ClientCredentials) (err error) {
+	ctx, span := tracer.Start(ctx, "oauthUsecase.Revoke")
+	defer func() { endSpan(span, err) }()
+
+	client, err := u.authenticateClient(ctx, creds)
+	if err != nil {
+		return err
+	}
+
+	token, err := u.grantRepo.FindRefreshTokenByHash(ctx, hashToken(req.Token))
+	if errors.Is(err, domain.ErrInvalidGrant) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if token.ClientID != client.ID {
+		return nil
+	}
+	return u.grantRepo.RevokeFamily(ctx, token.FamilyID, time.Now())
+}
+
+// UserInfo mengembalikan claim user pemilik access token. Access token
+// harus memiliki scope openid.
+func (u *oauthUsecase) UserInfo(ctx context.Context, accessToken string) (_ *domain.UserInfo, err error) {
+	ctx, span := tracer.Start(ctx, "oauthUsecase.UserInfo")
+	defer func() { endSpan(span, err) }()
+
+	var claims accessTokenClaims
+	if err := u.key.Parse(accessToken, accessTokenType, &claims); err != nil {
+		return nil, domain.ErrInvalidToken.Wrap(err)
+	}
+	if !claims.VerifyIssuer(u.cfg.Issuer, true) {
+		return nil, domain.ErrInvalidToken
+	}
+
+	scopes := strings.Fields(claims.Scope)
+	if !slices.Contains(scopes, domain.ScopeOpenID) {
+		return nil, domain.ErrInsufficientScope
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, domain.ErrInvalidToken.Wrap(err)
+	}
+	user, err := u.findUser(ctx, userID, domain.ErrInvalidToken)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewUserInfo(user, scopes), nil
+}
+
+// ListGrants mengembalikan aplikasi yang sudah diberi akses oleh user
+func (u *oauthUsecase) ListGrants(ctx context.Context, userID uuid.UUID) (_ []*domain.OAuthGrantResponse, err error) {
+	ctx, span := tracer.Start(ctx, "oauthUsecase.ListGrants")
+	defer func() { endSpan(span, err) }()
+
+	consents, err := u.grantRepo.ListConsents(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*domain.OAuthGrantResponse, len(consents))
+	for i := range consents {
+		responses[i] = domain.NewOAuthGrantResponse(&consents[i])
+	}
+	return responses, nil
+}
+
+// RevokeGrant mencabut akses aplikasi: consent dihapus dan semua refresh
+// token user untuk client tersebut dicabut
+func (u *oauthUsecase) RevokeGrant(ctx context.Context, userID, clientID uuid.UUID) (err error) {
+	ctx, span := tracer.Start(ctx, "oauthUsecase.RevokeGrant")
+	defer func() { endSpan(span, err) }()
+
+	return u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		if err := repos.OAuthGrants().DeleteConsent(ctx, userID, clientID); err != nil {
+			return err
+		}
+		if err := repos.OAuthGrants().RevokeRefreshTokens(ctx, userID, clientID, time.Now()); err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, domain.AuditOAuthConsentRevoked, &userID)
+		entry.Metadata = domain.AuditMetadata{"client_id": clientID.String()}
+		return repos.Audit().Append(ctx, entry)
+	})
+}
+
+// authenticateClient memeriksa client_id dan client_secret. Client public
+// tidak punya secret; keamanannya bergantung pada PKCE.
+func (u *oauthUsecase) authenticateClient(ctx context.Context, creds domain.ClientCredentials) (*domain.OAuthClient, error) {
+	client, err := u.findClient(ctx, creds.ID, domain.ErrInvalidClient)
+	if err != nil {
+		return nil, err
+	}
+	if client.Public {
+		if creds.Secret != "" {
+			return nil, domain.ErrInvalidClient
+		}
+		return client, nil
+	}
+	if creds.Secret == "" || subtle.ConstantTimeCompare([]byte(hashToken(creds.Secret)), []byte(client.SecretHash)) != 1 {
+		return nil, domain.ErrInvalidClient
+	}
+	return client, nil
+}
+
+// findClient mencari client dari client_id; client_id yang tidak valid
+// atau tidak terdaftar menghasilkan notFound
+func (u *oauthUsecase) findClient(ctx context.Context, clientID string, notFound *domain.Error) (*domain.OAuthClient, error) {
+	id, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, notFound.Wrap(err)
+	}
+	client, err := u.clientRepo.FindByID(ctx, id)
+	if errors.Is(err, domain.ErrOAuthClientNotFound) {
+		return nil, notFound.Wrap(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// findUser mencari user secara global (tanpa tenant); user yang sudah
+// dihapus menghasilkan notFound
+func (u *oauthUsecase) findUser(ctx context.Context, id uuid.UUID, notFound *domain.Error) (*domain.User, error) {
+	user, err := u.userRepo.FindById(domain.WithoutTenant(ctx), id)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil, notFound.Wrap(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// authorizeURL membangun ulang URL authorize, dipakai sebagai return_to
+// halaman login
+func (u *oauthUsecase) authorizeURL(req *domain.AuthorizeRequest) string {
+	params := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			params.Set(key, value)
+		}
+	}
+	set("response_type", req.ResponseType)
+	set("client_id", req.ClientID)
+	set("redirect_uri", req.RedirectURI)
+	set("scope", req.Scope)
+	set("state", req.State)
+	set("code_challenge", req.CodeChallenge)
+	set("code_challenge_method", req.CodeChallengeMethod)
+	set("nonce", req.Nonce)
+	set("prompt", req.Prompt)
+	return u.cfg.EndpointURL + "/authorize?" + params.Encode()
+}
+
+// errorRedirect membangun URL redirect_uri berisi error OAuth
+func (u *oauthUsecase) errorRedirect(redirectURI, state string, err error) string {
+	code, _ := domain.OAuthErrorCode(err)
+	params := url.Values{"error": {code}, "iss": {u.cfg.Issuer}}
+	var de *domain.Error
+	if errors.As(err, &de) {
+		params.Set("error_description", de.Message)
+	}
+	if state != "" {
+		params.Set("state", state)
+	}
+	return appendQuery(redirectURI, params)
+}
+
+// appendQuery menambahkan params ke query URL yang mungkin sudah punya query
+func appendQuery(rawURL string, params url.Values) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	query := u.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// parseScopes memecah parameter scope menjadi daftar unik dan terurut
+func parseScopes(scope string) []string {
+	return sortedSet(strings.Fields(scope))
+}
+
+// sortedSet mengembalikan salinan values yang unik dan terurut
+func sortedSet(values []string) []string {
+	out := slices.Clone(values)
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+// verifyPKCE memeriksa code_verifier terhadap code_challenge S256
+func verifyPKCE(verifier, challenge string) bool {
+	if !codeVerifierPattern.MatchString(verifier) {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	computed := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+}
+
+// randomToken membuat secret acak 256 bit, di-encode base64url
+func randomToken() (string, error) {
+	b := make([]byte, oauthSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
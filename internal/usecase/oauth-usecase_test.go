@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/repository"
+	"github.com/Hilmarch27/gin-api/pkg/oidc"
+	"github.com/google/uuid"
+)
+
+// memOAuthClients menyimpan satu client OAuth
+type memOAuthClients struct {
+	repository.OAuthClientRepository
+	client *domain.OAuthClient
+}
+
+func (r *memOAuthClients) FindByID(_ context.Context, id uuid.UUID) (*domain.OAuthClient, error) {
+	if r.client.ID != id {
+		return nil, domain.ErrOAuthClientNotFound
+	}
+	return r.client, nil
+}
+
+// memOAuthGrants menyimpan refresh token di memori
+type memOAuthGrants struct {
+	repository.OAuthGrantRepository
+	tokens map[uuid.UUID]*domain.OAuthRefreshToken
+}
+
+func (r *memOAuthGrants) CreateRefreshToken(_ context.Context, token *domain.OAuthRefreshToken) error {
+	token.ID = uuid.New()
+	saved := *token
+	r.tokens[token.ID] = &saved
+	return nil
+}
+
+func (r *memOAuthGrants) FindRefreshTokenByHash(_ context.Context, hash string) (*domain.OAuthRefreshToken, error) {
+	for _, token := range r.tokens {
+		if token.TokenHash == hash {
+			found := *token
+			return &found, nil
+		}
+	}
+	return nil, domain.ErrInvalidGrant
+}
+
+func (r *memOAuthGrants) RevokeRefreshToken(_ context.Context, id uuid.UUID, at time.Time) error {
+	if token := r.tokens[id]; token.RevokedAt == nil {
+		token.RevokedAt = &at
+	}
+	return nil
+}
+
+func (r *memOAuthGrants) RevokeFamily(_ context.Context, familyID uuid.UUID, at time.Time) error {
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &at
+		}
+	}
+	return nil
+}
+
+type oauthTest struct {
+	u      *oauthUsecase
+	grants *memOAuthGrants
+	repos  *memRepos
+	client *domain.OAuthClient
+	user   *domain.User
+}
+
+func newOAuthTest(t *testing.T) *oauthTest {
+	t.Helper()
+	key, err := oidc.GenerateSigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &domain.OAuthClient{
+		ID:         uuid.New(),
+		Public:     true,
+		GrantTypes: []string{domain.GrantAuthorizationCode, domain.GrantRefreshToken},
+		Scopes:     []string{domain.ScopeOpenID, domain.ScopeOfflineAccess},
+	}
+	grants := &memOAuthGrants{tokens: map[uuid.UUID]*domain.OAuthRefreshToken{}}
+	repos := newMemRepos(testUser())
+	repos.grants = grants
+	return &oauthTest{
+		u: &oauthUsecase{
+			clientRepo: &memOAuthClients{client: client},
+			grantRepo:  grants,
+			userRepo:   repos.users,
+			uow:        &memUnitOfWork{repos},
+			key:        key,
+			cfg:        oidc.Config{Issuer: "https://id.example.com", AccessTokenTTL: time.Minute, RefreshTokenTTL: time.Hour},
+		},
+		grants: grants,
+		repos:  repos,
+		client: client,
+		user:   repos.users.user,
+	}
+}
+
+// issue menerbitkan refresh token pertama sebuah family seperti hasil
+// penukaran authorization code
+func (tt *oauthTest) issue(t *testing.T) string {
+	t.Helper()
+	resp, err := tt.u.issueTokens(context.Background(), tt.grants, &tokenGrant{
+		client:        tt.client,
+		user:          tt.user,
+		scopes:        []string{domain.ScopeOfflineAccess},
+		refreshScopes: []string{domain.ScopeOfflineAccess},
+		familyID:      uuid.New(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.RefreshToken
+}
+
+func (tt *oauthTest) refresh(refreshToken string) (*domain.TokenResponse, error) {
+	return tt.u.Token(context.Background(),
+		&domain.TokenRequest{GrantType: domain.GrantRefreshToken, RefreshToken: refreshToken},
+		domain.ClientCredentials{ID: tt.client.ID.String()})
+}
+
+func (tt *oauthTest) active(refreshToken string) bool {
+	token, err := tt.grants.FindRefreshTokenByHash(context.Background(), hashToken(refreshToken))
+	return err == nil && token.RevokedAt == nil
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	tests := []struct {
+		name   string
+		replay int // indeks token yang sudah dirotasi lalu dipakai lagi
+	}{
+		{"first token", 0},
+		{"middle token", 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tt := newOAuthTest(t)
+			other := tt.issue(t)
+
+			chain := []string{tt.issue(t)}
+			for i := 0; i < 2; i++ {
+				resp, err := tt.refresh(chain[i])
+				if err != nil {
+					t.Fatalf("rotation %d: %v", i, err)
+				}
+				chain = append(chain, resp.RefreshToken)
+			}
+			if !tt.active(chain[2]) {
+				t.Fatal("latest refresh token not active")
+			}
+
+			if _, err := tt.refresh(chain[tc.replay]); !errors.Is(err, domain.ErrInvalidGrant) {
+				t.Fatalf("replay: err = %v, want %v", err, domain.ErrInvalidGrant)
+			}
+			for i, token := range chain {
+				if tt.active(token) {
+					t.Errorf("token %d of the family still active", i)
+				}
+			}
+			if _, err := tt.refresh(chain[2]); !errors.Is(err, domain.ErrInvalidGrant) {
+				t.Errorf("latest token after replay: err = %v, want %v", err, domain.ErrInvalidGrant)
+			}
+			if !tt.active(other) {
+				t.Error("token from another family revoked")
+			}
+
+			entries := tt.repos.audit.entries
+			if n := len(entries); n == 0 || entries[n-1].Action != domain.AuditOAuthRefreshTokenReused {
+				t.Errorf("expected %s audit entry, got %v", domain.AuditOAuthRefreshTokenReused, entries)
+			}
+		})
+	}
+}
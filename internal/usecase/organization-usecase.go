@@ -0,0 +1,276 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+type OrganizationUsecase interface {
+	Create(ctx context.Context, ownerID uuid.UUID, req *domain.CreateOrganizationRequest) (*domain.OrganizationResponse, error)
+	ListForUser(ctx context.Context, userID uuid.UUID) ([]*domain.OrganizationResponse, error)
+	Get(ctx context.Context, tenant domain.Tenant) (*domain.OrganizationResponse, error)
+	Resolve(ctx context.Context, ref string) (*domain.Organization, error)
+	Membership(ctx context.Context, orgID, userID uuid.UUID) (*domain.Membership, error)
+	ListMembers(ctx context.Context, orgID uuid.UUID) ([]*domain.MemberResponse, error)
+	AddMember(ctx context.Context, actor domain.Tenant, req *domain.AddMemberRequest) (*domain.MemberResponse, error)
+	UpdateMember(ctx context.Context, actor domain.Tenant, userID uuid.UUID, req *domain.UpdateMemberRequest) (*domain.MemberResponse, error)
+	RemoveMember(ctx context.Context, actor domain.Tenant, actorID, userID uuid.UUID) error
+}
+
+type organizationUsecase struct {
+	orgRepo repository.OrganizationRepository
+	uow     repository.UnitOfWork
+}
+
+func NewOrganizationUsecase(or repository.OrganizationRepository, uow repository.UnitOfWork) OrganizationUsecase {
+	return &organizationUsecase{
+		orgRepo: or,
+		uow:     uow,
+	}
+}
+
+// Create membuat organization baru dengan pembuatnya sebagai owner
+func (u *organizationUsecase) Create(ctx context.Context, ownerID uuid.UUID, req *domain.CreateOrganizationRequest) (_ *domain.OrganizationResponse, err error) {
+	ctx, span := tracer.Start(ctx, "organizationUsecase.Create")
+	defer func() { endSpan(span, err) }()
+
+	org := &domain.Organization{Name: req.Name, Slug: req.Slug}
+	err = u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		if err := repos.Organizations().Create(ctx, org); err != nil {
+			return err
+		}
+		membership := &domain.Membership{OrganizationID: org.ID, UserID: ownerID, Role: domain.OrgRoleOwner}
+		if err := repos.Organizations().AddMember(ctx, membership); err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, domain.AuditOrganizationCreated, &ownerID)
+		entry.Metadata = organizationMetadata(org.ID, domain.OrgRoleOwner)
+		entry.Metadata["slug"] = org.Slug
+		return repos.Audit().Append(ctx, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewOrganizationResponse(org, domain.OrgRoleOwner), nil
+}
+
+func (u *organizationUsecase) ListForUser(ctx context.Context, userID uuid.UUID) (_ []*domain.OrganizationResponse, err error) {
+	ctx, span := tracer.Start(ctx, "organizationUsecase.ListForUser")
+	defer func() { endSpan(span, err) }()
+
+	memberships, err := u.orgRepo.ListForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*domain.OrganizationResponse, len(memberships))
+	for i, m := range memberships {
+		responses[i] = domain.NewOrganizationResponse(m.Organization, m.Role)
+	}
+	return responses, nil
+}
+
+func (u *organizationUsecase) Get(ctx context.Context, tenant domain.Tenant) (_ *domain.OrganizationResponse, err error) {
+	ctx, span := tracer.Start(ctx, "organizationUsecase.Get")
+	defer func() { endSpan(span, err) }()
+
+	org, err := u.orgRepo.FindByID(ctx, tenant.ID)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewOrganizationResponse(org, tenant.Role), nil
+}
+
+// Resolve mencari organization berdasarkan ID atau slug
+func (u *organizationUsecase) Resolve(ctx context.Context, ref string) (_ *domain.Organization, err error) {
+	ctx, span := tracer.Start(ctx, "organizationUsecase.Resolve")
+	defer func() { endSpan(span, err) }()
+
+	if id, err := uuid.Parse(ref); err == nil {
+		return u.orgRepo.FindByID(ctx, id)
+	}
+	if !domain.ValidSlug(ref) {
+		return nil, domain.ErrOrganizationNotFound
+	}
+	return u.orgRepo.FindBySlug(ctx, ref)
+}
+
+func (u *organizationUsecase) Membership(ctx context.Context, orgID, userID uuid.UUID) (_ *domain.Membership, err error) {
+	ctx, span := tracer.Start(ctx, "organizationUsecase.Membership")
+	defer func() { endSpan(span, err) }()
+
+	return u.orgRepo.FindMembership(ctx, orgID, userID)
+}
+
+func (u *organizationUsecase) ListMembers(ctx context.Context, orgID uuid.UUID) (_ []*domain.MemberResponse, err error) {
+	ctx, span := tracer.Start(ctx, "organizationUsecase.ListMembers")
+	defer func() { endSpan(span, err) }()
+
+	memberships, err := u.orgRepo.ListMembers(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*domain.MemberResponse, len(memberships))
+	for i := range memberships {
+		responses[i] = domain.NewMemberResponse(&memberships[i])
+	}
+	return responses, nil
+}
+
+// AddMember menambahkan user ke organization actor. Hanya owner yang boleh
+// menambahkan owner lain.
+func (u *organizationUsecase) AddMember(ctx context.Context, actor domain.Tenant, req *domain.AddMemberRequest) (_ *domain.MemberResponse, err error) {
+	ctx, span := tracer.Start(ctx, "organizationUsecase.AddMember")
+	defer func() { endSpan(span, err) }()
+
+	if req.Role == domain.OrgRoleOwner && actor.Role != domain.OrgRoleOwner {
+		return nil, domain.ErrOwnerRequired
+	}
+
+	var membership *domain.Membership
+	err = u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		m := &domain.Membership{OrganizationID: actor.ID, UserID: req.UserID, Role: req.Role}
+		if err := repos.Organizations().AddMember(ctx, m); err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, domain.AuditMemberAdded, &req.UserID)
+		entry.Metadata = organizationMetadata(actor.ID, req.Role)
+		if err := repos.Audit().Append(ctx, entry); err != nil {
+			return err
+		}
+
+		// Baca ulang bersama data user untuk response
+		membership, err = findMember(ctx, repos, actor.ID, req.UserID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewMemberResponse(membership), nil
+}
+
+// UpdateMember mengubah role anggota. Mengubah role owner atau menjadikan
+// anggota owner hanya boleh dilakukan owner, dan owner terakhir tidak bisa
+// diturunkan.
+func (u *organizationUsecase) UpdateMember(ctx context.Context, actor domain.Tenant, userID uuid.UUID, req *domain.UpdateMemberRequest) (_ *domain.MemberResponse, err error) {
+	ctx, span := tracer.Start(ctx, "organizationUsecase.UpdateMember")
+	defer func() { endSpan(span, err) }()
+
+	var membership *domain.Membership
+	err = u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		var err error
+		membership, err = findMember(ctx, repos, actor.ID, userID)
+		if err != nil {
+			return err
+		}
+		before := membership.Role
+		if before == req.Role {
+			return nil
+		}
+
+		touchesOwner := before == domain.OrgRoleOwner || req.Role == domain.OrgRoleOwner
+		if touchesOwner && actor.Role != domain.OrgRoleOwner {
+			return domain.ErrOwnerRequired
+		}
+		if before == domain.OrgRoleOwner {
+			if err := ensureAnotherOwner(ctx, repos, actor.ID, userID); err != nil {
+				return err
+			}
+		}
+
+		membership.Role = req.Role
+		if err := repos.Organizations().UpdateMember(ctx, membership); err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, domain.AuditMemberRoleChanged, &userID)
+		entry.Changes = domain.AuditChanges{"role": {Before: before, After: req.Role}}
+		entry.Metadata = organizationMetadata(actor.ID, req.Role)
+		return repos.Audit().Append(ctx, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewMemberResponse(membership), nil
+}
+
+// RemoveMember mengeluarkan anggota dari organization. Setiap anggota
+// boleh keluar sendiri; mengeluarkan orang lain butuh role owner/admin, dan
+// mengeluarkan owner hanya boleh dilakukan owner.
+func (u *organizationUsecase) RemoveMember(ctx context.Context, actor domain.Tenant, actorID, userID uuid.UUID) (err error) {
+	ctx, span := tracer.Start(ctx, "organizationUsecase.RemoveMember")
+	defer func() { endSpan(span, err) }()
+
+	return u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		membership, err := findMember(ctx, repos, actor.ID, userID)
+		if err != nil {
+			return err
+		}
+
+		self := actorID == userID
+		switch {
+		case !self && !actor.CanManageMembers():
+			return domain.ErrOrgAdminRequired
+		case !self && membership.Role == domain.OrgRoleOwner && actor.Role != domain.OrgRoleOwner:
+			return domain.ErrOwnerRequired
+		}
+		if membership.Role == domain.OrgRoleOwner {
+			if err := ensureAnotherOwner(ctx, repos, actor.ID, userID); err != nil {
+				return err
+			}
+		}
+
+		if err := repos.Organizations().RemoveMember(ctx, actor.ID, userID); err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, domain.AuditMemberRemoved, &userID)
+		entry.Metadata = organizationMetadata(actor.ID, membership.Role)
+		return repos.Audit().Append(ctx, entry)
+	})
+}
+
+// findMember membaca membership beserta data user-nya
+func findMember(ctx context.Context, repos repository.Repositories, orgID, userID uuid.UUID) (*domain.Membership, error) {
+	membership, err := repos.Organizations().FindMembership(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// User dibaca dalam scope tenant organization tersebut
+	user, err := repos.Users().FindById(domain.WithTenant(ctx, domain.Tenant{ID: orgID}), userID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil, domain.ErrMemberNotFound.Wrap(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	membership.User = user
+	return membership, nil
+}
+
+// ensureAnotherOwner mengunci owner organization dan memastikan masih ada
+// owner selain userID
+func ensureAnotherOwner(ctx context.Context, repos repository.Repositories, orgID, userID uuid.UUID) error {
+	owners, err := repos.Organizations().LockOwners(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	for _, owner := range owners {
+		if owner != userID {
+			return nil
+		}
+	}
+	return domain.ErrLastOwner
+}
+
+func organizationMetadata(orgID uuid.UUID, role string) domain.AuditMetadata {
+	return domain.AuditMetadata{"organization_id": orgID.String(), "role": role}
+}
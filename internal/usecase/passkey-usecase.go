@@ -0,0 +1,462 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/repository"
+	"github.com/Hilmarch27/gin-api/pkg/metrics"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+)
+
+const (
+	// Tipe sesi ceremony WebAuthn, agar challenge registrasi tidak bisa
+	// dipakai untuk login dan sebaliknya
+	passkeyRegistrationType = "passkey_registration"
+	passkeyLoginType        = "passkey_login"
+
+	// passkeySessionTTL adalah batas waktu browser menyelesaikan ceremony
+	passkeySessionTTL = 5 * time.Minute
+
+	defaultPasskeyName = "Passkey"
+)
+
+type PasskeyUsecase interface {
+	RegistrationOptions(ctx context.Context, userID uuid.UUID) (options *protocol.CredentialCreation, session string, err error)
+	Register(ctx context.Context, userID uuid.UUID, req *domain.RegisterPasskeyRequest, session string) (*domain.PasskeyResponse, error)
+	LoginOptions(ctx context.Context) (options *protocol.CredentialAssertion, session string, err error)
+	Login(ctx context.Context, req *domain.PasskeyLoginRequest, session string) (*domain.User, error)
+
+	List(ctx context.Context, userID uuid.UUID) ([]*domain.PasskeyResponse, error)
+	Rename(ctx context.Context, userID, id uuid.UUID, req *domain.RenamePasskeyRequest) (*domain.PasskeyResponse, error)
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+	RunCleanup(ctx context.Context, interval time.Duration, log *slog.Logger)
+}
+
+type passkeyUsecase struct {
+	passkeyRepo repository.PasskeyRepository
+	userRepo    repository.UserRepository
+	uow         repository.UnitOfWork
+	webauthn    *webauthn.WebAuthn
+}
+
+// NewPasskeyUsecase membuat usecase registrasi dan login passkey (WebAuthn)
+func NewPasskeyUsecase(pr repository.PasskeyRepository, ur repository.UserRepository, uow repository.UnitOfWork, wa *webauthn.WebAuthn) PasskeyUsecase {
+	return &passkeyUsecase{
+		passkeyRepo: pr,
+		userRepo:    ur,
+		uow:         uow,
+		webauthn:    wa,
+	}
+}
+
+// passkeyUser menyesuaikan user dengan interface webauthn.User. User
+// handle WebAuthn adalah 16 byte ID user.
+type passkeyUser struct {
+	user     *domain.User
+	passkeys []domain.Passkey
+}
+
+func (u *passkeyUser) WebAuthnID() []byte {
+	return u.user.ID[:]
+}
+
+func (u *passkeyUser) WebAuthnName() string {
+	return u.user.Email
+}
+
+func (u *passkeyUser) WebAuthnDisplayName() string {
+	if u.user.DisplayName != "" {
+		return u.user.DisplayName
+	}
+	return u.user.Name
+}
+
+func (u *passkeyUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, len(u.passkeys))
+	for i := range u.passkeys {
+		credentials[i] = passkeyCredential(&u.passkeys[i])
+	}
+	return credentials
+}
+
+// passkeyCredential membangun kembali credential WebAuthn dari record
+// database
+func passkeyCredential(p *domain.Passkey) webauthn.Credential {
+	transports := make([]protocol.AuthenticatorTransport, len(p.Transports))
+	for i, t := range p.Transports {
+		transports[i] = protocol.AuthenticatorTransport(t)
+	}
+	return webauthn.Credential{
+		ID:              p.CredentialID,
+		PublicKey:       p.PublicKey,
+		AttestationType: p.AttestationType,
+		Transport:       transports,
+		Flags: webauthn.CredentialFlags{
+			BackupEligible: p.BackupEligible,
+			BackupState:    p.BackupState,
+		},
+		Authenticator: webauthn.Authenticator{
+			AAGUID:    p.AAGUID,
+			SignCount: uint32(p.SignCount),
+		},
+	}
+}
+
+// RegistrationOptions membuat options untuk navigator.credentials.create().
+// Passkey harus discoverable (tersimpan di authenticator) dan memverifikasi
+// user, agar bisa dipakai login tanpa email dan password.
+func (u *passkeyUsecase) RegistrationOptions(ctx context.Context, userID uuid.UUID) (_ *protocol.CredentialCreation, _ string, err error) {
+	ctx, span := tracer.Start(ctx, "passkeyUsecase.RegistrationOptions")
+	defer func() { endSpan(span, err) }()
+
+	user, err := u.loadUser(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Authenticator yang sudah terdaftar tidak boleh mendaftar lagi
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(user.passkeys))
+	for _, credential := range user.WebAuthnCredentials() {
+		exclusions = append(exclusions, credential.Descriptor())
+	}
+
+	options, session, err := u.webauthn.BeginRegistration(user,
+		webauthn.WithAuthenticatorSelection(protocol.AuthenticatorSelection{
+			ResidentKey:      protocol.ResidentKeyRequirementRequired,
+			UserVerification: protocol.VerificationRequired,
+		}),
+		webauthn.WithExclusions(exclusions),
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	id, err := u.startSession(ctx, passkeyRegistrationType, session)
+	if err != nil {
+		return nil, "", err
+	}
+	return options, id, nil
+}
+
+// Register memverifikasi hasil registrasi dari browser lalu menyimpan
+// passkey
+func (u *passkeyUsecase) Register(ctx context.Context, userID uuid.UUID, req *domain.RegisterPasskeyRequest, session string) (_ *domain.PasskeyResponse, err error) {
+	ctx, span := tracer.Start(ctx, "passkeyUsecase.Register")
+	defer func() { endSpan(span, err) }()
+
+	data, err := u.takeSession(ctx, session, passkeyRegistrationType)
+	if err != nil {
+		return nil, err
+	}
+	// Sesi registrasi hanya berlaku untuk user yang memulainya
+	if !bytes.Equal(data.UserID, userID[:]) {
+		return nil, domain.ErrInvalidPasskeySession
+	}
+
+	parsed, err := req.Credential.Parse()
+	if err != nil {
+		return nil, domain.ErrPasskeyRegistrationFailed.Wrap(err)
+	}
+
+	user, err := u.loadUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	credential, err := u.webauthn.CreateCredential(user, *data, parsed)
+	if err != nil {
+		return nil, domain.ErrPasskeyRegistrationFailed.Wrap(err)
+	}
+
+	name := req.Name
+	if name == "" {
+		name = defaultPasskeyName
+	}
+	transports := make([]string, len(credential.Transport))
+	for i, t := range credential.Transport {
+		transports[i] = string(t)
+	}
+	passkey := &domain.Passkey{
+		UserID:          userID,
+		Name:            name,
+		CredentialID:    credential.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		AAGUID:          credential.Authenticator.AAGUID,
+		SignCount:       int64(credential.Authenticator.SignCount),
+		Transports:      transports,
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+	}
+
+	err = u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		if err := repos.Passkeys().Create(ctx, passkey); err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, domain.AuditPasskeyRegistered, &userID)
+		entry.Metadata = domain.AuditMetadata{"passkey_id": passkey.ID.String(), "name": passkey.Name}
+		return repos.Audit().Append(ctx, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewPasskeyResponse(passkey), nil
+}
+
+// LoginOptions membuat options untuk navigator.credentials.get(). Login
+// memakai discoverable credential: browser menawarkan passkey yang
+// tersimpan sehingga user tidak perlu mengisi email.
+func (u *passkeyUsecase) LoginOptions(ctx context.Context) (_ *protocol.CredentialAssertion, _ string, err error) {
+	ctx, span := tracer.Start(ctx, "passkeyUsecase.LoginOptions")
+	defer func() { endSpan(span, err) }()
+
+	options, session, err := u.webauthn.BeginDiscoverableLogin(webauthn.WithUserVerification(protocol.VerificationRequired))
+	if err != nil {
+		return nil, "", err
+	}
+
+	id, err := u.startSession(ctx, passkeyLoginType, session)
+	if err != nil {
+		return nil, "", err
+	}
+	return options, id, nil
+}
+
+// Login memverifikasi assertion dari browser dan mengembalikan pemilik
+// passkey. Token diterbitkan handler lewat AuthUsecase.CompleteLogin.
+func (u *passkeyUsecase) Login(ctx context.Context, req *domain.PasskeyLoginRequest, session string) (_ *domain.User, err error) {
+	ctx, span := tracer.Start(ctx, "passkeyUsecase.Login")
+	defer func() { endSpan(span, err) }()
+
+	data, err := u.takeSession(ctx, session, passkeyLoginType)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := req.Credential.Parse()
+	if err != nil {
+		return nil, u.loginFailed(ctx, nil, err)
+	}
+
+	// Pemilik credential dicari dari credential ID; user handle yang
+	// dikirim authenticator harus sama dengan ID pemilik
+	var owner *passkeyUser
+	var passkey *domain.Passkey
+	var lookupErr error
+	lookup := func(rawID, userHandle []byte) (webauthn.User, error) {
+		found, err := u.passkeyRepo.FindByCredentialID(ctx, rawID)
+		if err == nil && !bytes.Equal(found.UserID[:], userHandle) {
+			err = domain.ErrPasskeyNotFound
+		}
+		if err != nil {
+			lookupErr = err
+			return nil, err
+		}
+		user, err := u.userRepo.FindById(ctx, found.UserID)
+		if err != nil {
+			lookupErr = err
+			return nil, err
+		}
+		passkey = found
+		owner = &passkeyUser{user: user, passkeys: []domain.Passkey{*found}}
+		return owner, nil
+	}
+
+	credential, err := u.webauthn.ValidateDiscoverableLogin(lookup, *data, parsed)
+	if err != nil {
+		// Error database tetap dilaporkan sebagai error internal
+		if lookupErr != nil && domain.KindOf(lookupErr) == domain.KindInternal {
+			return nil, lookupErr
+		}
+		var userID *uuid.UUID
+		if passkey != nil {
+			userID = &passkey.UserID
+		}
+		return nil, u.loginFailed(ctx, userID, err)
+	}
+
+	// Sign count yang tidak naik berarti private key mungkin sudah
+	// digandakan; passkey ditolak sampai user mendaftarkannya ulang
+	if credential.Authenticator.CloneWarning {
+		metrics.AuthLogins.WithLabelValues(metrics.ResultFailure).Inc()
+		err := u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+			entry := newAuditEntry(ctx, domain.AuditPasskeyCloneDetected, &passkey.UserID)
+			entry.Metadata = domain.AuditMetadata{
+				"passkey_id":   passkey.ID.String(),
+				"stored_count": strconv.FormatInt(passkey.SignCount, 10),
+				"sign_count":   strconv.FormatUint(uint64(parsed.Response.AuthenticatorData.Counter), 10),
+			}
+			return repos.Audit().Append(ctx, entry)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrInvalidCredentials
+	}
+
+	err = u.passkeyRepo.RecordUse(ctx, passkey.ID, credential.Authenticator.SignCount, credential.Flags.BackupState, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return owner.user, nil
+}
+
+// loginFailed mencatat login passkey yang gagal ke audit log lalu
+// mengembalikan ErrInvalidCredentials
+func (u *passkeyUsecase) loginFailed(ctx context.Context, userID *uuid.UUID, cause error) error {
+	metrics.AuthLogins.WithLabelValues(metrics.ResultFailure).Inc()
+	err := u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		entry := newAuditEntry(ctx, domain.AuditLoginFailed, userID)
+		entry.Metadata = domain.AuditMetadata{"method": domain.LoginMethodPasskey}
+		return repos.Audit().Append(ctx, entry)
+	})
+	if err != nil {
+		return err
+	}
+	return domain.ErrInvalidCredentials.Wrap(cause)
+}
+
+func (u *passkeyUsecase) List(ctx context.Context, userID uuid.UUID) (_ []*domain.PasskeyResponse, err error) {
+	ctx, span := tracer.Start(ctx, "passkeyUsecase.List")
+	defer func() { endSpan(span, err) }()
+
+	passkeys, err := u.passkeyRepo.ListForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*domain.PasskeyResponse, len(passkeys))
+	for i := range passkeys {
+		responses[i] = domain.NewPasskeyResponse(&passkeys[i])
+	}
+	return responses, nil
+}
+
+func (u *passkeyUsecase) Rename(ctx context.Context, userID, id uuid.UUID, req *domain.RenamePasskeyRequest) (_ *domain.PasskeyResponse, err error) {
+	ctx, span := tracer.Start(ctx, "passkeyUsecase.Rename")
+	defer func() { endSpan(span, err) }()
+
+	passkey, err := u.passkeyRepo.Rename(ctx, userID, id, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewPasskeyResponse(passkey), nil
+}
+
+// Delete menghapus passkey user. User tanpa password dan identity SSO harus
+// tetap punya minimal satu passkey agar masih bisa login.
+func (u *passkeyUsecase) Delete(ctx context.Context, userID, id uuid.UUID) (err error) {
+	ctx, span := tracer.Start(ctx, "passkeyUsecase.Delete")
+	defer func() { endSpan(span, err) }()
+
+	return u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		user, err := repos.Users().FindById(ctx, userID)
+		if err != nil {
+			return err
+		}
+		methods, err := loginMethods(ctx, repos, user)
+		if err != nil {
+			return err
+		}
+
+		passkey, err := repos.Passkeys().Delete(ctx, userID, id)
+		if err != nil {
+			return err
+		}
+		if methods <= 1 {
+			return domain.ErrLastLoginMethod
+		}
+
+		entry := newAuditEntry(ctx, domain.AuditPasskeyRemoved, &userID)
+		entry.Metadata = domain.AuditMetadata{"passkey_id": passkey.ID.String(), "name": passkey.Name}
+		return repos.Audit().Append(ctx, entry)
+	})
+}
+
+// loginMethods menghitung cara login user: password, setiap identity SSO
+// dan setiap passkey
+func loginMethods(ctx context.Context, repos repository.Repositories, user *domain.User) (int, error) {
+	identities, err := repos.Identities().ListForUser(ctx, user.ID)
+	if err != nil {
+		return 0, err
+	}
+	passkeys, err := repos.Passkeys().ListForUser(ctx, user.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	methods := len(identities) + len(passkeys)
+	if user.Password != "" {
+		methods++
+	}
+	return methods, nil
+}
+
+func (u *passkeyUsecase) loadUser(ctx context.Context, userID uuid.UUID) (*passkeyUser, error) {
+	user, err := u.userRepo.FindById(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	passkeys, err := u.passkeyRepo.ListForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &passkeyUser{user: user, passkeys: passkeys}, nil
+}
+
+// startSession menyimpan sesi ceremony lalu mengembalikan ID-nya untuk
+// cookie
+func (u *passkeyUsecase) startSession(ctx context.Context, typ string, session *webauthn.SessionData) (string, error) {
+	challenge := &domain.PasskeyChallenge{
+		Type:      typ,
+		Session:   *session,
+		ExpiresAt: time.Now().Add(passkeySessionTTL),
+	}
+	if err := u.passkeyRepo.CreateChallenge(ctx, challenge); err != nil {
+		return "", err
+	}
+	return challenge.ID.String(), nil
+}
+
+// takeSession mengambil sekaligus menghapus sesi ceremony dari cookie,
+// sehingga setiap challenge hanya bisa diverifikasi sekali
+func (u *passkeyUsecase) takeSession(ctx context.Context, id, typ string) (*webauthn.SessionData, error) {
+	challengeID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrInvalidPasskeySession.Wrap(err)
+	}
+	challenge, err := u.passkeyRepo.ConsumeChallenge(ctx, challengeID, typ, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &challenge.Session, nil
+}
+
+// RunCleanup menghapus challenge yang expired tanpa pernah dipakai setiap
+// interval sampai ctx dibatalkan
+func (u *passkeyUsecase) RunCleanup(ctx context.Context, interval time.Duration, log *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := u.passkeyRepo.DeleteExpiredChallenges(ctx, time.Now())
+			if err != nil {
+				log.Error("failed to delete expired passkey challenges", "error", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Info("deleted expired passkey challenges", "count", deleted)
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/repository"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+)
+
+// memPasskeys menyimpan challenge di memori; challenge di-encode ke JSON
+// seperti kolom jsonb
+type memPasskeys struct {
+	repository.PasskeyRepository
+
+	mu         sync.Mutex
+	challenges map[uuid.UUID][]byte
+}
+
+func (r *memPasskeys) CreateChallenge(_ context.Context, challenge *domain.PasskeyChallenge) error {
+	challenge.ID = uuid.New()
+	data, err := json.Marshal(challenge)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.challenges[challenge.ID] = data
+	return nil
+}
+
+func (r *memPasskeys) ConsumeChallenge(_ context.Context, id uuid.UUID, typ string, now time.Time) (*domain.PasskeyChallenge, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data, ok := r.challenges[id]
+	if !ok {
+		return nil, domain.ErrInvalidPasskeySession
+	}
+	var challenge domain.PasskeyChallenge
+	if err := json.Unmarshal(data, &challenge); err != nil {
+		return nil, err
+	}
+	if challenge.Type != typ || !challenge.ExpiresAt.After(now) {
+		return nil, domain.ErrInvalidPasskeySession
+	}
+	delete(r.challenges, id)
+	return &challenge, nil
+}
+
+func newPasskeyTest(t *testing.T) (*passkeyUsecase, *memPasskeys) {
+	t.Helper()
+	wa, err := webauthn.New(&webauthn.Config{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{"https://example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	passkeys := &memPasskeys{challenges: map[uuid.UUID][]byte{}}
+	repos := newMemRepos(nil)
+	return &passkeyUsecase{passkeyRepo: passkeys, uow: &memUnitOfWork{repos}, webauthn: wa}, passkeys
+}
+
+func TestPasskeyLoginChallengeIsSingleUse(t *testing.T) {
+	u, passkeys := newPasskeyTest(t)
+	ctx := context.Background()
+
+	options, session, err := u.LoginOptions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored domain.PasskeyChallenge
+	if err := json.Unmarshal(passkeys.challenges[uuid.MustParse(session)], &stored); err != nil {
+		t.Fatalf("challenge not stored: %v", err)
+	}
+	if stored.Session.Challenge != options.Response.Challenge.String() {
+		t.Errorf("stored challenge %q does not match options %q", stored.Session.Challenge, options.Response.Challenge)
+	}
+
+	// Assertion tidak valid tetap menghabiskan challenge
+	_, err = u.Login(ctx, &domain.PasskeyLoginRequest{}, session)
+	if !errors.Is(err, domain.ErrInvalidCredentials) {
+		t.Fatalf("first login: err = %v, want %v", err, domain.ErrInvalidCredentials)
+	}
+	_, err = u.Login(ctx, &domain.PasskeyLoginRequest{}, session)
+	if !errors.Is(err, domain.ErrInvalidPasskeySession) {
+		t.Fatalf("replayed login: err = %v, want %v", err, domain.ErrInvalidPasskeySession)
+	}
+}
+
+func TestPasskeySessionRejectsWrongTypeAndGarbage(t *testing.T) {
+	u, _ := newPasskeyTest(t)
+	ctx := context.Background()
+
+	_, session, err := u.LoginOptions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := u.takeSession(ctx, session, passkeyRegistrationType); !errors.Is(err, domain.ErrInvalidPasskeySession) {
+		t.Errorf("login challenge used for registration: err = %v", err)
+	}
+	for _, id := range []string{"", "not-a-uuid", uuid.NewString()} {
+		if _, err := u.takeSession(ctx, id, passkeyLoginType); !errors.Is(err, domain.ErrInvalidPasskeySession) {
+			t.Errorf("session %q: err = %v, want %v", id, err, domain.ErrInvalidPasskeySession)
+		}
+	}
+}
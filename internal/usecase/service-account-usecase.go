@@ -0,0 +1,377 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	// apiKeyIDBytes adalah panjang key id acak (96 bit, 24 karakter hex)
+	apiKeyIDBytes = 12
+
+	// apiKeySecretBytes adalah panjang secret API key (256 bit)
+	apiKeySecretBytes = 32
+
+	// apiKeyTouchInterval membatasi update last_used_at per key
+	apiKeyTouchInterval = time.Minute
+)
+
+type ServiceAccountUsecase interface {
+	Create(ctx context.Context, creatorID uuid.UUID, req *domain.CreateServiceAccountRequest) (*domain.ServiceAccountResponse, error)
+	List(ctx context.Context) ([]*domain.ServiceAccountResponse, error)
+	Get(ctx context.Context, id uuid.UUID) (*domain.ServiceAccountResponse, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	ListKeys(ctx context.Context, accountID uuid.UUID) ([]*domain.APIKeyResponse, error)
+	CreateKey(ctx context.Context, accountID uuid.UUID, req *domain.CreateAPIKeyRequest) (*domain.CreatedAPIKeyResponse, error)
+	RotateKey(ctx context.Context, accountID, id uuid.UUID, req *domain.RotateAPIKeyRequest) (*domain.CreatedAPIKeyResponse, error)
+	RevokeKey(ctx context.Context, accountID, id uuid.UUID) error
+	Authenticate(ctx context.Context, key, ip string) (*domain.ServiceAccount, *domain.APIKey, error)
+}
+
+type serviceAccountUsecase struct {
+	accountRepo     repository.ServiceAccountRepository
+	keyRepo         repository.APIKeyRepository
+	uow             repository.UnitOfWork
+	rotationOverlap time.Duration
+}
+
+// NewServiceAccountUsecase membuat usecase service account. rotationOverlap
+// adalah masa berlaku key lama setelah dirotasi jika request tidak
+// menentukannya.
+func NewServiceAccountUsecase(sr repository.ServiceAccountRepository, kr repository.APIKeyRepository, uow repository.UnitOfWork, rotationOverlap time.Duration) ServiceAccountUsecase {
+	return &serviceAccountUsecase{
+		accountRepo:     sr,
+		keyRepo:         kr,
+		uow:             uow,
+		rotationOverlap: rotationOverlap,
+	}
+}
+
+// Create membuat service account. Scope admin hanya untuk role admin.
+func (u *serviceAccountUsecase) Create(ctx context.Context, creatorID uuid.UUID, req *domain.CreateServiceAccountRequest) (_ *domain.ServiceAccountResponse, err error) {
+	ctx, span := tracer.Start(ctx, "serviceAccountUsecase.Create")
+	defer func() { endSpan(span, err) }()
+
+	role := req.Role
+	if role == "" {
+		role = "service"
+	}
+	if role != "admin" && slices.ContainsFunc(req.Scopes, domain.AdminScope) {
+		return nil, domain.ErrScopeNotAllowed
+	}
+
+	// Scope disimpan unik dan terurut seperti personal access token
+	scopes := slices.Clone(req.Scopes)
+	slices.Sort(scopes)
+	scopes = slices.Compact(scopes)
+
+	account := &domain.ServiceAccount{
+		Name:        req.Name,
+		Description: req.Description,
+		Role:        role,
+		Scopes:      scopes,
+		CreatedBy:   &creatorID,
+	}
+	err = u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		if err := repos.ServiceAccounts().Create(ctx, account); err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, domain.AuditServiceAccountCreated, &account.ID)
+		entry.Metadata = domain.AuditMetadata{
+			"name":   account.Name,
+			"role":   account.Role,
+			"scopes": strings.Join(account.Scopes, " "),
+		}
+		return repos.Audit().Append(ctx, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewServiceAccountResponse(account), nil
+}
+
+func (u *serviceAccountUsecase) List(ctx context.Context) (_ []*domain.ServiceAccountResponse, err error) {
+	ctx, span := tracer.Start(ctx, "serviceAccountUsecase.List")
+	defer func() { endSpan(span, err) }()
+
+	accounts, err := u.accountRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*domain.ServiceAccountResponse, len(accounts))
+	for i := range accounts {
+		responses[i] = domain.NewServiceAccountResponse(&accounts[i])
+	}
+	return responses, nil
+}
+
+func (u *serviceAccountUsecase) Get(ctx context.Context, id uuid.UUID) (_ *domain.ServiceAccountResponse, err error) {
+	ctx, span := tracer.Start(ctx, "serviceAccountUsecase.Get")
+	defer func() { endSpan(span, err) }()
+
+	account, err := u.accountRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewServiceAccountResponse(account), nil
+}
+
+// Delete menghapus service account dan mencabut semua API key-nya
+func (u *serviceAccountUsecase) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := tracer.Start(ctx, "serviceAccountUsecase.Delete")
+	defer func() { endSpan(span, err) }()
+
+	return u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		account, err := repos.ServiceAccounts().FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := repos.ServiceAccounts().Delete(ctx, id); err != nil {
+			return err
+		}
+		if err := repos.APIKeys().RevokeAll(ctx, id, time.Now()); err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, domain.AuditServiceAccountDeleted, &id)
+		entry.Metadata = domain.AuditMetadata{"name": account.Name}
+		return repos.Audit().Append(ctx, entry)
+	})
+}
+
+func (u *serviceAccountUsecase) ListKeys(ctx context.Context, accountID uuid.UUID) (_ []*domain.APIKeyResponse, err error) {
+	ctx, span := tracer.Start(ctx, "serviceAccountUsecase.ListKeys")
+	defer func() { endSpan(span, err) }()
+
+	if _, err := u.accountRepo.FindByID(ctx, accountID); err != nil {
+		return nil, err
+	}
+	keys, err := u.keyRepo.ListForServiceAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*domain.APIKeyResponse, len(keys))
+	for i := range keys {
+		responses[i] = domain.NewAPIKeyResponse(&keys[i])
+	}
+	return responses, nil
+}
+
+// CreateKey membuat API key. Key utuh hanya ada di response ini.
+func (u *serviceAccountUsecase) CreateKey(ctx context.Context, accountID uuid.UUID, req *domain.CreateAPIKeyRequest) (_ *domain.CreatedAPIKeyResponse, err error) {
+	ctx, span := tracer.Start(ctx, "serviceAccountUsecase.CreateKey")
+	defer func() { endSpan(span, err) }()
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, domain.ErrInvalidExpiry
+	}
+	allowedIPs, err := normalizeIPs(req.AllowedIPs)
+	if err != nil {
+		return nil, err
+	}
+
+	var created *domain.CreatedAPIKeyResponse
+	err = u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		if _, err := repos.ServiceAccounts().FindByID(ctx, accountID); err != nil {
+			return err
+		}
+
+		key, raw, err := newAPIKey(accountID, req.Name, allowedIPs, req.ExpiresAt)
+		if err != nil {
+			return err
+		}
+		if err := repos.APIKeys().Create(ctx, key); err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, domain.AuditAPIKeyCreated, &accountID)
+		entry.Metadata = apiKeyMetadata(key)
+		if err := repos.Audit().Append(ctx, entry); err != nil {
+			return err
+		}
+
+		created = &domain.CreatedAPIKeyResponse{APIKeyResponse: *domain.NewAPIKeyResponse(key), Key: raw}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// RotateKey membuat key pengganti dengan nama dan allowlist yang sama. Key
+// lama tetap berlaku selama masa overlap agar service bisa berpindah tanpa
+// downtime; overlap 0 langsung mencabutnya.
+func (u *serviceAccountUsecase) RotateKey(ctx context.Context, accountID, id uuid.UUID, req *domain.RotateAPIKeyRequest) (_ *domain.CreatedAPIKeyResponse, err error) {
+	ctx, span := tracer.Start(ctx, "serviceAccountUsecase.RotateKey")
+	defer func() { endSpan(span, err) }()
+
+	now := time.Now()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return nil, domain.ErrInvalidExpiry
+	}
+	overlap := u.rotationOverlap
+	if req.OverlapSeconds != nil {
+		overlap = time.Duration(*req.OverlapSeconds) * time.Second
+	}
+
+	var created *domain.CreatedAPIKeyResponse
+	err = u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		old, err := repos.APIKeys().FindByID(ctx, accountID, id)
+		if err != nil {
+			return err
+		}
+
+		key, raw, err := newAPIKey(accountID, old.Name, old.AllowedIPs, req.ExpiresAt)
+		if err != nil {
+			return err
+		}
+		key.RotatedFromID = &old.ID
+		if err := repos.APIKeys().Create(ctx, key); err != nil {
+			return err
+		}
+
+		metadata := apiKeyMetadata(key)
+		metadata["rotated_from_id"] = old.ID.String()
+		if overlap > 0 {
+			oldExpiresAt := now.Add(overlap)
+			if err := repos.APIKeys().Expire(ctx, old.ID, oldExpiresAt); err != nil {
+				return err
+			}
+			metadata["overlap_until"] = oldExpiresAt.UTC().Format(time.RFC3339)
+		} else if _, err := repos.APIKeys().Revoke(ctx, accountID, old.ID, now); err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, domain.AuditAPIKeyRotated, &accountID)
+		entry.Metadata = metadata
+		if err := repos.Audit().Append(ctx, entry); err != nil {
+			return err
+		}
+
+		created = &domain.CreatedAPIKeyResponse{APIKeyResponse: *domain.NewAPIKeyResponse(key), Key: raw}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (u *serviceAccountUsecase) RevokeKey(ctx context.Context, accountID, id uuid.UUID) (err error) {
+	ctx, span := tracer.Start(ctx, "serviceAccountUsecase.RevokeKey")
+	defer func() { endSpan(span, err) }()
+
+	return u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		key, err := repos.APIKeys().Revoke(ctx, accountID, id, time.Now())
+		if err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, domain.AuditAPIKeyRevoked, &accountID)
+		entry.Metadata = apiKeyMetadata(key)
+		return repos.Audit().Append(ctx, entry)
+	})
+}
+
+// Authenticate memeriksa API key dan mengembalikan service account-nya. Key
+// yang tidak dikenal, salah, dicabut, kedaluwarsa atau milik service account
+// yang sudah dihapus menghasilkan ErrUnauthorized; IP di luar allowlist
+// menghasilkan ErrIPNotAllowed.
+func (u *serviceAccountUsecase) Authenticate(ctx context.Context, raw, ip string) (_ *domain.ServiceAccount, _ *domain.APIKey, err error) {
+	ctx, span := tracer.Start(ctx, "serviceAccountUsecase.Authenticate")
+	defer func() { endSpan(span, err) }()
+
+	keyID, _, ok := strings.Cut(strings.TrimPrefix(raw, domain.APIKeyPrefix), "_")
+	if !ok || keyID == "" {
+		return nil, nil, domain.ErrUnauthorized
+	}
+
+	key, err := u.keyRepo.FindByKeyID(ctx, keyID)
+	if errors.Is(err, domain.ErrAPIKeyNotFound) {
+		return nil, nil, domain.ErrUnauthorized.Wrap(err)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Bandingkan hash dalam waktu konstan agar secret tidak bisa ditebak
+	// lewat perbedaan waktu response
+	if subtle.ConstantTimeCompare([]byte(hashToken(raw)), []byte(key.SecretHash)) != 1 {
+		return nil, nil, domain.ErrUnauthorized
+	}
+
+	now := time.Now()
+	if !key.Active(now) || key.ServiceAccount == nil {
+		return nil, nil, domain.ErrUnauthorized
+	}
+	if !key.AllowsIP(ip) {
+		return nil, nil, domain.ErrIPNotAllowed
+	}
+
+	if err := u.keyRepo.TouchLastUsed(ctx, key.ID, now, ip, now.Add(-apiKeyTouchInterval)); err != nil {
+		return nil, nil, err
+	}
+	return key.ServiceAccount, key, nil
+}
+
+// newAPIKey membuat key baru beserta bentuk utuhnya gsk_<key id>_<secret>
+func newAPIKey(accountID uuid.UUID, name string, allowedIPs []string, expiresAt *time.Time) (*domain.APIKey, string, error) {
+	id := make([]byte, apiKeyIDBytes)
+	if _, err := rand.Read(id); err != nil {
+		return nil, "", err
+	}
+	secret := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+
+	keyID := hex.EncodeToString(id)
+	raw := domain.APIKeyPrefix + keyID + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	return &domain.APIKey{
+		ServiceAccountID: accountID,
+		Name:             name,
+		KeyID:            keyID,
+		SecretHash:       hashToken(raw),
+		AllowedIPs:       allowedIPs,
+		ExpiresAt:        expiresAt,
+	}, raw, nil
+}
+
+// normalizeIPs mengubah allowlist ke bentuk CIDR kanonis tanpa duplikat
+func normalizeIPs(ips []string) ([]string, error) {
+	out := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		prefix, err := domain.ParseIPPrefix(ip)
+		if err != nil {
+			return nil, domain.ErrInvalidInput.Wrap(err)
+		}
+		if s := prefix.String(); !slices.Contains(out, s) {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func apiKeyMetadata(key *domain.APIKey) domain.AuditMetadata {
+	return domain.AuditMetadata{
+		"api_key_id":  key.ID.String(),
+		"name":        key.Name,
+		"key_id":      key.KeyID,
+		"allowed_ips": strings.Join(key.AllowedIPs, " "),
+	}
+}
@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+// memAPIKeys menyimpan API key milik satu service account di memori
+type memAPIKeys struct {
+	repository.APIKeyRepository
+
+	account *domain.ServiceAccount
+	keys    map[uuid.UUID]*domain.APIKey
+}
+
+func (r *memAPIKeys) Create(_ context.Context, key *domain.APIKey) error {
+	key.ID = uuid.New()
+	saved := *key
+	r.keys[key.ID] = &saved
+	return nil
+}
+
+func (r *memAPIKeys) FindByKeyID(_ context.Context, keyID string) (*domain.APIKey, error) {
+	for _, key := range r.keys {
+		if key.KeyID == keyID {
+			found := *key
+			found.ServiceAccount = r.account
+			return &found, nil
+		}
+	}
+	return nil, domain.ErrAPIKeyNotFound
+}
+
+func (r *memAPIKeys) FindByID(_ context.Context, accountID, id uuid.UUID) (*domain.APIKey, error) {
+	key, ok := r.keys[id]
+	if !ok || key.ServiceAccountID != accountID {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	found := *key
+	return &found, nil
+}
+
+func (r *memAPIKeys) Revoke(ctx context.Context, accountID, id uuid.UUID, at time.Time) (*domain.APIKey, error) {
+	if _, err := r.FindByID(ctx, accountID, id); err != nil {
+		return nil, err
+	}
+	r.keys[id].RevokedAt = &at
+	return r.FindByID(ctx, accountID, id)
+}
+
+func (r *memAPIKeys) Expire(_ context.Context, id uuid.UUID, at time.Time) error {
+	r.keys[id].ExpiresAt = &at
+	return nil
+}
+
+func (r *memAPIKeys) TouchLastUsed(context.Context, uuid.UUID, time.Time, string, time.Time) error {
+	return nil
+}
+
+// newServiceAccountTest membuat usecase dengan satu service account dan satu
+// API key aktif; mengembalikan key utuhnya
+func newServiceAccountTest(t *testing.T, overlap time.Duration) (*serviceAccountUsecase, *memAPIKeys, *domain.APIKey, string) {
+	t.Helper()
+	account := &domain.ServiceAccount{ID: uuid.New(), Name: "ci", Role: "service"}
+	keys := &memAPIKeys{account: account, keys: map[uuid.UUID]*domain.APIKey{}}
+	repos := newMemRepos(nil)
+	repos.apiKeys = keys
+
+	key, raw, err := newAPIKey(account.ID, "deploy", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := keys.Create(context.Background(), key); err != nil {
+		t.Fatal(err)
+	}
+	u := &serviceAccountUsecase{keyRepo: keys, uow: &memUnitOfWork{repos}, rotationOverlap: overlap}
+	return u, keys, key, raw
+}
+
+func TestRotateKeyOverlap(t *testing.T) {
+	zero := 0
+	tests := []struct {
+		name        string
+		overlap     *int
+		oldAccepted bool
+	}{
+		{"default overlap", nil, true},
+		{"no overlap", &zero, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, _, old, oldRaw := newServiceAccountTest(t, time.Hour)
+			ctx := context.Background()
+
+			rotated, err := u.RotateKey(ctx, old.ServiceAccountID, old.ID, &domain.RotateAPIKeyRequest{OverlapSeconds: tt.overlap})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := u.Authenticate(ctx, rotated.Key, "192.0.2.1"); err != nil {
+				t.Errorf("new key rejected: %v", err)
+			}
+			_, _, err = u.Authenticate(ctx, oldRaw, "192.0.2.1")
+			if tt.oldAccepted && err != nil {
+				t.Errorf("old key rejected during overlap: %v", err)
+			}
+			if !tt.oldAccepted && !errors.Is(err, domain.ErrUnauthorized) {
+				t.Errorf("old key: err = %v, want %v", err, domain.ErrUnauthorized)
+			}
+		})
+	}
+}
+
+func TestRotatedKeyExpiresAfterOverlap(t *testing.T) {
+	u, keys, old, oldRaw := newServiceAccountTest(t, time.Hour)
+	ctx := context.Background()
+	overlap := 1
+
+	start := time.Now()
+	rotated, err := u.RotateKey(ctx, old.ServiceAccountID, old.ID, &domain.RotateAPIKeyRequest{OverlapSeconds: &overlap})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expiresAt := keys.keys[old.ID].ExpiresAt
+	if expiresAt == nil || expiresAt.Before(start.Add(time.Second)) || expiresAt.After(time.Now().Add(time.Second)) {
+		t.Fatalf("old key expires at %v, want one second after rotation", expiresAt)
+	}
+	if keys.keys[old.ID].RevokedAt != nil {
+		t.Fatal("old key revoked during overlap")
+	}
+	if _, _, err := u.Authenticate(ctx, oldRaw, "192.0.2.1"); err != nil {
+		t.Fatalf("old key rejected during overlap: %v", err)
+	}
+
+	time.Sleep(time.Until(*expiresAt))
+	if _, _, err := u.Authenticate(ctx, oldRaw, "192.0.2.1"); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("old key after overlap: err = %v, want %v", err, domain.ErrUnauthorized)
+	}
+	if _, _, err := u.Authenticate(ctx, rotated.Key, "192.0.2.1"); err != nil {
+		t.Errorf("new key rejected after overlap: %v", err)
+	}
+}
@@ -0,0 +1,420 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/repository"
+	"github.com/Hilmarch27/gin-api/pkg/i18n"
+	"github.com/Hilmarch27/gin-api/pkg/oidc"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+const (
+	// ssoStateType membedakan state SSO dari access/refresh token yang
+	// ditandatangani dengan secret yang sama
+	ssoStateType = "sso_state"
+
+	// ssoStateTTL adalah batas waktu user menyelesaikan login di provider
+	ssoStateTTL = 10 * time.Minute
+)
+
+type SSOUsecase interface {
+	Providers() []*domain.SSOProviderResponse
+	Start(ctx context.Context, provider string, req *domain.SSOStartRequest, linkUserID *uuid.UUID) (redirect, state string, err error)
+	Callback(ctx context.Context, provider string, req *domain.SSOCallbackRequest, state string) (*domain.SSOResult, error)
+	ErrorRedirect(err error) string
+
+	ListIdentities(ctx context.Context, userID uuid.UUID) ([]*domain.IdentityResponse, error)
+	Unlink(ctx context.Context, userID, id uuid.UUID) error
+}
+
+type ssoUsecase struct {
+	identityRepo repository.IdentityRepository
+	userRepo     repository.UserRepository
+	uow          repository.UnitOfWork
+	providers    map[string]*oidc.Provider
+	cfg          oidc.FederationConfig
+	jwtSecret    []byte
+}
+
+// NewSSOUsecase membuat usecase login lewat identity provider OIDC
+// eksternal. Urutan providers mengikuti konfigurasi.
+func NewSSOUsecase(ir repository.IdentityRepository, ur repository.UserRepository, uow repository.UnitOfWork, providers []*oidc.Provider, cfg oidc.FederationConfig, secret string) SSOUsecase {
+	byName := make(map[string]*oidc.Provider, len(providers))
+	for _, p := range providers {
+		byName[p.Config().Name] = p
+	}
+	return &ssoUsecase{
+		identityRepo: ir,
+		userRepo:     ur,
+		uow:          uow,
+		providers:    byName,
+		cfg:          cfg,
+		jwtSecret:    []byte(secret),
+	}
+}
+
+// ssoStateClaims disimpan di cookie HttpOnly selama user login di provider.
+// State dicocokkan dengan parameter callback (CSRF), nonce dengan ID token,
+// dan Verifier adalah code_verifier PKCE.
+type ssoStateClaims struct {
+	Type       string     `json:"typ"`
+	Provider   string     `json:"provider"`
+	State      string     `json:"state"`
+	Nonce      string     `json:"nonce"`
+	Verifier   string     `json:"verifier"`
+	ReturnTo   string     `json:"return_to,omitempty"`
+	LinkUserID *uuid.UUID `json:"link_user_id,omitempty"`
+	jwt.RegisteredClaims
+}
+
+func (u *ssoUsecase) Providers() []*domain.SSOProviderResponse {
+	responses := make([]*domain.SSOProviderResponse, 0, len(u.cfg.Providers))
+	for _, p := range u.cfg.Providers {
+		responses = append(responses, &domain.SSOProviderResponse{Name: p.Name, DisplayName: p.DisplayName})
+	}
+	return responses
+}
+
+// Start membuat URL authorization request ke provider beserta state yang
+// harus disimpan handler di cookie sampai callback. linkUserID diisi jika
+// provider akan dihubungkan ke user yang sedang login.
+func (u *ssoUsecase) Start(ctx context.Context, name string, req *domain.SSOStartRequest, linkUserID *uuid.UUID) (_, _ string, err error) {
+	ctx, span := tracer.Start(ctx, "ssoUsecase.Start")
+	defer func() { endSpan(span, err) }()
+
+	provider, ok := u.providers[name]
+	if !ok {
+		return "", "", domain.ErrSSOProviderNotFound
+	}
+	if req.Link && linkUserID == nil {
+		return "", "", domain.ErrUnauthorized
+	}
+
+	var secrets [3]string
+	for i := range secrets {
+		if secrets[i], err = randomToken(); err != nil {
+			return "", "", err
+		}
+	}
+	state, nonce, verifier := secrets[0], secrets[1], secrets[2]
+	challenge := sha256.Sum256([]byte(verifier))
+
+	redirect, err := provider.AuthCodeURL(ctx, u.callbackURL(name), state, nonce, base64.RawURLEncoding.EncodeToString(challenge[:]))
+	if err != nil {
+		return "", "", domain.ErrSSOFailed.Wrap(err)
+	}
+
+	claims := &ssoStateClaims{
+		Type:     ssoStateType,
+		Provider: name,
+		State:    state,
+		Nonce:    nonce,
+		Verifier: verifier,
+		ReturnTo: u.returnTo(req.ReturnTo),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ssoStateTTL)),
+		},
+	}
+	if req.Link {
+		claims.LinkUserID = linkUserID
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(u.jwtSecret)
+	if err != nil {
+		return "", "", err
+	}
+	return redirect, signed, nil
+}
+
+// Callback memverifikasi response provider lalu mencari user untuk
+// identity tersebut: identity yang sudah terhubung, user dengan email yang
+// sama (jika email diverifikasi provider dan oleh service ini), atau user
+// baru (just-in-time)
+func (u *ssoUsecase) Callback(ctx context.Context, name string, req *domain.SSOCallbackRequest, state string) (_ *domain.SSOResult, err error) {
+	ctx, span := tracer.Start(ctx, "ssoUsecase.Callback")
+	defer func() { endSpan(span, err) }()
+
+	provider, ok := u.providers[name]
+	if !ok {
+		return nil, domain.ErrSSOProviderNotFound
+	}
+	claims, err := u.parseState(state, name, req.State)
+	if err != nil {
+		return nil, err
+	}
+	if req.Error != "" {
+		return nil, domain.ErrSSOFailed.Wrap(fmt.Errorf("provider returned %s: %s", req.Error, req.ErrorDescription))
+	}
+	if req.Code == "" {
+		return nil, domain.ErrSSOFailed
+	}
+
+	idToken, err := provider.Exchange(ctx, req.Code, claims.Verifier, u.callbackURL(name), claims.Nonce)
+	if err != nil {
+		return nil, domain.ErrSSOFailed.Wrap(err)
+	}
+	cfg := provider.Config()
+	if idToken.Email != "" && !cfg.AllowsEmail(idToken.Email) {
+		return nil, domain.ErrSSOEmailDomain
+	}
+
+	result := &domain.SSOResult{ReturnTo: claims.ReturnTo, Linked: claims.LinkUserID != nil}
+	if result.ReturnTo == "" {
+		result.ReturnTo = u.cfg.SuccessURL
+	}
+
+	err = u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		var err error
+		if claims.LinkUserID != nil {
+			result.User, err = u.link(ctx, repos, cfg, idToken, *claims.LinkUserID)
+		} else {
+			result.User, err = u.login(ctx, repos, cfg, idToken)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// login mencari atau membuat user untuk identity yang login
+func (u *ssoUsecase) login(ctx context.Context, repos repository.Repositories, cfg oidc.ProviderConfig, idToken *oidc.IDToken) (*domain.User, error) {
+	now := time.Now()
+
+	identity, err := repos.Identities().FindBySubject(ctx, cfg.Name, idToken.Subject)
+	if err == nil {
+		if err := repos.Identities().TouchLastLogin(ctx, identity.ID, now); err != nil {
+			return nil, err
+		}
+		return repos.Users().FindById(ctx, identity.UserID)
+	}
+	if !errors.Is(err, domain.ErrIdentityNotFound) {
+		return nil, err
+	}
+
+	// Identity baru hanya dihubungkan lewat email yang diverifikasi
+	// provider, agar akun orang lain tidak bisa diambil alih
+	if idToken.Email == "" {
+		return nil, domain.ErrSSOEmailRequired
+	}
+	if !idToken.EmailVerified {
+		return nil, domain.ErrSSOEmailNotVerified
+	}
+
+	user, err := repos.Users().FindByEmail(ctx, idToken.Email)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		if !cfg.Provision {
+			return nil, domain.ErrSSOAccountNotFound
+		}
+		user, err = u.provision(ctx, repos, cfg, idToken)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Email akun lokal bisa didaftarkan siapa saja. Tanpa bukti kepemilikan,
+	// pemilik akun harus login dulu lalu menghubungkan provider dari sesinya.
+	if !user.EmailVerified() {
+		return nil, domain.ErrSSOLinkRequired
+	}
+
+	if err := u.createIdentity(ctx, repos, cfg, idToken, user.ID, &now); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// provision membuat user baru dari claim ID token. User tanpa password
+// hanya bisa login lewat provider.
+func (u *ssoUsecase) provision(ctx context.Context, repos repository.Repositories, cfg oidc.ProviderConfig, idToken *oidc.IDToken) (*domain.User, error) {
+	name := idToken.Name
+	if name == "" {
+		name, _, _ = strings.Cut(idToken.Email, "@")
+	}
+	now := time.Now()
+	user := &domain.User{
+		Name:            name,
+		Email:           domain.NormalizeEmail(idToken.Email),
+		Locale:          i18n.FromContext(ctx),
+		AvatarURL:       idToken.Picture,
+		EmailVerifiedAt: &now,
+	}
+	if err := repos.Users().Create(ctx, user); err != nil {
+		return nil, err
+	}
+
+	entry := newAuditEntry(ctx, domain.AuditUserRegistered, &user.ID)
+	entry.ActorID = &user.ID
+	entry.ActorType = domain.PrincipalUser
+	entry.Changes = userChanges(&domain.User{}, user)
+	entry.Metadata = domain.AuditMetadata{"provider": cfg.Name}
+	if err := repos.Audit().Append(ctx, entry); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// link menghubungkan identity ke user yang sedang login
+func (u *ssoUsecase) link(ctx context.Context, repos repository.Repositories, cfg oidc.ProviderConfig, idToken *oidc.IDToken, userID uuid.UUID) (*domain.User, error) {
+	user, err := repos.Users().FindById(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := repos.Identities().FindBySubject(ctx, cfg.Name, idToken.Subject)
+	if err == nil {
+		if identity.UserID != userID {
+			return nil, domain.ErrIdentityLinked
+		}
+		return user, nil
+	}
+	if !errors.Is(err, domain.ErrIdentityNotFound) {
+		return nil, err
+	}
+
+	if err := u.createIdentity(ctx, repos, cfg, idToken, userID, nil); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (u *ssoUsecase) createIdentity(ctx context.Context, repos repository.Repositories, cfg oidc.ProviderConfig, idToken *oidc.IDToken, userID uuid.UUID, loginAt *time.Time) error {
+	identity := &domain.FederatedIdentity{
+		UserID:      userID,
+		Provider:    cfg.Name,
+		Subject:     idToken.Subject,
+		Email:       idToken.Email,
+		LastLoginAt: loginAt,
+	}
+	if err := repos.Identities().Create(ctx, identity); err != nil {
+		return err
+	}
+
+	entry := newAuditEntry(ctx, domain.AuditIdentityLinked, &userID)
+	entry.ActorID = &userID
+	entry.ActorType = domain.PrincipalUser
+	entry.Metadata = domain.AuditMetadata{"provider": cfg.Name, "email": idToken.Email}
+	return repos.Audit().Append(ctx, entry)
+}
+
+// parseState memverifikasi cookie state dan mencocokkannya dengan
+// parameter state dari provider
+func (u *ssoUsecase) parseState(signed, provider, state string) (*ssoStateClaims, error) {
+	if signed == "" {
+		return nil, domain.ErrInvalidSSOState
+	}
+	var claims ssoStateClaims
+	_, err := jwt.ParseWithClaims(signed, &claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("invalid signing method")
+		}
+		return u.jwtSecret, nil
+	})
+	if err != nil {
+		return nil, domain.ErrInvalidSSOState.Wrap(err)
+	}
+	if claims.Type != ssoStateType || claims.Provider != provider ||
+		subtle.ConstantTimeCompare([]byte(claims.State), []byte(state)) != 1 {
+		return nil, domain.ErrInvalidSSOState
+	}
+	return &claims, nil
+}
+
+// ErrorRedirect membangun URL halaman error frontend berisi kode error
+func (u *ssoUsecase) ErrorRedirect(err error) string {
+	code := "server_error"
+	var de *domain.Error
+	if errors.As(err, &de) && de.Kind != domain.KindInternal {
+		code = de.Code
+	}
+	return appendQuery(u.cfg.ErrorURL, url.Values{"error": {code}})
+}
+
+func (u *ssoUsecase) ListIdentities(ctx context.Context, userID uuid.UUID) (_ []*domain.IdentityResponse, err error) {
+	ctx, span := tracer.Start(ctx, "ssoUsecase.ListIdentities")
+	defer func() { endSpan(span, err) }()
+
+	identities, err := u.identityRepo.ListForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*domain.IdentityResponse, len(identities))
+	for i := range identities {
+		responses[i] = domain.NewIdentityResponse(&identities[i])
+	}
+	return responses, nil
+}
+
+// Unlink memutus identity dari user. User harus tetap punya minimal satu
+// cara login lain (password, identity lain atau passkey).
+func (u *ssoUsecase) Unlink(ctx context.Context, userID, id uuid.UUID) (err error) {
+	ctx, span := tracer.Start(ctx, "ssoUsecase.Unlink")
+	defer func() { endSpan(span, err) }()
+
+	return u.uow.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		user, err := repos.Users().FindById(ctx, userID)
+		if err != nil {
+			return err
+		}
+		identities, err := repos.Identities().ListForUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		i := slices.IndexFunc(identities, func(identity domain.FederatedIdentity) bool { return identity.ID == id })
+		if i < 0 {
+			return domain.ErrIdentityNotFound
+		}
+		methods, err := loginMethods(ctx, repos, user)
+		if err != nil {
+			return err
+		}
+		if methods <= 1 {
+			return domain.ErrLastLoginMethod
+		}
+
+		if err := repos.Identities().Delete(ctx, userID, id); err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, domain.AuditIdentityUnlinked, &userID)
+		entry.Metadata = domain.AuditMetadata{"provider": identities[i].Provider, "email": identities[i].Email}
+		return repos.Audit().Append(ctx, entry)
+	})
+}
+
+// callbackURL adalah redirect_uri yang didaftarkan di provider
+func (u *ssoUsecase) callbackURL(provider string) string {
+	return u.cfg.CallbackURL + "/" + url.PathEscape(provider) + "/callback"
+}
+
+// returnTo hanya menerima URL dengan origin yang diizinkan (open redirect);
+// selain itu kosong sehingga SuccessURL yang dipakai
+func (u *ssoUsecase) returnTo(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	target, err := url.Parse(raw)
+	if err != nil || target.Scheme == "" || target.Host == "" {
+		return ""
+	}
+	for _, origin := range u.cfg.ReturnOrigins {
+		allowed, err := url.Parse(origin)
+		if err == nil && strings.EqualFold(allowed.Scheme, target.Scheme) && strings.EqualFold(allowed.Host, target.Host) {
+			return target.String()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Hilmarch27/gin-api/internal/domain"
+	"github.com/Hilmarch27/gin-api/internal/repository"
+	"github.com/Hilmarch27/gin-api/pkg/oidc"
+)
+
+// memIdentities menyimpan identity federasi di memori
+type memIdentities struct {
+	repository.IdentityRepository
+	identities []*domain.FederatedIdentity
+}
+
+func (r *memIdentities) FindBySubject(_ context.Context, provider, subject string) (*domain.FederatedIdentity, error) {
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return nil, domain.ErrIdentityNotFound
+}
+
+func (r *memIdentities) Create(_ context.Context, identity *domain.FederatedIdentity) error {
+	r.identities = append(r.identities, identity)
+	return nil
+}
+
+func TestSSOLoginLinksOnlyVerifiedEmail(t *testing.T) {
+	verifiedAt := time.Now()
+	cfg := oidc.ProviderConfig{Name: "test", Provision: true}
+	idToken := &oidc.IDToken{Subject: "sub-1", Email: " Alice@Example.COM", EmailVerified: true, Name: "Alice"}
+
+	tests := []struct {
+		name     string
+		user     func() *domain.User
+		wantErr  error
+		wantLink bool
+	}{
+		{"unverified local account", func() *domain.User {
+			return testUser()
+		}, domain.ErrSSOLinkRequired, false},
+		{"verified local account", func() *domain.User {
+			user := testUser()
+			user.EmailVerifiedAt = &verifiedAt
+			return user
+		}, nil, true},
+		{"new account", func() *domain.User {
+			return nil
+		}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMemRepos(tt.user())
+			identities := &memIdentities{}
+			repos.identities = identities
+			u := &ssoUsecase{}
+
+			user, err := u.login(context.Background(), repos, cfg, idToken)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if linked := len(identities.identities) == 1; linked != tt.wantLink {
+				t.Fatalf("identity linked = %v, want %v", linked, tt.wantLink)
+			}
+			if err != nil {
+				return
+			}
+			if user.ID != repos.users.user.ID || user.Email != "alice@example.com" || !user.EmailVerified() {
+				t.Errorf("unexpected user: %+v", user)
+			}
+			if identities.identities[0].UserID != user.ID {
+				t.Errorf("identity linked to %s, want %s", identities.identities[0].UserID, user.ID)
+			}
+		})
+	}
+}
+
+func TestSaveUserResetsVerificationOnEmailChange(t *testing.T) {
+	verifiedAt := time.Now()
+	user := testUser()
+	user.EmailVerifiedAt = &verifiedAt
+	repos := newMemRepos(user)
+	before := *user
+
+	changed := *user
+	changed.Email = "Alice@Example.com"
+	if err := saveUser(context.Background(), repos, &before, &changed); err != nil {
+		t.Fatal(err)
+	}
+	if changed.Email != "alice@example.com" || !changed.EmailVerified() {
+		t.Errorf("case-only change: email=%q verified=%v", changed.Email, changed.EmailVerified())
+	}
+
+	changed.Email = "mallory@example.com"
+	if err := saveUser(context.Background(), repos, &before, &changed); err != nil {
+		t.Fatal(err)
+	}
+	if repos.users.user.Email != "mallory@example.com" || repos.users.user.EmailVerified() {
+		t.Errorf("new email kept verification: %+v", repos.users.user)
+	}
+}
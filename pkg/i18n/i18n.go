@@ -0,0 +1,140 @@
+package i18n
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+
+	"golang.org/x/text/language"
+)
+
+const (
+	English    = "en"
+	Indonesian = "id"
+
+	DefaultLocale = English
+)
+
+// Supported adalah daftar locale yang punya katalog pesan
+var Supported = []string{English, Indonesian}
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+//go:embed templates
+var templateFS embed.FS
+
+var (
+	catalogs = map[string]map[string]string{}
+	matcher  = language.NewMatcher([]language.Tag{language.English, language.Indonesian})
+)
+
+func init() {
+	for _, locale := range Supported {
+		data, err := localeFS.ReadFile("locales/" + locale + ".json")
+		if err != nil {
+			panic(fmt.Sprintf("i18n: missing catalog %s: %v", locale, err))
+		}
+		messages := map[string]string{}
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog %s: %v", locale, err))
+		}
+		catalogs[locale] = messages
+	}
+}
+
+// IsSupported mengecek apakah locale punya katalog pesan
+func IsSupported(locale string) bool {
+	_, ok := catalogs[locale]
+	return ok
+}
+
+// Negotiate memilih locale terbaik dari header Accept-Language
+func Negotiate(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return DefaultLocale
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return DefaultLocale
+	}
+	return Supported[index]
+}
+
+// T menerjemahkan key ke locale yang diminta. Jika key tidak ada di
+// locale tersebut, dipakai bahasa Inggris; jika tetap tidak ada, key
+// dikembalikan apa adanya. args diformat dengan fmt.Sprintf.
+func T(locale, key string, args ...any) string {
+	if msg, ok := Lookup(locale, key, args...); ok {
+		return msg
+	}
+	return key
+}
+
+// Lookup seperti T tetapi melaporkan apakah key ditemukan
+func Lookup(locale, key string, args ...any) (string, bool) {
+	msg, ok := catalogs[locale][key]
+	if !ok {
+		msg, ok = catalogs[DefaultLocale][key]
+	}
+	if !ok {
+		return "", false
+	}
+	if len(args) > 0 {
+		msg = fmt.Sprintf(msg, args...)
+	}
+	return msg, true
+}
+
+type localeKey struct{}
+
+// WithLocale menyimpan locale di context
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// FromContext mengambil locale dari context, DefaultLocale jika tidak ada
+func FromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeKey{}).(string); ok && locale != "" {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// Email adalah hasil render template email
+type Email struct {
+	Subject string
+	Text    string
+}
+
+// RenderEmail merender templates/<locale>/<name>.tmpl. Template wajib
+// mendefinisikan blok "subject" dan "body".
+func RenderEmail(locale, name string, data any) (*Email, error) {
+	if !IsSupported(locale) {
+		locale = DefaultLocale
+	}
+
+	tmpl, err := template.ParseFS(templateFS, path.Join("templates", locale, name+".tmpl"))
+	if err != nil {
+		return nil, fmt.Errorf("i18n: parse email template %s/%s: %w", locale, name, err)
+	}
+
+	var subject, body bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, fmt.Errorf("i18n: render email subject %s/%s: %w", locale, name, err)
+	}
+	if err := tmpl.ExecuteTemplate(&body, "body", data); err != nil {
+		return nil, fmt.Errorf("i18n: render email body %s/%s: %w", locale, name, err)
+	}
+
+	return &Email{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(body.String()) + "\n",
+	}, nil
+}
@@ -0,0 +1,83 @@
+// Package imaging mendekode gambar upload, memperbaiki orientasi EXIF,
+// dan membuat varian persegi berukuran tetap. Gambar selalu di-encode
+// ulang sehingga metadata (EXIF, GPS, profil kamera) tidak ikut tersimpan.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("imaging: unsupported image format")
+	ErrTooManyPixels     = errors.New("imaging: image dimensions too large")
+)
+
+// Decode mendekode gambar JPEG, PNG, GIF atau WebP. Dimensi dicek sebelum
+// decode penuh agar gambar "decompression bomb" ditolak lebih awal.
+func Decode(data []byte, maxPixels int) (image.Image, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if errors.Is(err, image.ErrFormat) {
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, fmt.Errorf("imaging: decode config: %w", err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrTooManyPixels
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("imaging: decode %s: %w", format, err)
+	}
+
+	// Foto kamera/ponsel sering disimpan miring dengan tag orientasi EXIF,
+	// tag itu hilang saat encode ulang jadi rotasinya diterapkan di sini
+	if format == "jpeg" {
+		img = orient(img, jpegOrientation(data))
+	}
+	return img, nil
+}
+
+// Square memotong bagian tengah gambar menjadi persegi lalu mengubah
+// ukurannya menjadi size x size. Gambar kecil tidak diperbesar.
+func Square(src image.Image, size int) *image.NRGBA {
+	b := src.Bounds()
+	side := min(b.Dx(), b.Dy())
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(
+		b.Min.X+(b.Dx()-side)/2,
+		b.Min.Y+(b.Dy()-side)/2,
+	))
+
+	size = min(size, side)
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Src, nil)
+	return dst
+}
+
+// Encode meng-encode gambar sebagai JPEG, atau PNG jika ada transparansi.
+// Mengembalikan data, content type dan ekstensi file.
+func Encode(img *image.NRGBA) ([]byte, string, string, error) {
+	var buf bytes.Buffer
+	if img.Opaque() {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, "", "", err
+		}
+		return buf.Bytes(), "image/jpeg", "jpg", nil
+	}
+
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, "", "", err
+	}
+	return buf.Bytes(), "image/png", "png", nil
+}
@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys adalah nama attribute (case-insensitive) yang nilainya
+// tidak boleh tercetak di log, termasuk nama header HTTP
+var sensitiveKeys = map[string]bool{
+	"password":         true,
+	"new_password":     true,
+	"current_password": true,
+	"authorization":    true,
+	"cookie":           true,
+	"set-cookie":       true,
+	"access_token":     true,
+	"refresh_token":    true,
+	"token":            true,
+	"secret":           true,
+	"x-api-key":        true,
+}
+
+// ParseLevel mengubah string (debug, info, warn, error) menjadi slog.Level
+func ParseLevel(s string) slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// New membuat logger JSON yang otomatis menyensor attribute sensitif
+func New(w io.Writer, level slog.Level) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redact,
+	}))
+}
+
+func redact(groups []string, a slog.Attr) slog.Attr {
+	if sensitiveKeys[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, redacted)
+	}
+	return a
+}
+
+type loggerKey struct{}
+
+// WithContext menyimpan logger per-request di context
+func WithContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext mengambil logger per-request, slog.Default() jika tidak ada
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
@@ -0,0 +1,88 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Message adalah email plain-text yang akan dikirim
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+}
+
+// Mailer mengirim email. Implementasi harus aman dipakai bersamaan.
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// SMTPConfig berisi pengaturan server SMTP
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+type smtpMailer struct {
+	cfg SMTPConfig
+}
+
+func NewSMTPMailer(cfg SMTPConfig) Mailer {
+	return &smtpMailer{cfg}
+}
+
+func (m *smtpMailer) Send(ctx context.Context, msg *Message) error {
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "From: %s\r\n", m.cfg.From)
+	fmt.Fprintf(&body, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&body, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&body, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	body.WriteString("MIME-Version: 1.0\r\n")
+	body.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	body.WriteString(strings.ReplaceAll(msg.Text, "\n", "\r\n"))
+
+	// net/smtp tidak mendukung context, jalankan di goroutine agar
+	// pemanggil tetap bisa berhenti saat context dibatalkan
+	done := make(chan error, 1)
+	go func() {
+		addr := net.JoinHostPort(m.cfg.Host, m.cfg.Port)
+		done <- smtp.SendMail(addr, auth, m.cfg.From, []string{msg.To}, []byte(body.String()))
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("mailer: send to %s: %w", msg.To, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type logMailer struct {
+	logger *slog.Logger
+}
+
+// NewLogMailer membuat mailer untuk development yang hanya mencetak email ke log
+func NewLogMailer(logger *slog.Logger) Mailer {
+	return &logMailer{logger}
+}
+
+func (m *logMailer) Send(ctx context.Context, msg *Message) error {
+	m.logger.InfoContext(ctx, "email not sent (log mailer)", "to", msg.To, "subject", msg.Subject, "body", msg.Text)
+	return nil
+}
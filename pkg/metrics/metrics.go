@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry menampung semua metrik aplikasi. Dipakai registry sendiri
+// (bukan prometheus.DefaultRegisterer) agar isi /metrics terkontrol.
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by method, route template and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	APIVersionRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "api_version_requests_total",
+		Help: "HTTP requests by API version and whether that version is deprecated.",
+	}, []string{"version", "deprecated"})
+
+	IdempotencyRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "idempotency_requests_total",
+		Help: "Requests carrying an Idempotency-Key by result (new, replayed, reused, in_flight).",
+	}, []string{"result"})
+
+	AuthLogins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_logins_total",
+		Help: "Login attempts by result (success, failure).",
+	}, []string{"result"})
+
+	AuthRefreshes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_token_refreshes_total",
+		Help: "Token refresh attempts by result (success, failure).",
+	}, []string{"result"})
+
+	AuthLockouts = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "auth_lockouts_total",
+		Help: "Accounts locked after too many consecutive failed password logins.",
+	})
+
+	AuthTokenValidationFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_token_validation_failures_total",
+		Help: "Access tokens rejected by the authentication middleware, by reason.",
+	}, []string{"reason"})
+
+	AuditLockWait = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "audit_chain_lock_wait_seconds",
+		Help:    "Time spent waiting for the audit log hash chain lock before appending an entry.",
+		Buckets: prometheus.ExponentialBuckets(0.0005, 4, 8),
+	})
+)
+
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPDuration,
+		APIVersionRequests,
+		IdempotencyRequests,
+		AuthLogins,
+		AuthRefreshes,
+		AuthLockouts,
+		AuthTokenValidationFailures,
+		AuditLockWait,
+	)
+}
+
+// RegisterDB mengekspor statistik connection pool database
+// (open, in use, idle, wait count, dst) dengan label db_name
+func RegisterDB(name string, db *sql.DB) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler mengembalikan handler HTTP untuk endpoint /metrics
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
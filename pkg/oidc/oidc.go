@@ -0,0 +1,152 @@
+// Package oidc berisi kunci penanda tangan dan konfigurasi untuk peran
+// service ini sebagai OAuth 2.1 / OpenID Connect provider.
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Config berisi pengaturan OAuth / OIDC provider
+type Config struct {
+	// Issuer adalah URL publik service ini (claim iss), tanpa slash di akhir
+	Issuer string
+
+	// EndpointURL adalah URL dasar endpoint OAuth (authorize, token,
+	// userinfo), misalnya https://api.example.com/v1/oauth
+	EndpointURL string
+
+	// SigningKeyFile adalah file PEM kunci RSA untuk menandatangani token.
+	// Kosong berarti kunci dibuat acak setiap start (hanya untuk development:
+	// token lama tidak bisa diverifikasi setelah restart).
+	SigningKeyFile string
+
+	// LoginURL dan ConsentURL adalah halaman frontend; LoginURL menerima
+	// query return_to dan ConsentURL menerima query request
+	LoginURL   string
+	ConsentURL string
+
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+// SigningKey adalah kunci RSA untuk menandatangani token (RS256). ID adalah
+// thumbprint JWK (RFC 7638) dan dikirim sebagai header kid.
+type SigningKey struct {
+	ID  string
+	key *rsa.PrivateKey
+}
+
+// NewSigningKey membungkus kunci RSA yang sudah ada
+func NewSigningKey(key *rsa.PrivateKey) *SigningKey {
+	return &SigningKey{ID: thumbprint(&key.PublicKey), key: key}
+}
+
+// GenerateSigningKey membuat kunci RSA 2048 bit baru
+func GenerateSigningKey() (*SigningKey, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: generate signing key: %w", err)
+	}
+	return NewSigningKey(key), nil
+}
+
+// LoadSigningKey membaca kunci RSA dari file PEM (PKCS#1 atau PKCS#8)
+func LoadSigningKey(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: read signing key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("oidc: signing key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return NewSigningKey(key), nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: parse signing key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("oidc: signing key is not an RSA key")
+	}
+	return NewSigningKey(key), nil
+}
+
+// Sign menandatangani claims dengan header typ dan kid
+func (k *SigningKey) Sign(typ string, claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["typ"] = typ
+	token.Header["kid"] = k.ID
+	return token.SignedString(k.key)
+}
+
+// Parse memverifikasi token yang ditandatangani kunci ini dengan header typ
+// yang sama, lalu mengisi claims. Waktu kedaluwarsa diperiksa oleh claims.
+func (k *SigningKey) Parse(tokenString, typ string, claims jwt.Claims) error {
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method != jwt.SigningMethodRS256 {
+			return nil, errors.New("invalid signing method")
+		}
+		if t.Header["typ"] != typ {
+			return nil, errors.New("invalid token type")
+		}
+		return &k.key.PublicKey, nil
+	})
+	return err
+}
+
+// JWK adalah public key dalam format JSON Web Key
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKS adalah dokumen /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS mengembalikan public key untuk verifikasi token oleh client
+func (k *SigningKey) JWKS() JWKS {
+	n, e := publicComponents(&k.key.PublicKey)
+	return JWKS{Keys: []JWK{{Kty: "RSA", Use: "sig", Alg: "RS256", Kid: k.ID, N: n, E: e}}}
+}
+
+func publicComponents(pub *rsa.PublicKey) (n, e string) {
+	n = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+	e = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	return n, e
+}
+
+// thumbprint menghitung JWK thumbprint SHA-256 (RFC 7638)
+func thumbprint(pub *rsa.PublicKey) string {
+	n, e := publicComponents(pub)
+	sum := sha256.Sum256([]byte(`{"e":"` + e + `","kty":"RSA","n":"` + n + `"}`))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// HashHalf menghitung nilai claim at_hash / c_hash untuk RS256: separuh
+// kiri SHA-256, di-encode base64url
+func HashHalf(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(sum[:len(sum)/2])
+}
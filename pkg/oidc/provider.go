@@ -0,0 +1,450 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	// metadataTTL adalah lama dokumen discovery provider disimpan di memori
+	metadataTTL = time.Hour
+
+	// keyRefreshInterval membatasi pengambilan ulang JWKS saat token memakai
+	// kid yang belum dikenal (rotasi kunci di provider)
+	keyRefreshInterval = time.Minute
+
+	// maxResponseBytes membatasi ukuran response provider yang dibaca
+	maxResponseBytes = 1 << 20
+)
+
+// FederationConfig mengatur login lewat identity provider OIDC eksternal
+type FederationConfig struct {
+	// CallbackURL adalah URL dasar callback, misalnya
+	// https://api.example.com/v1/auth/sso; callback setiap provider ada di
+	// <CallbackURL>/<nama provider>/callback dan harus didaftarkan di
+	// provider
+	CallbackURL string
+
+	// SuccessURL adalah halaman frontend setelah login jika request tidak
+	// membawa return_to, dan ErrorURL halaman yang menerima query error
+	SuccessURL string
+	ErrorURL   string
+
+	// ReturnOrigins adalah origin yang boleh menjadi tujuan return_to
+	// setelah login; URL lain diganti SuccessURL agar tidak menjadi open
+	// redirect
+	ReturnOrigins []string
+
+	Providers []ProviderConfig
+}
+
+// ProviderConfig adalah satu identity provider OIDC
+type ProviderConfig struct {
+	// Name dipakai di URL (/auth/sso/<name>) dan di tabel identity
+	Name        string
+	DisplayName string
+
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+
+	// AllowedDomains membatasi domain email yang boleh login; kosong
+	// berarti semua domain
+	AllowedDomains []string
+
+	// Provision membuat user baru saat login pertama (just-in-time) jika
+	// email belum terdaftar
+	Provision bool
+}
+
+// AllowsEmail melaporkan apakah domain email diizinkan provider
+func (c ProviderConfig) AllowsEmail(email string) bool {
+	if len(c.AllowedDomains) == 0 {
+		return true
+	}
+	_, domain, ok := strings.Cut(email, "@")
+	return ok && slices.ContainsFunc(c.AllowedDomains, func(d string) bool {
+		return strings.EqualFold(d, domain)
+	})
+}
+
+// IDToken adalah claim ID token yang sudah diverifikasi
+type IDToken struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+}
+
+// Provider adalah client (relying party) untuk satu identity provider. Dokumen
+// discovery dan JWKS diambil saat pertama dibutuhkan lalu disimpan di memori.
+// mu hanya melindungi cache; request ke provider berjalan tanpa lock, dan
+// pemanggil lain menunggu channel discovering / keysFetching sampai
+// pengambilan yang sedang berjalan selesai.
+type Provider struct {
+	cfg    ProviderConfig
+	client *http.Client
+
+	mu           sync.Mutex
+	metadata     *providerMetadata
+	fetchedAt    time.Time
+	discovering  chan struct{}
+	keys         map[string]*rsa.PublicKey
+	keysFetched  time.Time
+	keysFetching chan struct{}
+}
+
+// NewProvider membuat client provider; client nil memakai http.Client
+// dengan timeout 10 detik
+func NewProvider(cfg ProviderConfig, client *http.Client) *Provider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Provider{cfg: cfg, client: client}
+}
+
+func (p *Provider) Config() ProviderConfig {
+	return p.cfg
+}
+
+// providerMetadata adalah bagian dokumen discovery yang dipakai client
+type providerMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// idTokenClaims adalah claim ID token dari provider. email_verified bisa
+// dikirim sebagai string oleh sebagian provider.
+type idTokenClaims struct {
+	Nonce         string       `json:"nonce"`
+	Email         string       `json:"email"`
+	EmailVerified flexibleBool `json:"email_verified"`
+	Name          string       `json:"name"`
+	Picture       string       `json:"picture"`
+	jwt.RegisteredClaims
+}
+
+type flexibleBool bool
+
+func (b *flexibleBool) UnmarshalJSON(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case bool:
+		*b = flexibleBool(v)
+	case string:
+		*b = flexibleBool(strings.EqualFold(v, "true"))
+	}
+	return nil
+}
+
+// AuthCodeURL membangun URL authorization request (authorization code +
+// PKCE S256) ke provider
+func (p *Provider) AuthCodeURL(ctx context.Context, redirectURI, state, nonce, codeChallenge string) (string, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(meta.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("oidc: invalid authorization endpoint: %w", err)
+	}
+	query := u.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.cfg.ClientID)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// Exchange menukar authorization code dengan token lalu memverifikasi ID
+// token: tanda tangan (JWKS provider), iss, aud, exp dan nonce
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, redirectURI, nonce string) (*IDToken, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {codeVerifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+
+	var tokens struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	status, err := p.doJSON(req, &tokens)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("oidc: token request failed with status %d: %s %s", status, tokens.Error, tokens.ErrorDescription)
+	}
+	if tokens.IDToken == "" {
+		return nil, errors.New("oidc: token response has no id_token")
+	}
+
+	return p.verify(ctx, meta, tokens.IDToken, nonce)
+}
+
+func (p *Provider) verify(ctx context.Context, meta *providerMetadata, rawIDToken, nonce string) (*IDToken, error) {
+	var claims idTokenClaims
+	parser := jwt.Parser{ValidMethods: []string{"RS256", "RS384", "RS512"}}
+	_, err := parser.ParseWithClaims(rawIDToken, &claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, meta, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("oidc: invalid id_token: %w", err)
+	}
+
+	if claims.Issuer != meta.Issuer {
+		return nil, errors.New("oidc: id_token issuer mismatch")
+	}
+	if !claims.VerifyAudience(p.cfg.ClientID, true) {
+		return nil, errors.New("oidc: id_token audience mismatch")
+	}
+	if claims.ExpiresAt == nil {
+		return nil, errors.New("oidc: id_token has no expiry")
+	}
+	if claims.Nonce != nonce {
+		return nil, errors.New("oidc: id_token nonce mismatch")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("oidc: id_token has no subject")
+	}
+
+	return &IDToken{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          claims.Name,
+		Picture:       claims.Picture,
+	}, nil
+}
+
+// discover mengambil dokumen discovery provider (disimpan selama
+// metadataTTL)
+func (p *Provider) discover(ctx context.Context) (*providerMetadata, error) {
+	for {
+		p.mu.Lock()
+		if p.metadata != nil && time.Since(p.fetchedAt) < metadataTTL {
+			meta := p.metadata
+			p.mu.Unlock()
+			return meta, nil
+		}
+		if wait := p.discovering; wait != nil {
+			p.mu.Unlock()
+			if err := waitFetch(ctx, wait); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		done := make(chan struct{})
+		p.discovering = done
+		p.mu.Unlock()
+
+		meta, err := p.fetchMetadata(ctx)
+
+		p.mu.Lock()
+		if err == nil {
+			p.metadata = meta
+			p.fetchedAt = time.Now()
+		}
+		p.discovering = nil
+		p.mu.Unlock()
+		close(done)
+		return meta, err
+	}
+}
+
+func (p *Provider) fetchMetadata(ctx context.Context) (*providerMetadata, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(p.cfg.Issuer, "/")+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	var meta providerMetadata
+	status, err := p.doJSON(req, &meta)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("oidc: discovery failed with status %d", status)
+	}
+	// Issuer dokumen harus sama dengan yang dikonfigurasi (OIDC Discovery
+	// bagian 4.3)
+	if strings.TrimSuffix(meta.Issuer, "/") != strings.TrimSuffix(p.cfg.Issuer, "/") {
+		return nil, fmt.Errorf("oidc: discovery issuer %q does not match %q", meta.Issuer, p.cfg.Issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, errors.New("oidc: discovery document is incomplete")
+	}
+	return &meta, nil
+}
+
+// key mencari public key berdasarkan kid. JWKS diambil ulang (paling sering
+// sekali per keyRefreshInterval) jika kid belum dikenal.
+func (p *Provider) key(ctx context.Context, meta *providerMetadata, kid string) (*rsa.PublicKey, error) {
+	for {
+		p.mu.Lock()
+		if key, ok := p.lookupKey(kid); ok {
+			p.mu.Unlock()
+			return key, nil
+		}
+		if wait := p.keysFetching; wait != nil {
+			p.mu.Unlock()
+			if err := waitFetch(ctx, wait); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if time.Since(p.keysFetched) < keyRefreshInterval {
+			p.mu.Unlock()
+			return nil, fmt.Errorf("oidc: unknown signing key %q", kid)
+		}
+		done := make(chan struct{})
+		p.keysFetching = done
+		p.mu.Unlock()
+
+		keys, err := p.fetchKeys(ctx, meta)
+
+		p.mu.Lock()
+		if err == nil {
+			p.keys = keys
+			p.keysFetched = time.Now()
+		}
+		p.keysFetching = nil
+		key, ok := p.lookupKey(kid)
+		p.mu.Unlock()
+		close(done)
+
+		switch {
+		case err != nil:
+			return nil, err
+		case !ok:
+			return nil, fmt.Errorf("oidc: unknown signing key %q", kid)
+		}
+		return key, nil
+	}
+}
+
+func (p *Provider) fetchKeys(ctx context.Context, meta *providerMetadata) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, meta.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var set JWKS
+	status, err := p.doJSON(req, &set)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("oidc: jwks request failed with status %d", status)
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		key, err := rsaPublicKey(jwk)
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+// waitFetch menunggu pengambilan yang sedang dijalankan request lain
+func waitFetch(ctx context.Context, done <-chan struct{}) error {
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// lookupKey mencari key berdasarkan kid; token tanpa kid hanya diterima jika
+// provider punya tepat satu key. Harus dipanggil dengan mu terkunci.
+func (p *Provider) lookupKey(kid string) (*rsa.PublicKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+// doJSON mengirim request dan men-decode body JSON response ke v
+func (p *Provider) doJSON(req *http.Request, v any) (int, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("oidc: request %s: %w", req.URL.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return 0, fmt.Errorf("oidc: read response: %w", err)
+	}
+	if err := json.Unmarshal(body, v); err != nil && resp.StatusCode == http.StatusOK {
+		return 0, fmt.Errorf("oidc: decode response: %w", err)
+	}
+	return resp.StatusCode, nil
+}
+
+func rsaPublicKey(jwk JWK) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, err
+	}
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("oidc: invalid RSA exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
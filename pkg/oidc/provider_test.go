@@ -0,0 +1,281 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	testClientID = "client-1"
+	testNonce    = "nonce-1"
+)
+
+// testIssuer adalah identity provider OIDC di dalam proses: discovery, JWKS
+// dan token endpoint yang mengembalikan idToken
+type testIssuer struct {
+	*httptest.Server
+	key *SigningKey
+
+	mu      sync.Mutex
+	idToken string
+
+	discoveries atomic.Int32
+	jwksFetches atomic.Int32
+	release     chan struct{} // jika tidak nil, JWKS menunggu channel ini
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+	key, err := GenerateSigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	iss := &testIssuer{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		iss.discoveries.Add(1)
+		json.NewEncoder(w).Encode(providerMetadata{
+			Issuer:                iss.URL,
+			AuthorizationEndpoint: iss.URL + "/authorize",
+			TokenEndpoint:         iss.URL + "/token",
+			JWKSURI:               iss.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		iss.jwksFetches.Add(1)
+		if iss.release != nil {
+			<-iss.release
+		}
+		json.NewEncoder(w).Encode(iss.key.JWKS())
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		iss.mu.Lock()
+		defer iss.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]string{"id_token": iss.idToken, "token_type": "Bearer"})
+	})
+	iss.Server = httptest.NewServer(mux)
+	t.Cleanup(iss.Close)
+	return iss
+}
+
+func (iss *testIssuer) provider() *Provider {
+	return NewProvider(ProviderConfig{Name: "test", Issuer: iss.URL, ClientID: testClientID, ClientSecret: "secret"}, iss.Client())
+}
+
+// claims mengembalikan claim ID token yang valid untuk provider
+func (iss *testIssuer) claims() *idTokenClaims {
+	now := time.Now()
+	return &idTokenClaims{
+		Nonce:         testNonce,
+		Email:         "alice@example.com",
+		EmailVerified: true,
+		Name:          "Alice",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    iss.URL,
+			Subject:   "user-1",
+			Audience:  jwt.ClaimStrings{testClientID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+		},
+	}
+}
+
+func (iss *testIssuer) sign(t *testing.T, claims jwt.Claims) string {
+	t.Helper()
+	token, err := iss.key.Sign("JWT", claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func (iss *testIssuer) respondWith(token string) {
+	iss.mu.Lock()
+	defer iss.mu.Unlock()
+	iss.idToken = token
+}
+
+func exchange(p *Provider) (*IDToken, error) {
+	return p.Exchange(context.Background(), "code", "verifier", "https://app.test/callback", testNonce)
+}
+
+func TestExchangeVerifiesIDToken(t *testing.T) {
+	iss := newTestIssuer(t)
+	iss.respondWith(iss.sign(t, iss.claims()))
+
+	token, err := exchange(iss.provider())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Subject != "user-1" || token.Email != "alice@example.com" || !token.EmailVerified || token.Name != "Alice" {
+		t.Errorf("unexpected token: %+v", token)
+	}
+}
+
+func TestExchangeRejectsInvalidIDToken(t *testing.T) {
+	iss := newTestIssuer(t)
+
+	other, err := GenerateSigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token func() string
+		want  string
+	}{
+		{"issuer", func() string {
+			c := iss.claims()
+			c.Issuer = "https://evil.test"
+			return iss.sign(t, c)
+		}, "issuer mismatch"},
+		{"audience", func() string {
+			c := iss.claims()
+			c.Audience = jwt.ClaimStrings{"another-client"}
+			return iss.sign(t, c)
+		}, "audience mismatch"},
+		{"nonce", func() string {
+			c := iss.claims()
+			c.Nonce = "replayed"
+			return iss.sign(t, c)
+		}, "nonce mismatch"},
+		{"expired", func() string {
+			c := iss.claims()
+			c.IssuedAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+			c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+			return iss.sign(t, c)
+		}, "expired"},
+		{"no expiry", func() string {
+			c := iss.claims()
+			c.ExpiresAt = nil
+			return iss.sign(t, c)
+		}, "no expiry"},
+		{"hmac with public key", func() string {
+			// Serangan alg confusion: HS256 dengan public key sebagai secret
+			token := jwt.NewWithClaims(jwt.SigningMethodHS256, iss.claims())
+			token.Header["kid"] = iss.key.ID
+			signed, err := token.SignedString(iss.key.key.PublicKey.N.Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			return signed
+		}, "signing method"},
+		{"alg none", func() string {
+			token := jwt.NewWithClaims(jwt.SigningMethodNone, iss.claims())
+			signed, err := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return signed
+		}, "signing method"},
+		{"unknown key", func() string {
+			token, err := other.Sign("JWT", iss.claims())
+			if err != nil {
+				t.Fatal(err)
+			}
+			return token
+		}, "unknown signing key"},
+		{"tampered signature", func() string {
+			token := iss.sign(t, iss.claims())
+			c := iss.claims()
+			c.Subject = "admin"
+			forged := iss.sign(t, c)
+			// Payload token lain dengan tanda tangan token asli
+			parts, forgedParts := strings.Split(token, "."), strings.Split(forged, ".")
+			return parts[0] + "." + forgedParts[1] + "." + parts[2]
+		}, "verification error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			iss.respondWith(tt.token())
+			_, err := exchange(iss.provider())
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q does not contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestProviderCachesDiscoveryAndKeys(t *testing.T) {
+	iss := newTestIssuer(t)
+	iss.respondWith(iss.sign(t, iss.claims()))
+	p := iss.provider()
+
+	for i := 0; i < 3; i++ {
+		if _, err := exchange(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := iss.discoveries.Load(); n != 1 {
+		t.Errorf("discovery fetched %d times, want 1", n)
+	}
+	if n := iss.jwksFetches.Load(); n != 1 {
+		t.Errorf("jwks fetched %d times, want 1", n)
+	}
+}
+
+func TestProviderDoesNotHoldLockDuringFetch(t *testing.T) {
+	iss := newTestIssuer(t)
+	iss.release = make(chan struct{})
+	iss.respondWith(iss.sign(t, iss.claims()))
+	p := iss.provider()
+
+	// Exchange pertama tertahan di JWKS; request lain tetap bisa memakai
+	// dokumen discovery dari cache
+	errs := make(chan error, 1)
+	go func() {
+		_, err := exchange(p)
+		errs <- err
+	}()
+	for iss.jwksFetches.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	authURL := make(chan error, 1)
+	go func() {
+		_, err := p.AuthCodeURL(context.Background(), "https://app.test/callback", "state", testNonce, "challenge")
+		authURL <- err
+	}()
+	select {
+	case err := <-authURL:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		close(iss.release)
+		t.Fatal("AuthCodeURL blocked by JWKS fetch")
+	}
+
+	// Request yang butuh key menunggu fetch yang sama, bukan fetch baru
+	waiter := make(chan error, 1)
+	go func() {
+		_, err := exchange(p)
+		waiter <- err
+	}()
+
+	close(iss.release)
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if err := <-waiter; err != nil {
+		t.Fatal(err)
+	}
+	if n := iss.jwksFetches.Load(); n != 1 {
+		t.Errorf("jwks fetched %d times, want 1", n)
+	}
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// Storage menyimpan object (misalnya gambar avatar) berdasarkan key.
+// Key memakai "/" sebagai pemisah, misalnya "avatars/<user-id>/256.jpg".
+type Storage interface {
+	// Put menulis object, menimpa object lama dengan key yang sama
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+
+	// Delete menghapus object, tidak error jika object tidak ada
+	Delete(ctx context.Context, key string) error
+
+	// URL mengembalikan URL publik object
+	URL(key string) string
+}
+
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+// Config memilih dan mengatur implementasi Storage
+type Config struct {
+	Driver string // local atau s3
+
+	// LocalDir adalah direktori file untuk driver local
+	LocalDir string
+
+	// LocalURL adalah prefix URL tempat LocalDir dilayani, misalnya /uploads
+	LocalURL string
+
+	S3 S3Config
+}
+
+// New membuat Storage sesuai cfg.Driver. Untuk S3, bucket dibuat jika belum ada.
+func New(ctx context.Context, cfg Config) (Storage, error) {
+	switch cfg.Driver {
+	case DriverLocal:
+		return NewLocalStorage(cfg.LocalDir, cfg.LocalURL)
+	case DriverS3:
+		s, err := NewS3Storage(cfg.S3)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.EnsureBucket(ctx, cfg.S3.Region); err != nil {
+			return nil, err
+		}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("storage: unknown driver %q", cfg.Driver)
+	}
+}